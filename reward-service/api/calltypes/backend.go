@@ -1,6 +1,9 @@
 package calltypes
 
-import "time"
+import (
+	"net/http"
+	"time"
+)
 
 // User provides structure to hold users
 // @Description info about user.
@@ -13,8 +16,372 @@ type User struct {
 	Active    int       `json:"active"`
 	Score     int       `json:"score"`
 	Referrer  string    `json:"referrer,omitempty"`
+	Role      string    `json:"role"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
+	// ExpiringPoints, Tier and Streak are set only for the status of the user.
+	ExpiringPoints *int        `json:"expiringPoints,omitempty"`
+	Tier           *TierStatus `json:"tier,omitempty"`
+	Streak         *Streak     `json:"streak,omitempty"`
+}
+
+// PointTransaction is one entry of the points ledger. ActorID is zero for entries written by the system
+// @Description points ledger entry.
+type PointTransaction struct {
+	ID          int       `json:"id"`
+	UserID      int       `json:"userId"`
+	Delta       int       `json:"delta"`
+	Reason      string    `json:"reason"`
+	ReferenceID string    `json:"referenceId,omitempty"`
+	ActorID     int       `json:"actorId,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// TransactionsPage is one page of user's ledger entries
+// @Description page of points ledger entries.
+type TransactionsPage struct {
+	Items      []*PointTransaction `json:"items"`
+	NextCursor string              `json:"nextCursor,omitempty"`
+}
+
+// LeaderboardEntry is one place of the leaderboard, it shows nothing but the public name of the user.
+// Users with equal scores share the rank
+// @Description leaderboard entry.
+type LeaderboardEntry struct {
+	Rank        int    `json:"rank"`
+	UserID      int    `json:"userId"`
+	DisplayName string `json:"displayName"`
+	Score       int    `json:"score"`
+	// Prize is set only in the final standings of a season.
+	Prize int `json:"prize,omitempty"`
+}
+
+// LeaderboardPage is one page of the leaderboard for the period
+// @Description page of the leaderboard.
+type LeaderboardPage struct {
+	Period     string              `json:"period"`
+	Items      []*LeaderboardEntry `json:"items"`
+	NextCursor string              `json:"nextCursor,omitempty"`
+}
+
+// Season is a competition with its own leaderboard of the points earned from StartsAt until EndsAt.
+// Prizes are the points paid to the users of the ranks 1, 2, 3 and so on when the season is closed,
+// zero means no prize for the rank. Once closed, the final standings are kept in the archive
+// @Description leaderboard season.
+type Season struct {
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	StartsAt  time.Time  `json:"startsAt"`
+	EndsAt    time.Time  `json:"endsAt"`
+	Prizes    []int      `json:"prizes,omitempty"`
+	Status    string     `json:"status"`
+	ClosedAt  *time.Time `json:"closedAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+// SeasonLeaderboardPage is one page of the season leaderboard, live until the season is closed
+// and the final standings after that
+// @Description page of the season leaderboard.
+type SeasonLeaderboardPage struct {
+	Season     *Season             `json:"season"`
+	Items      []*LeaderboardEntry `json:"items"`
+	NextCursor string              `json:"nextCursor,omitempty"`
+}
+
+// UserRank is the user's place on the leaderboard. Position breaks the ties of Rank by user id, Percentile
+// is the share of ranked users with a lower score
+// @Description user's place on the leaderboard.
+type UserRank struct {
+	Period     string              `json:"period"`
+	Rank       int                 `json:"rank"`
+	Position   int                 `json:"position"`
+	Total      int                 `json:"total"`
+	Percentile float64             `json:"percentile"`
+	Score      int                 `json:"score"`
+	Above      []*LeaderboardEntry `json:"above"`
+	Below      []*LeaderboardEntry `json:"below"`
+}
+
+// LeaderboardCursor is the position after which the next leaderboard page starts, entries are ordered
+// by score descending and user id ascending.
+type LeaderboardCursor struct {
+	Score  int
+	UserID int
+}
+
+// ReferralInfo is user's referral code and the link to share it
+// @Description user's referral code.
+type ReferralInfo struct {
+	Code              string `example:"7KQ2MZXA"                      json:"code"`
+	ShareLink         string `example:"/registrate?referrer=7KQ2MZXA" json:"shareLink"`
+	ShareLinkTemplate string `example:"/registrate?referrer={code}"   json:"shareLinkTemplate"`
+}
+
+// ReferralRewards are the points granted when a referral code is redeemed. Tiers[0] goes to the owner
+// of the code, Tiers[1] to the one who invited the owner and so on, Referee goes to the one who redeemed it.
+type ReferralRewards struct {
+	Tiers   []int
+	Referee int
+}
+
+// ReferralStats describes users invited by a user
+// @Description user's referral statistics.
+type ReferralStats struct {
+	Invited      int             `json:"invited"`
+	PointsEarned int             `json:"pointsEarned"`
+	Levels       []ReferralLevel `json:"levels"`
+	Invitees     []*Invitee      `json:"invitees"`
+}
+
+// ReferralLevel counts invitees on one level of the referral tree, level 1 are the direct invitees
+// @Description referral tree level.
+type ReferralLevel struct {
+	Level        int `json:"level"`
+	Count        int `json:"count"`
+	PointsEarned int `json:"pointsEarned"`
+}
+
+// Invitee is a user invited directly, PointsEarned includes the rewards for the ones they invited further
+// @Description directly invited user.
+type Invitee struct {
+	UserID       int       `json:"userId"`
+	FirstName    string    `json:"firstName,omitempty"`
+	LastName     string    `json:"lastName,omitempty"`
+	PointsEarned int       `json:"pointsEarned"`
+	RedeemedAt   time.Time `json:"redeemedAt"`
+}
+
+// Task is one entry of the task catalog, StartsAt and EndsAt limit when it can be completed.
+// Policy tells how often a user can complete it: once ever, once per day, MaxCompletions times
+// per PeriodSeconds ("limited") or any number of times with CooldownSeconds between completions ("unlimited")
+// @Description task from the catalog.
+type Task struct {
+	ID              int        `json:"id"`
+	Slug            string     `json:"slug"`
+	Title           string     `json:"title"`
+	Description     string     `json:"description,omitempty"`
+	Reward          int        `json:"reward"`
+	Policy          string     `json:"policy"`
+	MaxCompletions  int        `json:"maxCompletions,omitempty"`
+	PeriodSeconds   int        `json:"periodSeconds,omitempty"`
+	CooldownSeconds int        `json:"cooldownSeconds,omitempty"`
+	Verifier        string     `json:"verifier,omitempty"`
+	Active          bool       `json:"active"`
+	StartsAt        *time.Time `json:"startsAt,omitempty"`
+	EndsAt          *time.Time `json:"endsAt,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+}
+
+// SecretCode is a quest completed by entering a secret code, only the hash of the code is stored.
+// MaxRedemptions limits redemptions by all users (zero means no limit), PerUserLimit by one user
+// @Description secret-code quest.
+type SecretCode struct {
+	ID             int        `json:"id"`
+	Title          string     `json:"title"`
+	CodeHash       string     `json:"-"`
+	Reward         int        `json:"reward"`
+	MaxRedemptions int        `json:"maxRedemptions,omitempty"`
+	PerUserLimit   int        `json:"perUserLimit"`
+	Redemptions    int        `json:"redemptions"`
+	Active         bool       `json:"active"`
+	ExpiresAt      *time.Time `json:"expiresAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+// Campaign is a marketing campaign that runs between StartsAt and EndsAt. A "multiplier" campaign multiplies
+// rewards for completed tasks by MultiplierPercent/100, a "promo" campaign awards Reward once per user for
+// entering PromoCode. NewUserDays limits the audience to users registered within that many days and MinScore
+// to users with at least that score, zero disables the filter
+// @Description marketing campaign.
+type Campaign struct {
+	ID                int        `json:"id"`
+	Name              string     `json:"name"`
+	Kind              string     `json:"kind"`
+	MultiplierPercent int        `json:"multiplierPercent,omitempty"`
+	PromoCode         string     `json:"promoCode,omitempty"`
+	Reward            int        `json:"reward,omitempty"`
+	MaxRedemptions    int        `json:"maxRedemptions,omitempty"`
+	Redemptions       int        `json:"redemptions"`
+	NewUserDays       int        `json:"newUserDays,omitempty"`
+	MinScore          int        `json:"minScore,omitempty"`
+	Active            bool       `json:"active"`
+	StartsAt          *time.Time `json:"startsAt,omitempty"`
+	EndsAt            *time.Time `json:"endsAt,omitempty"`
+	CreatedAt         time.Time  `json:"createdAt"`
+	UpdatedAt         time.Time  `json:"updatedAt"`
+}
+
+// StoreItem is an item of the rewards store that users buy with points. Nil Stock means the stock is unlimited,
+// PerUserLimit limits redemptions by one user, zero means no limit. Digital items are handed out as voucher
+// codes right away, their stock is the number of unclaimed vouchers in VouchersLeft
+// @Description item of the rewards store.
+type StoreItem struct {
+	ID           int       `json:"id"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description,omitempty"`
+	Price        int       `json:"price"`
+	Stock        *int      `json:"stock,omitempty"`
+	PerUserLimit int       `json:"perUserLimit,omitempty"`
+	Digital      bool      `json:"digital"`
+	VouchersLeft *int      `json:"vouchersLeft,omitempty"`
+	Active       bool      `json:"active"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// Voucher is a code of a digital store item, it is claimed by a redemption. Codes are stored encrypted,
+// Fingerprint finds duplicates among them
+// @Description voucher code claimed by the user.
+type Voucher struct {
+	ID            int    `json:"id"`
+	ItemID        int    `json:"itemId"`
+	ItemTitle     string `json:"itemTitle"`
+	RedemptionID  int    `json:"redemptionId"`
+	Code          string `json:"code"`
+	EncryptedCode string `json:"-"`
+	Fingerprint   string `json:"-"`
+	// FingerprintVersion is the version of the key the fingerprint was made with.
+	FingerprintVersion int        `json:"-"`
+	ClaimedAt          *time.Time `json:"claimedAt,omitempty"`
+}
+
+// VoucherUpload is the result of a voucher upload, codes the item already has are counted as Duplicates
+// @Description result of a voucher upload.
+type VoucherUpload struct {
+	Added      int `json:"added"`
+	Duplicates int `json:"duplicates"`
+}
+
+// Redemption is one purchase in the rewards store. It is "pending" until an admin either fulfills it
+// or cancels it, a cancelled redemption is refunded. Redemptions of digital items are fulfilled at once,
+// their voucher codes are returned only by the user's vouchers
+// @Description purchase in the rewards store.
+type Redemption struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"userId"`
+	ItemID    int       `json:"itemId"`
+	ItemTitle string    `json:"itemTitle"`
+	Price     int       `json:"price"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Tier is a level users reach by lifetime earned points, spending or expiring points doesn't lower it.
+// MultiplierPercent multiplies rewards for completed tasks, 100 means no bonus
+// @Description user tier.
+type Tier struct {
+	ID                int       `json:"id"`
+	Name              string    `json:"name"`
+	MinPoints         int       `json:"minPoints"`
+	MultiplierPercent int       `json:"multiplierPercent"`
+	CreatedAt         time.Time `json:"createdAt"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
+
+// TierStatus is the user's progress through the tiers. Current is nil below the lowest tier, Next is nil
+// at the highest one
+// @Description user's tier progress.
+type TierStatus struct {
+	LifetimePoints int   `json:"lifetimePoints"`
+	Current        *Tier `json:"current,omitempty"`
+	Next           *Tier `json:"next,omitempty"`
+	PointsToNext   int   `json:"pointsToNext"`
+}
+
+// Badge is an achievement a user gets once, when their Metric meets Threshold. The "tasks_completed",
+// "referrals" and "lifetime_points" counters must reach Threshold, the "monthly_rank" by points earned
+// this month must be within it, rank badges are awarded periodically. Reward is paid with the badge, zero means none
+// @Description badge definition.
+type Badge struct {
+	ID          int       `json:"id"`
+	Slug        string    `json:"slug"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Metric      string    `json:"metric"`
+	Threshold   int       `json:"threshold"`
+	Reward      int       `json:"reward,omitempty"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// UserBadge is a badge awarded to a user
+// @Description awarded badge.
+type UserBadge struct {
+	Slug        string    `json:"slug"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Reward      int       `json:"reward,omitempty"`
+	AwardedAt   time.Time `json:"awardedAt"`
+}
+
+// Streak is the user's daily check-in streak. Dates are in the user's timezone, Current is zero once
+// the streak is broken
+// @Description daily check-in streak.
+type Streak struct {
+	Current     int    `json:"current"`
+	Longest     int    `json:"longest"`
+	LastCheckIn string `json:"lastCheckIn,omitempty"`
+	Freezes     int    `json:"freezes"`
+	Timezone    string `json:"timezone"`
+}
+
+// CheckIn is the result of a daily check-in
+// @Description daily check-in.
+type CheckIn struct {
+	Date        string `json:"date"`
+	Streak      int    `json:"streak"`
+	Reward      int    `json:"reward"`
+	FreezesUsed int    `json:"freezesUsed,omitempty"`
+}
+
+// IdempotentResponse is the stored response of a request made with an Idempotency-Key. Header keeps
+// the response headers, cookies included, so that the replay matches the original response.
+type IdempotentResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// TaskCompletion is one attempt of a user to complete a task. Only completions with the "verified" status
+// are rewarded, "pending" ones couldn't be checked with the provider yet and "failed" ones were refused by it.
+type TaskCompletion struct {
+	ID          int       `json:"id"`
+	UserID      int       `json:"userId"`
+	TaskID      int       `json:"taskId"`
+	Status      string    `json:"status"`
+	Account     string    `json:"account,omitempty"`
+	Detail      string    `json:"detail,omitempty"`
+	CompletedAt time.Time `json:"completedAt"`
+}
+
+// Verification is the result of checking a task with its provider.
+type Verification struct {
+	Status string
+	Detail string
+}
+
+// Session describes one signed-in device of a user
+// @Description user's session.
+type Session struct {
+	ID         int       `json:"id"`
+	UserID     int       `json:"-"`
+	UserRole   string    `json:"-"`
+	UserAgent  string    `json:"userAgent"`
+	IP         string    `json:"ip"`
+	Current    bool      `json:"current"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+// RefreshToken is the stored form of a refresh token: the lookup selector and the keyed hash of its verifier.
+type RefreshToken struct {
+	Selector string
+	Hash     string
 }
 
 // LoginRequest represents user login request
@@ -31,15 +398,140 @@ type RegisterRequest struct {
 	FirstName string `example:"John"                json:"firstName"`
 	LastName  string `example:"Doe"                 json:"lastName"`
 	Password  string `example:"securePassword123"   json:"password"`
-	Active    int    `example:"1"                   json:"active,omitempty"`
-	Score     int    `example:"0"                   json:"score,omitempty"`
-	Referrer  string `example:"ref123"              json:"referrer,omitempty"`
+	Referrer  string `example:"SUMMER-2025"         json:"referrer,omitempty"`
+}
+
+// RedeemCodeRequest represents secret code redemption request
+// @name RedeemCodeRequest.
+type RedeemCodeRequest struct {
+	Code string `example:"ABCD-EFGH" json:"code"`
+}
+
+// SecretCodeRequest represents secret-code quest create request, the quest is active when Active is omitted,
+// can be redeemed once per user when PerUserLimit is omitted and any number of times in total
+// when MaxRedemptions is omitted
+// @name SecretCodeRequest.
+type SecretCodeRequest struct {
+	Code           string     `example:"ABCD-EFGH"            json:"code"`
+	Title          string     `example:"Launch quest"         json:"title"`
+	Reward         int        `example:"10000"                json:"reward"`
+	MaxRedemptions int        `example:"100"                  json:"maxRedemptions"`
+	PerUserLimit   int        `example:"1"                    json:"perUserLimit"`
+	Active         *bool      `example:"true"                 json:"active"`
+	ExpiresAt      *time.Time `example:"2025-09-01T00:00:00Z" json:"expiresAt"`
+}
+
+// CampaignRequest represents campaign create or update request, the campaign is active when Active is omitted
+// @name CampaignRequest.
+type CampaignRequest struct {
+	Name              string     `example:"Double points weekend" json:"name"`
+	Kind              string     `example:"multiplier"            json:"kind"`
+	MultiplierPercent int        `example:"200"                   json:"multiplierPercent,omitempty"`
+	PromoCode         string     `example:"SPRING25"              json:"promoCode,omitempty"`
+	Reward            int        `example:"0"                     json:"reward,omitempty"`
+	MaxRedemptions    int        `example:"0"                     json:"maxRedemptions,omitempty"`
+	NewUserDays       int        `example:"0"                     json:"newUserDays,omitempty"`
+	MinScore          int        `example:"0"                     json:"minScore,omitempty"`
+	Active            *bool      `example:"true"                  json:"active,omitempty"`
+	StartsAt          *time.Time `json:"startsAt,omitempty"`
+	EndsAt            *time.Time `json:"endsAt,omitempty"`
+}
+
+// PromoCodeRequest represents promo code redemption request
+// @name PromoCodeRequest.
+type PromoCodeRequest struct {
+	Code string `example:"SPRING25" json:"code"`
+}
+
+// StoreItemRequest represents store item create or update request, the item is active when Active is omitted
+// and its stock is unlimited when Stock is omitted. Digital items are sold while they have vouchers
+// @name StoreItemRequest.
+type StoreItemRequest struct {
+	Title        string `example:"Branded T-shirt"      json:"title"`
+	Description  string `example:"Cotton, sizes S to XL" json:"description"`
+	Price        int    `example:"5000"                 json:"price"`
+	Stock        *int   `example:"100"                  json:"stock,omitempty"`
+	PerUserLimit int    `example:"1"                    json:"perUserLimit,omitempty"`
+	Digital      bool   `example:"false"                json:"digital,omitempty"`
+	Active       *bool  `example:"true"                 json:"active,omitempty"`
+}
+
+// TierRequest represents tier create or update request, MultiplierPercent defaults to 100
+// @name TierRequest.
+type TierRequest struct {
+	Name              string `example:"Gold" json:"name"`
+	MinPoints         int    `example:"5000" json:"minPoints"`
+	MultiplierPercent int    `example:"125"  json:"multiplierPercent,omitempty"`
+}
+
+// BadgeRequest represents badge create or update request, the badge is active when Active is omitted
+// @name BadgeRequest.
+type BadgeRequest struct {
+	Slug        string `example:"ten-friends"        json:"slug"`
+	Name        string `example:"Invited 10 friends" json:"name"`
+	Description string `example:"Ten friends joined" json:"description"`
+	Metric      string `example:"referrals"          json:"metric"`
+	Threshold   int    `example:"10"                 json:"threshold"`
+	Reward      int    `example:"500"                json:"reward,omitempty"`
+	Active      *bool  `example:"true"               json:"active,omitempty"`
+}
+
+// TimezoneRequest represents user's timezone change request, the timezone is an IANA time zone name
+// @name TimezoneRequest.
+type TimezoneRequest struct {
+	Timezone string `example:"Europe/Moscow" json:"timezone"`
+}
+
+// SeasonRequest represents season create request, Prizes are the points for the ranks 1, 2, 3 and so on
+// @name SeasonRequest.
+type SeasonRequest struct {
+	Name     string    `example:"September 2025"       json:"name"`
+	StartsAt time.Time `example:"2025-09-01T00:00:00Z" json:"startsAt"`
+	EndsAt   time.Time `example:"2025-10-01T00:00:00Z" json:"endsAt"`
+	Prizes   []int     `example:"1000,500,250"         json:"prizes,omitempty"`
+}
+
+// RedemptionRequest represents store item redemption request
+// @name RedemptionRequest.
+type RedemptionRequest struct {
+	ItemID int `example:"1" json:"itemId"`
+}
+
+// RedemptionStatusRequest represents redemption status change request
+// @name RedemptionStatusRequest.
+type RedemptionStatusRequest struct {
+	Status string `example:"fulfilled" json:"status"`
+}
+
+// TaskRequest represents task create or update request, the task is active when Active is omitted,
+// can be completed once when Policy is omitted and isn't checked with any provider when Verifier is omitted
+// @name TaskRequest.
+type TaskRequest struct {
+	Slug            string     `example:"telegram-sign"             json:"slug"`
+	Title           string     `example:"Subscribe to the channel"  json:"title"`
+	Description     string     `example:"Join our Telegram channel" json:"description"`
+	Reward          int        `example:"50"                        json:"reward"`
+	Policy          string     `example:"once"                      json:"policy"`
+	MaxCompletions  int        `example:"0"                         json:"maxCompletions,omitempty"`
+	PeriodSeconds   int        `example:"0"                         json:"periodSeconds,omitempty"`
+	CooldownSeconds int        `example:"0"                         json:"cooldownSeconds,omitempty"`
+	Verifier        string     `example:"telegram"                  json:"verifier,omitempty"`
+	Active          *bool      `example:"true"                      json:"active,omitempty"`
+	StartsAt        *time.Time `json:"startsAt,omitempty"`
+	EndsAt          *time.Time `json:"endsAt,omitempty"`
+}
+
+// TaskCompletionRequest represents task completion request, Account is the user's account
+// with the provider of the task: Telegram user ID or X user ID
+// @name TaskCompletionRequest.
+type TaskCompletionRequest struct {
+	Account string `example:"123456789" json:"account"`
 }
 
-// SecretTaskRequest represents secret task request
-// @name SecretTaskRequest.
-type SecretTaskRequest struct {
-	SecretWaterPassword string `example:"KUARHODRON" json:"waterPassword"`
+// RoleRequest represents user role change request
+// @name RoleRequest.
+type RoleRequest struct {
+	Role string `example:"moderator" json:"role"`
 }
 
 // ReferrerRequest represents referrer code request
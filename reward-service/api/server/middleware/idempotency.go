@@ -0,0 +1,179 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"reward-service/api/calltypes"
+	"reward-service/api/server/httputils"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"strconv"
+)
+
+// IdempotencyStore keeps the responses of requests made with an Idempotency-Key.
+type IdempotencyStore interface {
+	// BeginIdempotentRequest reserves the key of the user. It returns the stored response when the key was
+	// already used for the same request, ErrIdempotencyKeyReused when it was used for a different one and
+	// ErrIdempotencyKeyInProgress when the first request hasn't finished yet.
+	BeginIdempotentRequest(userID int, key, fingerprint string) (*calltypes.IdempotentResponse, error)
+	CompleteIdempotentRequest(userID int, key string, response calltypes.IdempotentResponse) error
+	ReleaseIdempotentRequest(userID int, key string) error
+}
+
+// Idempotency replays the stored response when a mutating request is retried with the same Idempotency-Key.
+// Keys are scoped to the caller, so it must be mounted after Auth, and after the access checks of the route,
+// so that their rejections are not stored under the key. Requests without the header pass through,
+// responses with 5xx status are not stored so that the request can be retried. The status, the headers but
+// the ones describing the connection and the body are replayed.
+func Idempotency(store IdempotencyStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(consts.IdempotencyKeyHeader)
+			if key == "" || !isMutating(r.Method) {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			if len(key) > consts.IdempotencyKeyMaxLength {
+				httputils.ErrorJSON(w, errormsg.ErrInvalidIdempotencyKey, http.StatusBadRequest)
+
+				return
+			}
+
+			userID, ok := UserIDFromContext(r.Context())
+			if !ok {
+				handleAuthError(w, "missing identity")
+
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, consts.Megabyte))
+			if err != nil {
+				httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+				return
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			stored, err := store.BeginIdempotentRequest(userID, key, requestFingerprint(r, body))
+			switch {
+			case errors.Is(err, errormsg.ErrIdempotencyKeyReused):
+				httputils.ErrorJSON(w, err, http.StatusUnprocessableEntity)
+
+				return
+			case errors.Is(err, errormsg.ErrIdempotencyKeyInProgress):
+				httputils.ErrorJSON(w, err, http.StatusConflict)
+
+				return
+			case err != nil:
+				log.Printf("failed to reserve idempotency key of user %d: %v", userID, err)
+				httputils.ErrorJSON(w, errormsg.ErrIdempotency, http.StatusInternalServerError)
+
+				return
+			case stored != nil:
+				replay(w, stored)
+
+				return
+			}
+
+			recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+
+			if recorder.statusCode >= http.StatusInternalServerError {
+				if err := store.ReleaseIdempotentRequest(userID, key); err != nil {
+					log.Printf("failed to release idempotency key of user %d: %v", userID, err)
+				}
+
+				return
+			}
+
+			err = store.CompleteIdempotentRequest(userID, key, calltypes.IdempotentResponse{
+				StatusCode: recorder.statusCode,
+				Header:     replayableHeader(recorder.Header()),
+				Body:       recorder.body.Bytes(),
+			})
+			if err != nil {
+				log.Printf("failed to store idempotent response of user %d: %v", userID, err)
+			}
+		})
+	}
+}
+
+// requestFingerprint identifies the request a key was used for.
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// replayableHeader returns a copy of the response headers without the ones that describe the connection
+// rather than the response.
+func replayableHeader(header http.Header) http.Header {
+	replayable := header.Clone()
+
+	for _, name := range []string{"Connection", "Content-Length", "Date", "Transfer-Encoding"} {
+		replayable.Del(name)
+	}
+
+	return replayable
+}
+
+func replay(w http.ResponseWriter, stored *calltypes.IdempotentResponse) {
+	for name, values := range stored.Header {
+		w.Header()[name] = values
+	}
+
+	w.Header().Set(consts.IdempotencyReplayedHeader, strconv.FormatBool(true))
+	w.WriteHeader(stored.StatusCode)
+
+	if _, err := w.Write(stored.Body); err != nil {
+		log.Println("failed to replay idempotent response: ", err)
+	}
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// responseRecorder passes the response through and keeps a copy of it.
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rr *responseRecorder) WriteHeader(statusCode int) {
+	if !rr.wroteHeader {
+		rr.statusCode = statusCode
+		rr.wroteHeader = true
+	}
+
+	rr.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	rr.wroteHeader = true
+	rr.body.Write(b)
+
+	n, err := rr.ResponseWriter.Write(b)
+	if err != nil {
+		return n, err //nolint: wrapcheck
+	}
+
+	return n, nil
+}
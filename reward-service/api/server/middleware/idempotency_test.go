@@ -0,0 +1,180 @@
+package middleware_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reward-service/api/calltypes"
+	"reward-service/api/server/middleware"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type storedKey struct {
+	fingerprint string
+	response    *calltypes.IdempotentResponse
+}
+
+// memoryIdempotencyStore keeps idempotency keys in memory.
+type memoryIdempotencyStore struct {
+	mu   sync.Mutex
+	keys map[string]*storedKey
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{keys: make(map[string]*storedKey)}
+}
+
+func (s *memoryIdempotencyStore) BeginIdempotentRequest(userID int, key, fingerprint string,
+) (*calltypes.IdempotentResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.keys[fmt.Sprint(userID, key)]
+	if !ok {
+		s.keys[fmt.Sprint(userID, key)] = &storedKey{fingerprint: fingerprint}
+
+		return nil, nil
+	}
+
+	if stored.fingerprint != fingerprint {
+		return nil, errormsg.ErrIdempotencyKeyReused
+	}
+
+	if stored.response == nil {
+		return nil, errormsg.ErrIdempotencyKeyInProgress
+	}
+
+	return stored.response, nil
+}
+
+func (s *memoryIdempotencyStore) CompleteIdempotentRequest(userID int, key string,
+	response calltypes.IdempotentResponse,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[fmt.Sprint(userID, key)].response = &response
+
+	return nil
+}
+
+func (s *memoryIdempotencyStore) ReleaseIdempotentRequest(userID int, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.keys, fmt.Sprint(userID, key))
+
+	return nil
+}
+
+func TestIdempotency(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		requests        []string
+		handlerStatus   int
+		expectedStatus  []int
+		expectedCalls   int
+		expectedReplays int
+	}{
+		{
+			name:            "retry with the same key is replayed",
+			requests:        []string{`{"referrer":"ABCD"}`, `{"referrer":"ABCD"}`},
+			handlerStatus:   http.StatusOK,
+			expectedStatus:  []int{http.StatusOK, http.StatusOK},
+			expectedCalls:   1,
+			expectedReplays: 1,
+		},
+		{
+			name:            "client errors are replayed as well",
+			requests:        []string{`{"referrer":"ABCD"}`, `{"referrer":"ABCD"}`},
+			handlerStatus:   http.StatusConflict,
+			expectedStatus:  []int{http.StatusConflict, http.StatusConflict},
+			expectedCalls:   1,
+			expectedReplays: 1,
+		},
+		{
+			name:           "key reused with another payload",
+			requests:       []string{`{"referrer":"ABCD"}`, `{"referrer":"EFGH"}`},
+			handlerStatus:  http.StatusOK,
+			expectedStatus: []int{http.StatusOK, http.StatusUnprocessableEntity},
+			expectedCalls:  1,
+		},
+		{
+			name:           "server errors are not stored",
+			requests:       []string{`{"referrer":"ABCD"}`, `{"referrer":"ABCD"}`},
+			handlerStatus:  http.StatusInternalServerError,
+			expectedStatus: []int{http.StatusInternalServerError, http.StatusInternalServerError},
+			expectedCalls:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			calls := 0
+			handler := middleware.Idempotency(newMemoryIdempotencyStore())(
+				http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					calls++
+
+					w.Header().Set("Content-Type", "application/json")
+					http.SetCookie(w, &http.Cookie{Name: "accessToken", MaxAge: -1})
+					w.WriteHeader(tt.handlerStatus)
+					fmt.Fprintf(w, `{"call":%d}`, calls)
+				}))
+
+			replays := 0
+
+			for i, body := range tt.requests {
+				req := httptest.NewRequest(http.MethodPost, "/users/5/referrer", strings.NewReader(body))
+				req.Header.Set(consts.IdempotencyKeyHeader, "key-1")
+				req = req.WithContext(middleware.WithIdentity(req.Context(), middleware.Identity{UserID: 5}))
+
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+
+				assert.Equal(t, tt.expectedStatus[i], rr.Code)
+
+				if rr.Header().Get(consts.IdempotencyReplayedHeader) == "true" {
+					replays++
+
+					assert.JSONEq(t, `{"call":1}`, rr.Body.String())
+					assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+					assert.Contains(t, rr.Header().Get("Set-Cookie"), "accessToken=")
+				}
+			}
+
+			assert.Equal(t, tt.expectedCalls, calls)
+			assert.Equal(t, tt.expectedReplays, replays)
+		})
+	}
+}
+
+func TestIdempotency_WithoutKey(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	handler := middleware.Idempotency(newMemoryIdempotencyStore())(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			calls++
+
+			w.WriteHeader(http.StatusOK)
+		}))
+
+	for range 2 {
+		req := httptest.NewRequest(http.MethodPost, "/users/5/task/complete", nil)
+		req = req.WithContext(middleware.WithIdentity(req.Context(), middleware.Identity{UserID: 5}))
+
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"reward-service/api/server/httputils"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type contextKey string
+
+const identityKey contextKey = "identity"
+
+// Identity describes the authenticated caller.
+type Identity struct {
+	UserID    int
+	SessionID int
+	Role      string
+}
+
+// WithIdentity returns a copy of ctx carrying the caller's identity.
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey, identity)
+}
+
+// IdentityFromContext returns the caller's identity put into the context by Auth.
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey).(Identity)
+
+	return identity, ok
+}
+
+// UserIDFromContext returns the caller's user ID put into the context by Auth.
+func UserIDFromContext(ctx context.Context) (int, bool) {
+	identity, ok := IdentityFromContext(ctx)
+
+	return identity.UserID, ok
+}
+
+// OwnerOrAdmin lets the request through only when the {id} URL parameter is the caller's own user ID.
+// Admins may act on any user.
+func OwnerOrAdmin() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			identity, ok := IdentityFromContext(r.Context())
+			if !ok {
+				handleAuthError(w, "missing identity")
+
+				return
+			}
+
+			if identity.Role == consts.RoleAdmin {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			targetID, err := strconv.Atoi(chi.URLParam(r, "id"))
+			if err != nil {
+				httputils.ErrorJSON(w, errormsg.ErrInvalidID, http.StatusBadRequest)
+
+				return
+			}
+
+			if targetID != identity.UserID {
+				httputils.ErrorJSON(w, errormsg.ErrForbidden, http.StatusForbidden)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
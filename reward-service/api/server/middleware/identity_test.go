@@ -0,0 +1,115 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reward-service/api/server/middleware"
+	"reward-service/pkg/consts"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOwnerOrAdmin(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		identity       *middleware.Identity
+		urlID          string
+		expectedStatus int
+	}{
+		{
+			name:           "owner passes",
+			identity:       &middleware.Identity{UserID: 5},
+			urlID:          "5",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "other user is forbidden",
+			identity:       &middleware.Identity{UserID: 5},
+			urlID:          "6",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "admin may act on anyone",
+			identity:       &middleware.Identity{UserID: 1, Role: consts.RoleAdmin},
+			urlID:          "6",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid ID",
+			identity:       &middleware.Identity{UserID: 5},
+			urlID:          "abc",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing identity",
+			identity:       nil,
+			urlID:          "5",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := middleware.OwnerOrAdmin()(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/users/"+tt.urlID+"/referrer", nil)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.urlID)
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+
+			if tt.identity != nil {
+				ctx = middleware.WithIdentity(ctx, *tt.identity)
+			}
+
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req.WithContext(ctx))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		role           string
+		expectedStatus int
+	}{
+		{name: "admin passes", role: consts.RoleAdmin, expectedStatus: http.StatusOK},
+		{name: "moderator passes", role: consts.RoleModerator, expectedStatus: http.StatusOK},
+		{name: "user is forbidden", role: consts.RoleUser, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := middleware.RequireRole(consts.RoleModerator, consts.RoleAdmin)(
+				http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusOK)
+				}))
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/users", nil)
+			req = req.WithContext(middleware.WithIdentity(req.Context(), middleware.Identity{UserID: 1, Role: tt.role}))
+
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
@@ -1,14 +1,23 @@
 package middleware
 
 import (
-	"context"
 	"encoding/json"
+	"log"
 	"net/http"
+	"reward-service/api/server/httputils"
 	"reward-service/internal/token"
+	"reward-service/pkg/errormsg"
+	"slices"
+	"time"
 )
 
-// Auth middleware checks JWT token from cookies.
-func Auth() func(http.Handler) http.Handler {
+// RevocationChecker reports whether an access token was revoked before it expired.
+type RevocationChecker interface {
+	IsAccessTokenRevoked(tokenID string, userID, sessionID int, issuedAt time.Time) (bool, error)
+}
+
+// Auth middleware checks JWT token from cookies and rejects revoked tokens.
+func Auth(revocations RevocationChecker) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			accessCookie, err := r.Cookie("accessToken")
@@ -27,19 +36,61 @@ func Auth() func(http.Handler) http.Handler {
 				return
 			}
 
-			userID, ok := claims["sub"].(float64)
-			if !ok {
-				handleAuthError(w, "invalid user ID in token")
+			accessClaims, err := token.ParseAccessClaims(claims)
+			if err != nil {
+				handleAuthError(w, err.Error())
+
+				return
+			}
+
+			revoked, err := revocations.IsAccessTokenRevoked(
+				accessClaims.TokenID, accessClaims.UserID, accessClaims.SessionID, accessClaims.IssuedAt)
+			if err != nil {
+				log.Println("failed to check access token revocation: ", err)
+				handleAuthError(w, "couldn't verify access token")
 
 				return
 			}
 
-			ctx := context.WithValue(r.Context(), "userID", int(userID)) //nolint: revive, staticcheck
+			if revoked {
+				handleAuthError(w, "access token has been revoked")
+
+				return
+			}
+
+			ctx := WithIdentity(r.Context(), Identity{
+				UserID:    accessClaims.UserID,
+				SessionID: accessClaims.SessionID,
+				Role:      accessClaims.Role,
+			})
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+// RequireRole lets the request through only when the caller has one of the provided roles.
+// It must be mounted after Auth.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			identity, ok := IdentityFromContext(r.Context())
+			if !ok {
+				handleAuthError(w, "missing identity")
+
+				return
+			}
+
+			if !slices.Contains(roles, identity.Role) {
+				httputils.ErrorJSON(w, errormsg.ErrInsufficientRole, http.StatusForbidden)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // handleAuthError handle errors from Auth middleware.
 func handleAuthError(w http.ResponseWriter, message string) {
 	w.Header().Set("Content-Type", "application/json")
@@ -1,8 +1,12 @@
 package network
 
 import (
+	"encoding/base64"
 	"os"
+	"reward-service/pkg/consts"
 	"reward-service/pkg/errormsg"
+	"strconv"
+	"strings"
 )
 
 type Config struct {
@@ -15,6 +19,41 @@ type Config struct {
 	JWT struct {
 		Secret string
 	}
+	Admin struct {
+		Email    string
+		Password string
+	}
+	Referral struct {
+		LinkTemplate  string
+		TierRewards   []int
+		RefereeReward int
+	}
+	Verifiers struct {
+		// Fake confirms every task completion without calling the providers.
+		Fake     bool
+		Telegram struct {
+			APIURL    string
+			BotToken  string
+			ChannelID string
+		}
+		X struct {
+			APIURL      string
+			BearerToken string
+			AccountID   string
+		}
+	}
+	Leaderboard struct {
+		// Ranking is "competition" (1, 2, 2, 4) or "dense" (1, 2, 2, 3).
+		Ranking string
+	}
+	Vouchers struct {
+		// Key encrypts voucher codes at rest, vouchers are disabled without it.
+		Key []byte
+	}
+	SecretCodes struct {
+		// Key keys the hashes of secret codes, secret codes are disabled without it.
+		Key []byte
+	}
 }
 
 func Load() (*Config, error) {
@@ -22,6 +61,63 @@ func Load() (*Config, error) {
 
 	cfg.DB.DSN = os.Getenv("DSN")
 	cfg.Server.Port = os.Getenv("PORT")
+	cfg.Admin.Email = os.Getenv("ADMIN_EMAIL")
+	cfg.Admin.Password = os.Getenv("ADMIN_PASSWORD")
+	cfg.Referral.LinkTemplate = os.Getenv("REFERRAL_LINK_TEMPLATE")
+	cfg.Referral.TierRewards = []int{consts.FixedRewardForReferrer, consts.SecondTierReferrerReward}
+	cfg.Referral.RefereeReward = consts.FixedRewardForReferee
+	cfg.Verifiers.Fake = os.Getenv("TASK_VERIFIER_MODE") == "fake"
+	cfg.Verifiers.Telegram.APIURL = os.Getenv("TELEGRAM_API_URL")
+	cfg.Verifiers.Telegram.BotToken = os.Getenv("TELEGRAM_BOT_TOKEN")
+	cfg.Verifiers.Telegram.ChannelID = os.Getenv("TELEGRAM_CHANNEL_ID")
+	cfg.Verifiers.X.APIURL = os.Getenv("X_API_URL")
+	cfg.Verifiers.X.BearerToken = os.Getenv("X_BEARER_TOKEN")
+	cfg.Verifiers.X.AccountID = os.Getenv("X_ACCOUNT_ID")
+	cfg.Leaderboard.Ranking = consts.RankingCompetition
+
+	if tierRewards := os.Getenv("REFERRAL_TIER_REWARDS"); tierRewards != "" {
+		rewards, err := parseRewards(tierRewards)
+		if err != nil || len(rewards) > consts.MaxReferralTiers {
+			return nil, errormsg.ErrInvalidReferralRewards
+		}
+
+		cfg.Referral.TierRewards = rewards
+	}
+
+	if refereeReward := os.Getenv("REFERRAL_REFEREE_REWARD"); refereeReward != "" {
+		rewards, err := parseRewards(refereeReward)
+		if err != nil || len(rewards) != 1 {
+			return nil, errormsg.ErrInvalidReferralRewards
+		}
+
+		cfg.Referral.RefereeReward = rewards[0]
+	}
+
+	if ranking := os.Getenv("LEADERBOARD_RANKING"); ranking != "" {
+		if ranking != consts.RankingCompetition && ranking != consts.RankingDense {
+			return nil, errormsg.ErrInvalidRanking
+		}
+
+		cfg.Leaderboard.Ranking = ranking
+	}
+
+	if voucherKey := os.Getenv("VOUCHER_ENCRYPTION_KEY"); voucherKey != "" {
+		key, err := base64.StdEncoding.DecodeString(voucherKey)
+		if err != nil {
+			return nil, errormsg.ErrInvalidVoucherKey
+		}
+
+		cfg.Vouchers.Key = key
+	}
+
+	if secretCodeKey := os.Getenv("SECRET_CODE_KEY"); secretCodeKey != "" {
+		key, err := base64.StdEncoding.DecodeString(secretCodeKey)
+		if err != nil {
+			return nil, errormsg.ErrInvalidSecretCodeKey
+		}
+
+		cfg.SecretCodes.Key = key
+	}
 
 	if cfg.DB.DSN == "" {
 		return nil, errormsg.ErrDSNRequired
@@ -33,3 +129,20 @@ func Load() (*Config, error) {
 
 	return cfg, nil
 }
+
+// parseRewards parses comma separated non-negative numbers.
+func parseRewards(value string) ([]int, error) {
+	parts := strings.Split(value, ",")
+	rewards := make([]int, 0, len(parts))
+
+	for _, part := range parts {
+		reward, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || reward < 0 {
+			return nil, errormsg.ErrInvalidReferralRewards
+		}
+
+		rewards = append(rewards, reward)
+	}
+
+	return rewards, nil
+}
@@ -11,8 +11,8 @@ func CORS() func(http.Handler) http.Handler {
 	return cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"https://*", "http://*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", consts.IdempotencyKeyHeader},
+		ExposedHeaders:   []string{"Link", consts.IdempotencyReplayedHeader},
 		AllowCredentials: true,
 		MaxAge:           consts.MaxAge,
 	})
@@ -6,6 +6,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"reward-service/api/server/middleware"
 	"reward-service/internal/service"
+	"reward-service/pkg/consts"
 )
 
 // SetupRoutes set up the Routes
@@ -14,19 +15,90 @@ func SetupRoutes(svc *service.RewardService) http.Handler {
 	r := chi.NewRouter()
 
 	r.Group(func(secure chi.Router) {
-		secure.Use(middleware.Auth())
+		secure.Use(middleware.Auth(svc.Repo))
 
-		secure.Get("/users/{id}/status", svc.RetrieveOne)
 		secure.Get("/users/leaderboard", svc.GetLeaderboard)
-		secure.Post("/users/{id}/task/telegramSign", svc.CompleteTelegramSign)
-		secure.Post("/users/{id}/task/XSign", svc.CompleteXSign)
-		secure.Post("/users/{id}/referrer", svc.RedeemReferrer)
-		secure.Post("/users/{id}/task/complete", svc.SomeTask)
-		secure.Post("/users/{id}/kuarhodron", svc.Kuarhodron)
+		secure.Get("/tasks", svc.ListTasks)
+		secure.Get("/store/items", svc.ListStoreItems)
+		secure.Get("/tiers", svc.ListTiers)
+		secure.Get("/seasons", svc.ListSeasons)
+		secure.Get("/seasons/{id}/leaderboard", svc.GetSeasonLeaderboard)
+		secure.Get("/users/me/sessions", svc.GetSessions)
+		secure.Get("/users/me/referral", svc.GetReferral)
+		secure.Get("/users/me/vouchers", svc.GetVouchers)
+
+		// Idempotency goes after the access checks, so only the responses of the handlers are stored.
+		secure.Group(func(self chi.Router) {
+			self.Use(middleware.Idempotency(svc.Repo))
+
+			self.Post("/logout-all", svc.LogoutAll)
+			self.Delete("/users/me/sessions/{id}", svc.RevokeSession)
+		})
+
+		secure.Route("/users/{id}", func(user chi.Router) {
+			user.Use(middleware.OwnerOrAdmin())
+			user.Use(middleware.Idempotency(svc.Repo))
+
+			user.Get("/status", svc.RetrieveOne)
+			user.Post("/task/telegramSign", svc.CompleteTelegramSign)
+			user.Post("/task/XSign", svc.CompleteXSign)
+			user.Post("/referrer", svc.RedeemReferrer)
+			user.Post("/task/complete", svc.SomeTask)
+			user.Post("/tasks/{slug}/complete", svc.CompleteTaskBySlug)
+			user.Post("/codes/redeem", svc.RedeemSecretCode)
+			user.Post("/promo-codes/redeem", svc.RedeemPromoCode)
+			user.Post("/redemptions", svc.RedeemItem)
+			user.Get("/redemptions", svc.GetRedemptions)
+			user.Get("/transactions", svc.GetTransactions)
+			user.Get("/referrals", svc.GetReferralStats)
+			user.Get("/badges", svc.GetUserBadges)
+			user.Get("/rank", svc.GetRank)
+			user.Post("/checkin", svc.CheckIn)
+			user.Post("/streak/freezes", svc.BuyStreakFreeze)
+			user.Put("/timezone", svc.SetTimezone)
+		})
+	})
+
+	r.Route("/admin", func(admin chi.Router) {
+		admin.Use(middleware.Auth(svc.Repo))
+		admin.Use(middleware.RequireRole(consts.RoleModerator, consts.RoleAdmin))
+
+		admin.Get("/users", svc.ListUsers)
+
+		admin.Group(func(adminOnly chi.Router) {
+			adminOnly.Use(middleware.RequireRole(consts.RoleAdmin))
+			adminOnly.Use(middleware.Idempotency(svc.Repo))
+
+			adminOnly.Put("/users/{id}/role", svc.SetUserRole)
+			adminOnly.Get("/tasks", svc.ListAllTasks)
+			adminOnly.Post("/tasks", svc.CreateTask)
+			adminOnly.Put("/tasks/{slug}", svc.UpdateTask)
+			adminOnly.Get("/codes", svc.ListSecretCodes)
+			adminOnly.Post("/codes", svc.CreateSecretCode)
+			adminOnly.Get("/campaigns", svc.ListCampaigns)
+			adminOnly.Post("/campaigns", svc.CreateCampaign)
+			adminOnly.Put("/campaigns/{id}", svc.UpdateCampaign)
+			adminOnly.Delete("/campaigns/{id}", svc.DeleteCampaign)
+			adminOnly.Get("/store/items", svc.ListAllStoreItems)
+			adminOnly.Post("/store/items", svc.CreateStoreItem)
+			adminOnly.Put("/store/items/{id}", svc.UpdateStoreItem)
+			adminOnly.Post("/store/items/{id}/vouchers", svc.UploadVouchers)
+			adminOnly.Post("/tiers", svc.CreateTier)
+			adminOnly.Put("/tiers/{id}", svc.UpdateTier)
+			adminOnly.Delete("/tiers/{id}", svc.DeleteTier)
+			adminOnly.Post("/seasons", svc.CreateSeason)
+			adminOnly.Get("/badges", svc.ListBadges)
+			adminOnly.Post("/badges", svc.CreateBadge)
+			adminOnly.Put("/badges/{id}", svc.UpdateBadge)
+			adminOnly.Get("/redemptions", svc.ListRedemptions)
+			adminOnly.Put("/redemptions/{id}/status", svc.SetRedemptionStatus)
+		})
 	})
 
 	r.Post("/authenticate", svc.Authenticate)
 	r.Post("/registrate", svc.Registrate)
+	r.Post("/refresh", svc.Refresh)
+	r.Post("/logout", svc.Logout)
 
 	return r
 }
@@ -1,14 +1,18 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"github.com/go-chi/chi/v5"
 	httpSwagger "github.com/swaggo/http-swagger"
 	"log"
 	"net/http"
+	"reward-service/api/calltypes"
 	"reward-service/api/server/router/network"
 	"reward-service/internal/postgres/models"
+	"reward-service/internal/secretcode"
 	"reward-service/internal/service"
+	"reward-service/internal/voucher"
 	"reward-service/migrations"
 	"reward-service/pkg/consts"
 	"reward-service/pkg/db"
@@ -19,6 +23,7 @@ import (
 type Server struct {
 	cfg    *network.Config
 	router *chi.Mux
+	svc    *service.RewardService
 }
 
 func NewServer(cfg *network.Config) (*Server, error) {
@@ -32,7 +37,43 @@ func NewServer(cfg *network.Config) (*Server, error) {
 	}
 
 	repo := models.NewPostgresRepository(conn)
+
+	if cfg.Admin.Email != "" {
+		adminID, err := repo.EnsureAdmin(cfg.Admin.Email, cfg.Admin.Password)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errormsg.ErrBootstrapAdmin, err)
+		}
+
+		log.Printf("Admin account %s is ready (id: %d)", cfg.Admin.Email, adminID)
+	}
+
 	svc := service.NewRewardService(repo)
+	if cfg.Referral.LinkTemplate != "" {
+		svc.Config.ReferralLinkTemplate = cfg.Referral.LinkTemplate
+	}
+
+	svc.Config.ReferralRewards = calltypes.ReferralRewards{
+		Tiers:   cfg.Referral.TierRewards,
+		Referee: cfg.Referral.RefereeReward,
+	}
+
+	svc.Config.Ranking = cfg.Leaderboard.Ranking
+
+	registerVerifiers(svc, cfg)
+
+	if cfg.Vouchers.Key != nil {
+		svc.Vouchers, err = voucher.NewCipher(cfg.Vouchers.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up vouchers: %w", err)
+		}
+	}
+
+	if cfg.SecretCodes.Key != nil {
+		svc.SecretCodes, err = secretcode.NewHasher(cfg.SecretCodes.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up secret codes: %w", err)
+		}
+	}
 
 	router := chi.NewRouter()
 	router.Use(network.CORS())
@@ -44,6 +85,7 @@ func NewServer(cfg *network.Config) (*Server, error) {
 	return &Server{
 		cfg:    cfg,
 		router: router,
+		svc:    svc,
 	}, nil
 }
 
@@ -56,6 +98,16 @@ func (s *Server) Start() error {
 		IdleTimeout:  consts.IdleTimeout * time.Second,
 	}
 
+	go s.svc.RunPointExpiry(context.Background(), consts.PointsExpiryInterval)
+	go s.svc.RunSeasonClosing(context.Background(), consts.SeasonClosingInterval)
+	go s.svc.RunRankBadges(context.Background(), consts.RankBadgesInterval)
+
+	go func() {
+		if err := s.svc.RefreshVoucherFingerprints(); err != nil {
+			log.Printf("%v, retrying on the next start", err)
+		}
+	}()
+
 	log.Printf("Server started on :%s", s.cfg.Server.Port)
 
 	if err := server.ListenAndServe(); err != nil {
@@ -64,3 +116,27 @@ func (s *Server) Start() error {
 
 	return nil
 }
+
+// registerVerifiers sets up the verifiers of the providers that are configured. Tasks of a provider
+// without verifier can't be completed.
+func registerVerifiers(svc *service.RewardService, cfg *network.Config) {
+	if cfg.Verifiers.Fake {
+		log.Println("Task verifiers are faked, every completion is confirmed")
+
+		svc.Verifiers[consts.VerifierTelegram] = service.NewFakeVerifier()
+		svc.Verifiers[consts.VerifierX] = service.NewFakeVerifier()
+
+		return
+	}
+
+	telegram := cfg.Verifiers.Telegram
+	if telegram.BotToken != "" && telegram.ChannelID != "" {
+		svc.Verifiers[consts.VerifierTelegram] = service.NewTelegramVerifier(svc.Client, telegram.APIURL,
+			telegram.BotToken, telegram.ChannelID)
+	}
+
+	x := cfg.Verifiers.X
+	if x.BearerToken != "" && x.AccountID != "" {
+		svc.Verifiers[consts.VerifierX] = service.NewXVerifier(svc.Client, x.APIURL, x.BearerToken, x.AccountID)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"reward-service/api/server"
 	"reward-service/api/server/router/network"
 	_ "reward-service/docs"
+	_ "time/tzdata" // the runtime image has no zoneinfo, users' timezones are loaded from the embedded one.
 )
 
 // @title Reward Service API
@@ -522,7 +522,7 @@ const docTemplate = `{
             "properties": {
                 "waterPassword": {
                     "type": "string",
-                    "example": "KUARHODRON"
+                    "example": "ABCD-EFGH"
                 }
             }
         },
@@ -0,0 +1,40 @@
+// Package badge holds the rules badges are awarded by. A rule is a metric of the user and a threshold:
+// counters must reach the threshold, ranks must be within it.
+package badge
+
+import (
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+)
+
+// Metrics lists every metric a rule can use.
+var Metrics = []string{
+	consts.BadgeMetricTasksCompleted,
+	consts.BadgeMetricReferrals,
+	consts.BadgeMetricLifetimePoints,
+	consts.BadgeMetricMonthlyRank,
+}
+
+// Validate checks that the rule uses a known metric with a positive threshold.
+func Validate(metric string, threshold int) error {
+	if threshold <= 0 {
+		return errormsg.ErrInvalidBadgeRule
+	}
+
+	for _, known := range Metrics {
+		if metric == known {
+			return nil
+		}
+	}
+
+	return errormsg.ErrInvalidBadgeRule
+}
+
+// Satisfied reports whether the value of the metric earns the badge. A rank of zero means the user isn't ranked.
+func Satisfied(metric string, threshold, value int) bool {
+	if metric == consts.BadgeMetricMonthlyRank {
+		return value > 0 && value <= threshold
+	}
+
+	return value >= threshold
+}
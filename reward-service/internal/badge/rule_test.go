@@ -0,0 +1,44 @@
+package badge_test
+
+import (
+	"reward-service/internal/badge"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, badge.Validate(consts.BadgeMetricReferrals, 10))
+	assert.ErrorIs(t, badge.Validate(consts.BadgeMetricReferrals, 0), errormsg.ErrInvalidBadgeRule)
+	assert.ErrorIs(t, badge.Validate("logins", 1), errormsg.ErrInvalidBadgeRule)
+}
+
+func TestSatisfied(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		metric    string
+		threshold int
+		value     int
+		expected  bool
+	}{
+		{name: "counter reaches threshold", metric: consts.BadgeMetricTasksCompleted, threshold: 1, value: 1, expected: true},
+		{name: "counter below threshold", metric: consts.BadgeMetricReferrals, threshold: 10, value: 9, expected: false},
+		{name: "rank within threshold", metric: consts.BadgeMetricMonthlyRank, threshold: 100, value: 100, expected: true},
+		{name: "rank outside threshold", metric: consts.BadgeMetricMonthlyRank, threshold: 100, value: 101, expected: false},
+		{name: "user isn't ranked", metric: consts.BadgeMetricMonthlyRank, threshold: 100, value: 0, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, badge.Satisfied(tt.metric, tt.threshold, tt.value))
+		})
+	}
+}
@@ -0,0 +1,437 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"reward-service/api/calltypes"
+	"reward-service/internal/badge"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"time"
+)
+
+const badgeColumns = `id, slug, name, description, metric, threshold, reward, active, created_at, updated_at`
+
+// badgeMetrics select the value of every counter badge metric for the user $1. Rank badges depend on all users
+// and are awarded by AwardRankBadges instead.
+var badgeMetrics = map[string]string{
+	consts.BadgeMetricTasksCompleted: `SELECT count(*) FROM task_completions WHERE user_id = $1 AND status = 'verified'`,
+	consts.BadgeMetricReferrals:      `SELECT count(*) FROM referrals WHERE referrer_id = $1`,
+	consts.BadgeMetricLifetimePoints: `SELECT lifetime_points FROM users WHERE id = $1`,
+}
+
+// GetBadges returns all badges ordered by id.
+func (u *PostgresRepository) GetBadges() ([]*calltypes.Badge, error) {
+	query := `select ` + badgeColumns + ` from badges order by id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), consts.DbTimeout)
+	defer cancel()
+
+	rows, err := u.Conn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch badges: %w", err)
+	}
+	defer rows.Close()
+
+	badges := []*calltypes.Badge{}
+
+	for rows.Next() {
+		var b *calltypes.Badge
+
+		b, err = scanBadge(rows)
+		if err != nil {
+			log.Printf("Error scanning badge: %v", err)
+
+			return nil, err
+		}
+
+		badges = append(badges, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch badges: %w", err)
+	}
+
+	return badges, nil
+}
+
+// CreateBadge adds new badge. Users who already meet its rule get it on their next change.
+func (u *PostgresRepository) CreateBadge(b calltypes.Badge) (int, error) {
+	stmt := `insert into badges (slug, name, description, metric, threshold, reward, active, created_at, updated_at)
+             values ($1, $2, $3, $4, $5, $6, $7, $8, $8) returning id`
+
+	var newID int
+
+	err := u.queryRow(context.Background(), stmt,
+		b.Slug,
+		b.Name,
+		b.Description,
+		b.Metric,
+		b.Threshold,
+		b.Reward,
+		b.Active,
+		time.Now(),
+	).Scan(&newID)
+	if isUniqueViolation(err) {
+		return 0, errormsg.ErrBadgeExists
+	}
+
+	if err != nil {
+		log.Println("failed to insert new badge: ", err)
+
+		return 0, fmt.Errorf("failed to insert new badge: %w", err)
+	}
+
+	return newID, nil
+}
+
+// UpdateBadge updates the badge with the same id, users keep the badge and the reward they already got.
+func (u *PostgresRepository) UpdateBadge(b calltypes.Badge) error {
+	stmt := `update badges set slug = $1, name = $2, description = $3, metric = $4, threshold = $5, reward = $6,
+             active = $7, updated_at = $8
+             where id = $9`
+
+	result, err := u.execQuery(context.Background(), stmt,
+		b.Slug,
+		b.Name,
+		b.Description,
+		b.Metric,
+		b.Threshold,
+		b.Reward,
+		b.Active,
+		time.Now(),
+		b.ID,
+	)
+	if isUniqueViolation(err) {
+		return errormsg.ErrBadgeExists
+	}
+
+	if err != nil {
+		log.Println("failed to update badge: ", err)
+
+		return fmt.Errorf("failed to update badge: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update badge: %w", err)
+	}
+
+	if affected == 0 {
+		return errormsg.ErrBadgeNotFound
+	}
+
+	return nil
+}
+
+// GetUserBadges returns the badges awarded to the user, newest first.
+func (u *PostgresRepository) GetUserBadges(userID int) ([]*calltypes.UserBadge, error) {
+	query := `select b.slug, b.name, b.description, b.reward, ub.awarded_at
+              from user_badges ub
+              join badges b on b.id = ub.badge_id
+              where ub.user_id = $1
+              order by ub.awarded_at desc, b.id desc`
+
+	ctx, cancel := context.WithTimeout(context.Background(), consts.DbTimeout)
+	defer cancel()
+
+	rows, err := u.Conn.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user's badges: %w", err)
+	}
+	defer rows.Close()
+
+	badges := []*calltypes.UserBadge{}
+
+	for rows.Next() {
+		var b calltypes.UserBadge
+
+		err := rows.Scan(&b.Slug, &b.Name, &b.Description, &b.Reward, &b.AwardedAt)
+		if err != nil {
+			log.Printf("Error scanning user's badge: %v", err)
+
+			return nil, fmt.Errorf("failed to scan user's badge: %w", err)
+		}
+
+		badges = append(badges, &b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch user's badges: %w", err)
+	}
+
+	return badges, nil
+}
+
+// AwardRankBadges awards the active monthly_rank badges to the users the leaderboard of the points earned since
+// ranks within their thresholds. Ranking the month takes all users, so it runs periodically rather than on every
+// ledger write. Rewards are paid like awardBadges does, every user is handled in their own transaction.
+// The number of awarded badges is returned.
+func (u *PostgresRepository) AwardRankBadges(since time.Time, ranking string) (int, error) {
+	badges, err := u.rankBadges()
+	if err != nil {
+		return 0, err
+	}
+
+	var awarded int
+
+	for _, b := range badges {
+		userIDs, err := u.usersRankedWithin(since, ranking, b)
+		if err != nil {
+			return awarded, err
+		}
+
+		for _, userID := range userIDs {
+			var inserted bool
+
+			err := u.withTx(context.Background(), nil, func(ctx context.Context, tx *sql.Tx) error {
+				var err error
+
+				inserted, err = insertUserBadge(ctx, tx, userID, b, time.Now())
+				if err != nil || !inserted {
+					return err
+				}
+
+				if b.Reward > 0 {
+					err = u.applyLedgerEntry(ctx, tx, calltypes.PointTransaction{
+						UserID:      userID,
+						Delta:       b.Reward,
+						Reason:      consts.ReasonBadgeReward,
+						ReferenceID: b.Slug,
+					})
+					if err != nil {
+						return err
+					}
+				}
+
+				return u.awardBadges(ctx, tx, userID)
+			})
+			if err != nil {
+				return awarded, fmt.Errorf("failed to award badge %s to user %d: %w", b.Slug, userID, err)
+			}
+
+			if inserted {
+				awarded++
+			}
+		}
+	}
+
+	return awarded, nil
+}
+
+// rankBadges returns the active monthly_rank badges.
+func (u *PostgresRepository) rankBadges() ([]*calltypes.Badge, error) {
+	query := `select ` + badgeColumns + ` from badges where active and metric = $1 order by id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), consts.DbTimeout)
+	defer cancel()
+
+	rows, err := u.Conn.QueryContext(ctx, query, consts.BadgeMetricMonthlyRank)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch badges: %w", err)
+	}
+	defer rows.Close()
+
+	var badges []*calltypes.Badge
+
+	for rows.Next() {
+		b, err := scanBadge(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		badges = append(badges, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch badges: %w", err)
+	}
+
+	return badges, nil
+}
+
+// usersRankedWithin returns the users without the badge that newLeaderboardQuery ranks within its threshold
+// by the points earned since.
+func (u *PostgresRepository) usersRankedWithin(since time.Time, ranking string, b *calltypes.Badge) ([]int, error) {
+	rank := `rank()`
+	if ranking == consts.RankingDense {
+		rank = `dense_rank()`
+	}
+
+	q := newLeaderboardQuery(since, time.Time{})
+
+	query := `select id from (select id, ` + rank + ` over (order by score desc) as rank from (` + q.from + `) b) r
+              where rank <= ` + q.arg(b.Threshold) + ` and not exists (
+                  select 1 from user_badges ub where ub.badge_id = ` + q.arg(b.ID) + ` and ub.user_id = r.id)
+              order by id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), consts.DbTimeout)
+	defer cancel()
+
+	rows, err := u.Conn.QueryContext(ctx, query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rank users for badge %s: %w", b.Slug, err)
+	}
+	defer rows.Close()
+
+	var userIDs []int
+
+	for rows.Next() {
+		var userID int
+
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan ranked user: %w", err)
+		}
+
+		userIDs = append(userIDs, userID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to rank users for badge %s: %w", b.Slug, err)
+	}
+
+	return userIDs, nil
+}
+
+// awardBadges evaluates the rules of the active badges the users don't have yet and awards the ones they meet.
+// Rewards are written to the ledger like AddPoints does, a reward can earn another badge, so the rules are
+// evaluated again until nothing new is awarded. It must be called inside the transaction that changed the users.
+func (u *PostgresRepository) awardBadges(ctx context.Context, tx *sql.Tx, userIDs ...int) error {
+	for _, userID := range userIDs {
+		for {
+			awarded, err := awardUserBadges(ctx, tx, userID)
+			if err != nil {
+				return err
+			}
+
+			rewarded := false
+
+			for _, b := range awarded {
+				if b.Reward == 0 {
+					continue
+				}
+
+				err = u.applyLedgerEntry(ctx, tx, calltypes.PointTransaction{
+					UserID:      userID,
+					Delta:       b.Reward,
+					Reason:      consts.ReasonBadgeReward,
+					ReferenceID: b.Slug,
+				})
+				if err != nil {
+					return err
+				}
+
+				rewarded = true
+			}
+
+			if !rewarded {
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+func awardUserBadges(ctx context.Context, tx *sql.Tx, userID int) ([]*calltypes.Badge, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT `+badgeColumns+` FROM badges b
+             WHERE active AND metric <> $2
+               AND NOT EXISTS (SELECT 1 FROM user_badges ub WHERE ub.badge_id = b.id AND ub.user_id = $1)
+             ORDER BY id`, userID, consts.BadgeMetricMonthlyRank)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch badges: %w", err)
+	}
+
+	var pending []*calltypes.Badge
+
+	for rows.Next() {
+		b, err := scanBadge(rows)
+		if err != nil {
+			rows.Close()
+
+			return nil, err
+		}
+
+		pending = append(pending, b)
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch badges: %w", err)
+	}
+
+	now := time.Now()
+	values := map[string]int{}
+
+	var awarded []*calltypes.Badge
+
+	for _, b := range pending {
+		value, ok := values[b.Metric]
+		if !ok {
+			err := tx.QueryRowContext(ctx, badgeMetrics[b.Metric], userID).Scan(&value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to evaluate %s of user %d: %w", b.Metric, userID, err)
+			}
+
+			values[b.Metric] = value
+		}
+
+		if !badge.Satisfied(b.Metric, b.Threshold, value) {
+			continue
+		}
+
+		inserted, err := insertUserBadge(ctx, tx, userID, b, now)
+		if err != nil {
+			return nil, err
+		}
+
+		if inserted {
+			awarded = append(awarded, b)
+		}
+	}
+
+	return awarded, nil
+}
+
+// insertUserBadge awards the badge to the user and reports whether it is new. A badge awarded by a concurrent
+// transaction is not, the reward is that transaction's to pay.
+func insertUserBadge(ctx context.Context, tx *sql.Tx, userID int, b *calltypes.Badge, now time.Time) (bool, error) {
+	result, err := tx.ExecContext(ctx, `INSERT INTO user_badges (user_id, badge_id, awarded_at) VALUES ($1, $2, $3)
+             ON CONFLICT DO NOTHING`, userID, b.ID, now)
+	if err != nil {
+		return false, fmt.Errorf("failed to award badge: %w", err)
+	}
+
+	if inserted, err := result.RowsAffected(); err != nil || inserted == 0 {
+		return false, nil //nolint: nilerr
+	}
+
+	log.Printf("User %d is awarded badge %s", userID, b.Slug)
+
+	return true, nil
+}
+
+func scanBadge(row rowScanner) (*calltypes.Badge, error) {
+	var b calltypes.Badge
+
+	err := row.Scan(
+		&b.ID,
+		&b.Slug,
+		&b.Name,
+		&b.Description,
+		&b.Metric,
+		&b.Threshold,
+		&b.Reward,
+		&b.Active,
+		&b.CreatedAt,
+		&b.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan badge: %w", err)
+	}
+
+	return &b, nil
+}
@@ -0,0 +1,341 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"reward-service/api/calltypes"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"strconv"
+	"time"
+)
+
+const campaignColumns = `c.id, c.name, c.kind, c.multiplier_percent, c.promo_code, c.reward, c.max_redemptions,
+                         c.new_user_days, c.min_score, c.active, c.starts_at, c.ends_at, c.created_at, c.updated_at`
+
+// campaignRuns is true when the campaign c runs at $2 for the user u.
+const campaignRuns = `c.active
+        AND (c.starts_at IS NULL OR c.starts_at <= $2) AND (c.ends_at IS NULL OR c.ends_at > $2)
+        AND (c.new_user_days = 0 OR u.created_at > $2 - make_interval(days => c.new_user_days))
+        AND (c.min_score = 0 OR u.score >= c.min_score)`
+
+// GetCampaigns returns all campaigns with the number of their redemptions ordered by id.
+func (u *PostgresRepository) GetCampaigns() ([]*calltypes.Campaign, error) {
+	query := `select ` + campaignColumns + `,
+              (select count(*) from campaign_redemptions r where r.campaign_id = c.id)
+              from campaigns c
+              order by c.id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), consts.DbTimeout)
+	defer cancel()
+
+	rows, err := u.Conn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch campaigns: %w", err)
+	}
+	defer rows.Close()
+
+	campaigns := []*calltypes.Campaign{}
+
+	for rows.Next() {
+		var campaign *calltypes.Campaign
+
+		campaign, err = scanCampaign(rows)
+		if err != nil {
+			log.Printf("Error scanning campaign: %v", err)
+
+			return nil, err
+		}
+
+		campaigns = append(campaigns, campaign)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch campaigns: %w", err)
+	}
+
+	return campaigns, nil
+}
+
+// CreateCampaign adds new campaign.
+func (u *PostgresRepository) CreateCampaign(campaign calltypes.Campaign) (int, error) {
+	stmt := `insert into campaigns (name, kind, multiplier_percent, promo_code, reward, max_redemptions, new_user_days,
+             min_score, active, starts_at, ends_at, created_at, updated_at)
+             values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $12) returning id`
+
+	var newID int
+
+	err := u.queryRow(context.Background(), stmt,
+		campaign.Name,
+		campaign.Kind,
+		campaign.MultiplierPercent,
+		nullablePromoCode(campaign.PromoCode),
+		campaign.Reward,
+		campaign.MaxRedemptions,
+		campaign.NewUserDays,
+		campaign.MinScore,
+		campaign.Active,
+		campaign.StartsAt,
+		campaign.EndsAt,
+		time.Now(),
+	).Scan(&newID)
+	if isUniqueViolation(err) {
+		return 0, errormsg.ErrPromoCodeTaken
+	}
+
+	if err != nil {
+		log.Println("failed to insert new campaign: ", err)
+
+		return 0, fmt.Errorf("failed to insert new campaign: %w", err)
+	}
+
+	return newID, nil
+}
+
+// UpdateCampaign updates the campaign with the same id.
+func (u *PostgresRepository) UpdateCampaign(campaign calltypes.Campaign) error {
+	stmt := `update campaigns set name = $1, kind = $2, multiplier_percent = $3, promo_code = $4, reward = $5,
+             max_redemptions = $6, new_user_days = $7, min_score = $8, active = $9, starts_at = $10, ends_at = $11,
+             updated_at = $12
+             where id = $13`
+
+	result, err := u.execQuery(context.Background(), stmt,
+		campaign.Name,
+		campaign.Kind,
+		campaign.MultiplierPercent,
+		nullablePromoCode(campaign.PromoCode),
+		campaign.Reward,
+		campaign.MaxRedemptions,
+		campaign.NewUserDays,
+		campaign.MinScore,
+		campaign.Active,
+		campaign.StartsAt,
+		campaign.EndsAt,
+		time.Now(),
+		campaign.ID,
+	)
+	if isUniqueViolation(err) {
+		return errormsg.ErrPromoCodeTaken
+	}
+
+	if err != nil {
+		log.Println("failed to update campaign: ", err)
+
+		return fmt.Errorf("failed to update campaign: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update campaign: %w", err)
+	}
+
+	if affected == 0 {
+		return errormsg.ErrCampaignNotFound
+	}
+
+	return nil
+}
+
+// DeleteCampaign deletes the campaign with provided id along with its redemptions.
+// The ledger entries it produced are kept.
+func (u *PostgresRepository) DeleteCampaign(id int) error {
+	result, err := u.execQuery(context.Background(), `delete from campaigns where id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete campaign: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete campaign: %w", err)
+	}
+
+	if affected == 0 {
+		return errormsg.ErrCampaignNotFound
+	}
+
+	return nil
+}
+
+// RedeemPromoCode redeems the promo campaign with provided code for entry.UserID and rewards them with entry,
+// the reward and the reference of the entry are taken from the campaign. Every user can redeem a code once.
+// Wrong codes are throttled together with wrong secret codes, see RedeemSecretCode.
+func (u *PostgresRepository) RedeemPromoCode(code string, entry calltypes.PointTransaction,
+) (*calltypes.Campaign, error) {
+	var (
+		campaign calltypes.Campaign
+		wrong    bool
+	)
+
+	err := u.withTx(context.Background(), nil, func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `SELECT 1 FROM users WHERE id = $1 FOR UPDATE`, entry.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to lock user: %w", err)
+		}
+
+		now := time.Now()
+
+		err = checkCodeAttempts(ctx, tx, entry.UserID, now)
+		if err != nil {
+			return err
+		}
+
+		var runs bool
+
+		err = tx.QueryRowContext(ctx, `SELECT c.id, c.name, c.reward, c.max_redemptions, `+campaignRuns+`
+             FROM campaigns c JOIN users u ON u.id = $1
+             WHERE c.kind = $3 AND c.promo_code = $4
+             FOR UPDATE OF c`, entry.UserID, now, consts.CampaignKindPromo, code).
+			Scan(&campaign.ID, &campaign.Name, &campaign.Reward, &campaign.MaxRedemptions, &runs)
+		if errors.Is(err, sql.ErrNoRows) {
+			// The attempt must be committed, the error is returned after the transaction.
+			wrong = true
+
+			return recordCodeAttempt(ctx, tx, entry.UserID, now)
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to fetch promo campaign: %w", err)
+		}
+
+		if !runs {
+			return errormsg.ErrPromoCodeNotAvailable
+		}
+
+		var redeemed bool
+
+		err = tx.QueryRowContext(ctx, `SELECT count(*), coalesce(bool_or(user_id = $2), false)
+             FROM campaign_redemptions WHERE campaign_id = $1`, campaign.ID, entry.UserID).
+			Scan(&campaign.Redemptions, &redeemed)
+		if err != nil {
+			return fmt.Errorf("failed to count promo code redemptions: %w", err)
+		}
+
+		if redeemed {
+			return errormsg.ErrPromoCodeAlreadyRedeemed
+		}
+
+		if campaign.MaxRedemptions > 0 && campaign.Redemptions >= campaign.MaxRedemptions {
+			return errormsg.ErrPromoCodeExhausted
+		}
+
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO campaign_redemptions (campaign_id, user_id, redeemed_at) VALUES ($1, $2, $3)`,
+			campaign.ID, entry.UserID, now)
+		if err != nil {
+			return fmt.Errorf("failed to record promo code redemption: %w", err)
+		}
+
+		campaign.Redemptions++
+		entry.Delta = campaign.Reward
+		entry.ReferenceID = strconv.Itoa(campaign.ID)
+
+		err = u.applyLedgerEntry(ctx, tx, entry)
+		if err != nil {
+			return err
+		}
+
+		return u.awardBadges(ctx, tx, entry.UserID)
+	})
+	if isUniqueViolation(err) {
+		return nil, errormsg.ErrPromoCodeAlreadyRedeemed
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if wrong {
+		return nil, errormsg.ErrPromoCodeNotFound
+	}
+
+	return &campaign, nil
+}
+
+// campaignBonus returns the ledger entry of the bonus the running multiplier campaigns add to entry, or nil when
+// there is none. Campaigns don't stack, the one with the highest multiplier wins. The audience is checked
+// against the user's score before the entry is applied.
+func campaignBonus(ctx context.Context, tx *sql.Tx, entry calltypes.PointTransaction,
+) (*calltypes.PointTransaction, error) {
+	if entry.Delta <= 0 {
+		return nil, nil //nolint: nilnil
+	}
+
+	var campaignID, multiplierPercent int
+
+	err := tx.QueryRowContext(ctx, `SELECT c.id, c.multiplier_percent
+             FROM campaigns c JOIN users u ON u.id = $1
+             WHERE c.kind = $3 AND `+campaignRuns+`
+             ORDER BY c.multiplier_percent DESC, c.id
+             LIMIT 1`, entry.UserID, time.Now(), consts.CampaignKindMultiplier).
+		Scan(&campaignID, &multiplierPercent)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil //nolint: nilnil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find running campaigns: %w", err)
+	}
+
+	bonus := entry.Delta * (multiplierPercent - 100) / 100 //nolint: mnd
+	if bonus == 0 {
+		return nil, nil //nolint: nilnil
+	}
+
+	return &calltypes.PointTransaction{
+		UserID:      entry.UserID,
+		Delta:       bonus,
+		Reason:      consts.ReasonCampaignBonus,
+		ReferenceID: strconv.Itoa(campaignID),
+		ActorID:     entry.ActorID,
+	}, nil
+}
+
+func scanCampaign(row rowScanner) (*calltypes.Campaign, error) {
+	var (
+		campaign  calltypes.Campaign
+		promoCode sql.NullString
+		startsAt  sql.NullTime
+		endsAt    sql.NullTime
+	)
+
+	err := row.Scan(
+		&campaign.ID,
+		&campaign.Name,
+		&campaign.Kind,
+		&campaign.MultiplierPercent,
+		&promoCode,
+		&campaign.Reward,
+		&campaign.MaxRedemptions,
+		&campaign.NewUserDays,
+		&campaign.MinScore,
+		&campaign.Active,
+		&startsAt,
+		&endsAt,
+		&campaign.CreatedAt,
+		&campaign.UpdatedAt,
+		&campaign.Redemptions,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan campaign: %w", err)
+	}
+
+	campaign.PromoCode = promoCode.String
+
+	if startsAt.Valid {
+		campaign.StartsAt = &startsAt.Time
+	}
+
+	if endsAt.Valid {
+		campaign.EndsAt = &endsAt.Time
+	}
+
+	return &campaign, nil
+}
+
+// nullablePromoCode stores the missing code of a multiplier campaign as NULL.
+func nullablePromoCode(code string) sql.NullString {
+	return sql.NullString{String: code, Valid: code != ""}
+}
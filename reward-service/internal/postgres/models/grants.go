@@ -0,0 +1,202 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"reward-service/api/calltypes"
+	"reward-service/pkg/consts"
+	"time"
+)
+
+type pointGrant struct {
+	id        int
+	remaining int
+}
+
+// GetExpiringPoints returns how many points of the user expire before the deadline.
+func (u *PostgresRepository) GetExpiringPoints(userID int, before time.Time) (int, error) {
+	query := `select coalesce(sum(remaining), 0)
+              from point_grants
+              where user_id = $1 and remaining > 0 and expires_at <= $2`
+
+	var points int
+
+	err := u.queryRow(context.Background(), query, userID, before).Scan(&points)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch expiring points: %w", err)
+	}
+
+	return points, nil
+}
+
+// ExpirePoints writes expiry debits for the grants that expired by now and returns the number of expired points.
+// Every user is handled in their own transaction, so the job holds one balance lock at a time.
+func (u *PostgresRepository) ExpirePoints(now time.Time) (int, error) {
+	userIDs, err := u.usersWithExpiredGrants(now)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int
+
+	for _, userID := range userIDs {
+		var expired int
+
+		err := u.withTx(context.Background(), nil, func(ctx context.Context, tx *sql.Tx) error {
+			var err error
+
+			expired, err = expireUserGrants(ctx, tx, userID, now)
+
+			return err
+		})
+		if err != nil {
+			return total, fmt.Errorf("failed to expire points of user %d: %w", userID, err)
+		}
+
+		total += expired
+	}
+
+	return total, nil
+}
+
+func (u *PostgresRepository) usersWithExpiredGrants(now time.Time) ([]int, error) {
+	query := `select distinct user_id from point_grants where remaining > 0 and expires_at <= $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), consts.DbTimeout)
+	defer cancel()
+
+	rows, err := u.Conn.QueryContext(ctx, query, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch expired grants: %w", err)
+	}
+	defer rows.Close()
+
+	var userIDs []int
+
+	for rows.Next() {
+		var userID int
+
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan expired grant: %w", err)
+		}
+
+		userIDs = append(userIDs, userID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch expired grants: %w", err)
+	}
+
+	return userIDs, nil
+}
+
+// expireUserGrants closes the expired grants of the user and debits what was left of them. The debit never
+// takes the balance below zero: points spent beyond the grants, e.g. by an adjustment, are already gone.
+func expireUserGrants(ctx context.Context, tx *sql.Tx, userID int, now time.Time) (int, error) {
+	var balance int
+
+	err := tx.QueryRowContext(ctx, `SELECT score FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&balance)
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock user's balance: %w", err)
+	}
+
+	var expired int
+
+	err = tx.QueryRowContext(ctx, `WITH expired AS (
+                 SELECT id, remaining FROM point_grants
+                 WHERE user_id = $1 AND remaining > 0 AND expires_at <= $2
+                 FOR UPDATE),
+             closed AS (
+                 UPDATE point_grants g SET remaining = 0 FROM expired e WHERE g.id = e.id)
+             SELECT COALESCE(SUM(remaining), 0) FROM expired`, userID, now).Scan(&expired)
+	if err != nil {
+		return 0, fmt.Errorf("failed to close expired grants: %w", err)
+	}
+
+	expired = min(expired, max(balance, 0))
+	if expired == 0 {
+		return 0, nil
+	}
+
+	_, _, err = writeLedgerEntry(ctx, tx, calltypes.PointTransaction{
+		UserID: userID,
+		Delta:  -expired,
+		Reason: consts.ReasonPointsExpired,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	log.Printf("Expired %d points of user %d", expired, userID)
+
+	return expired, nil
+}
+
+func openGrant(ctx context.Context, tx *sql.Tx, userID, transactionID, amount int, now time.Time) error {
+	_, err := tx.ExecContext(ctx, `INSERT INTO point_grants (user_id, transaction_id, amount, remaining, expires_at,
+             created_at)
+             VALUES ($1, $2, $3, $3, $4, $5)`,
+		userID, transactionID, amount, now.AddDate(0, consts.PointsLifetimeMonths, 0), now)
+	if err != nil {
+		return fmt.Errorf("failed to open point grant: %w", err)
+	}
+
+	return nil
+}
+
+// consumeGrants takes the amount from the grants of the user still open at now, the ones that expire first
+// go first. A debit larger than the open grants consumes all of them. Grants expired by now are left to
+// the expiry job, which debits what remains of them.
+func consumeGrants(ctx context.Context, tx *sql.Tx, userID, amount int, now time.Time) error {
+	if amount <= 0 {
+		return nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT id, remaining FROM point_grants
+             WHERE user_id = $1 AND remaining > 0 AND expires_at > $2
+             ORDER BY expires_at, id
+             FOR UPDATE`, userID, now)
+	if err != nil {
+		return fmt.Errorf("failed to fetch point grants: %w", err)
+	}
+
+	var grants []pointGrant
+
+	for rows.Next() {
+		var grant pointGrant
+
+		if err := rows.Scan(&grant.id, &grant.remaining); err != nil {
+			rows.Close()
+
+			return fmt.Errorf("failed to scan point grant: %w", err)
+		}
+
+		grants = append(grants, grant)
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to fetch point grants: %w", err)
+	}
+
+	for _, grant := range grants {
+		if amount == 0 {
+			break
+		}
+
+		taken := min(amount, grant.remaining)
+
+		_, err = tx.ExecContext(ctx, `UPDATE point_grants SET remaining = remaining - $1 WHERE id = $2`,
+			taken, grant.id)
+		if err != nil {
+			return fmt.Errorf("failed to consume point grant: %w", err)
+		}
+
+		amount -= taken
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reward-service/api/calltypes"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"time"
+)
+
+// BeginIdempotentRequest reserves the idempotency key of the user. A key older than consts.IdempotencyKeyTTL
+// is forgotten, the rest of the expired keys are purged by PurgeIdempotencyKeys. It returns the stored response
+// when the key was already used for the request with the same fingerprint.
+func (u *PostgresRepository) BeginIdempotentRequest(userID int, key, fingerprint string,
+) (*calltypes.IdempotentResponse, error) {
+	var stored *calltypes.IdempotentResponse
+
+	err := u.withTx(context.Background(), nil, func(ctx context.Context, tx *sql.Tx) error {
+		now := time.Now()
+
+		_, err := tx.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE user_id = $1 AND key = $2 AND created_at < $3`,
+			userID, key, now.Add(-consts.IdempotencyKeyTTL))
+		if err != nil {
+			return fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+		}
+
+		result, err := tx.ExecContext(ctx, `INSERT INTO idempotency_keys (user_id, key, fingerprint, created_at)
+             VALUES ($1, $2, $3, $4) ON CONFLICT (user_id, key) DO NOTHING`,
+			userID, key, fingerprint, now)
+		if err != nil {
+			return fmt.Errorf("failed to reserve idempotency key: %w", err)
+		}
+
+		inserted, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to reserve idempotency key: %w", err)
+		}
+
+		if inserted == 1 {
+			return nil
+		}
+
+		var (
+			storedFingerprint string
+			statusCode        sql.NullInt64
+			header            []byte
+			response          calltypes.IdempotentResponse
+		)
+
+		err = tx.QueryRowContext(ctx, `SELECT fingerprint, status_code, headers, coalesce(response_body, '')
+             FROM idempotency_keys WHERE user_id = $1 AND key = $2`, userID, key).
+			Scan(&storedFingerprint, &statusCode, &header, &response.Body)
+		if err != nil {
+			return fmt.Errorf("failed to fetch idempotency key: %w", err)
+		}
+
+		if storedFingerprint != fingerprint {
+			return errormsg.ErrIdempotencyKeyReused
+		}
+
+		if !statusCode.Valid {
+			return errormsg.ErrIdempotencyKeyInProgress
+		}
+
+		err = json.Unmarshal(header, &response.Header)
+		if err != nil {
+			return fmt.Errorf("failed to decode idempotent response headers: %w", err)
+		}
+
+		response.StatusCode = int(statusCode.Int64)
+		stored = &response
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stored, nil
+}
+
+// CompleteIdempotentRequest stores the response of the request made with the key.
+func (u *PostgresRepository) CompleteIdempotentRequest(userID int, key string,
+	response calltypes.IdempotentResponse,
+) error {
+	header, err := json.Marshal(response.Header)
+	if err != nil {
+		return fmt.Errorf("failed to encode idempotent response headers: %w", err)
+	}
+
+	_, err = u.execQuery(context.Background(), `UPDATE idempotency_keys
+             SET status_code = $1, headers = $2, response_body = $3, completed_at = $4
+             WHERE user_id = $5 AND key = $6`,
+		response.StatusCode, header, response.Body, time.Now(), userID, key)
+	if err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+
+	return nil
+}
+
+// ReleaseIdempotentRequest forgets the key so that the request can be retried with it.
+func (u *PostgresRepository) ReleaseIdempotentRequest(userID int, key string) error {
+	_, err := u.execQuery(context.Background(),
+		`DELETE FROM idempotency_keys WHERE user_id = $1 AND key = $2`, userID, key)
+	if err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// PurgeIdempotencyKeys forgets the keys of all users created before the time and returns their number.
+func (u *PostgresRepository) PurgeIdempotencyKeys(before time.Time) (int, error) {
+	result, err := u.execQuery(context.Background(), `delete from idempotency_keys where created_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge idempotency keys: %w", err)
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge idempotency keys: %w", err)
+	}
+
+	return int(purged), nil
+}
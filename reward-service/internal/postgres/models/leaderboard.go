@@ -0,0 +1,261 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"reward-service/api/calltypes"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"slices"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// leaderboardQuery builds a query over the ranked users, it numbers the parameters in the order they are added.
+// The conditions on the order bound the score first, so the balance ranking scans a range of idx_users_active_score.
+type leaderboardQuery struct {
+	from string
+	args []any
+}
+
+// newLeaderboardQuery selects the active users with their balance, or with the points they earned since
+// the time when it is set and before until when that is set too. Refunds return spent points and are not earnings.
+func newLeaderboardQuery(since, until time.Time) *leaderboardQuery {
+	if since.IsZero() {
+		return &leaderboardQuery{
+			from: `select id, coalesce(first_name, '') as first_name, coalesce(last_name, '') as last_name, score
+              from users where active = 1`,
+		}
+	}
+
+	q := &leaderboardQuery{}
+
+	window := `created_at >= ` + q.arg(since)
+	if !until.IsZero() {
+		window += ` and created_at < ` + q.arg(until)
+	}
+
+	q.from = `select u.id, coalesce(u.first_name, '') as first_name, coalesce(u.last_name, '') as last_name, e.score
+              from users u
+              join (select user_id, sum(delta) as score from point_transactions
+                    where delta > 0 and reason <> 'redemption_refund' and ` + window + `
+                    group by user_id) e on e.user_id = u.id
+              where u.active = 1`
+
+	return q
+}
+
+func (q *leaderboardQuery) arg(value any) string {
+	q.args = append(q.args, value)
+
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+// page selects up to limit entries in the leaderboard order after the cursor, the first ones when it is nil.
+func (q *leaderboardQuery) page(after *calltypes.LeaderboardCursor, limit int) string {
+	query := `select id, first_name, last_name, score from (` + q.from + `) b`
+
+	if after != nil {
+		score, userID := q.arg(after.Score), q.arg(after.UserID)
+		query += ` where score <= ` + score + ` and (score < ` + score + ` or id > ` + userID + `)`
+	}
+
+	return query + ` order by score desc, id limit ` + q.arg(limit)
+}
+
+// GetLeaderboard returns up to limit leaderboard entries after the cursor, the first ones when it is nil.
+// Users with equal scores share the rank: the rank of the first of them (1, 2, 2, 4) with competition ranking,
+// the next one (1, 2, 2, 3) with dense ranking.
+func (u *PostgresRepository) GetLeaderboard(since time.Time, after *calltypes.LeaderboardCursor, limit int,
+	ranking string,
+) ([]*calltypes.LeaderboardEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), consts.DbTimeout)
+	defer cancel()
+
+	return u.leaderboardPage(ctx, since, time.Time{}, after, limit, ranking)
+}
+
+// leaderboardPage returns ranked leaderboard entries of the users selected by newLeaderboardQuery.
+func (u *PostgresRepository) leaderboardPage(ctx context.Context, since, until time.Time,
+	after *calltypes.LeaderboardCursor, limit int, ranking string,
+) ([]*calltypes.LeaderboardEntry, error) {
+	q := newLeaderboardQuery(since, until)
+
+	entries, err := u.queryLeaderboard(ctx, q, q.page(after, limit))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entries) == 0 {
+		return entries, nil
+	}
+
+	_, err = u.rankLeaderboard(ctx, since, until, entries, ranking)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// GetRank returns the user's place on the leaderboard with up to neighbours entries above and below it.
+// Only active users are ranked, for a period only the ones who earned points in it.
+func (u *PostgresRepository) GetRank(userID int, since time.Time, neighbours int, ranking string,
+) (*calltypes.UserRank, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), consts.DbTimeout)
+	defer cancel()
+
+	q := newLeaderboardQuery(since, time.Time{})
+
+	own, err := u.queryLeaderboard(ctx, q, `select id, first_name, last_name, score from (`+q.from+`) b
+              where id = `+q.arg(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(own) == 0 {
+		return nil, errormsg.ErrUserNotRanked
+	}
+
+	user := own[0]
+
+	q = newLeaderboardQuery(since, time.Time{})
+	score, id := q.arg(user.Score), q.arg(user.UserID)
+
+	above, err := u.queryLeaderboard(ctx, q, `select id, first_name, last_name, score from (`+q.from+`) b
+              where score >= `+score+` and (score > `+score+` or id < `+id+`)
+              order by score, id desc
+              limit `+q.arg(neighbours))
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Reverse(above)
+
+	q = newLeaderboardQuery(since, time.Time{})
+
+	below, err := u.queryLeaderboard(ctx, q,
+		q.page(&calltypes.LeaderboardCursor{Score: user.Score, UserID: user.UserID}, neighbours))
+	if err != nil {
+		return nil, err
+	}
+
+	window := slices.Concat(above, []*calltypes.LeaderboardEntry{user}, below)
+
+	ahead, err := u.rankLeaderboard(ctx, since, time.Time{}, window, ranking)
+	if err != nil {
+		return nil, err
+	}
+
+	rank := calltypes.UserRank{
+		Rank:     user.Rank,
+		Position: ahead + len(above) + 1,
+		Score:    user.Score,
+		Above:    above,
+		Below:    below,
+	}
+
+	q = newLeaderboardQuery(since, time.Time{})
+	query := `select count(*), count(*) filter (where score < ` + q.arg(user.Score) + `) from (` + q.from + `) b`
+
+	var lower int
+
+	err = u.Conn.QueryRowContext(ctx, query, q.args...).Scan(&rank.Total, &lower)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count ranked users: %w", err)
+	}
+
+	rank.Percentile = math.Round(float64(lower)*1000/float64(rank.Total)) / 10 //nolint: mnd
+
+	return &rank, nil
+}
+
+// queryLeaderboard runs the query with the arguments q collected while the query was built.
+func (u *PostgresRepository) queryLeaderboard(ctx context.Context, q *leaderboardQuery, query string,
+) ([]*calltypes.LeaderboardEntry, error) {
+	rows, err := u.Conn.QueryContext(ctx, query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch leaderboard: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*calltypes.LeaderboardEntry{}
+
+	for rows.Next() {
+		var (
+			entry               calltypes.LeaderboardEntry
+			firstName, lastName string
+		)
+
+		err := rows.Scan(&entry.UserID, &firstName, &lastName, &entry.Score)
+		if err != nil {
+			log.Printf("Error scanning leaderboard entry: %v", err)
+
+			return nil, fmt.Errorf("failed to scan leaderboard entry: %w", err)
+		}
+
+		entry.DisplayName = displayName(entry.UserID, firstName, lastName)
+		entries = append(entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch leaderboard: %w", err)
+	}
+
+	return entries, nil
+}
+
+// rankLeaderboard sets the ranks of consecutive entries and returns the number of users ahead of the first one.
+// Only the users ahead of the first entry are counted, the rest of the ranks follow from the entries themselves.
+func (u *PostgresRepository) rankLeaderboard(ctx context.Context, since, until time.Time,
+	entries []*calltypes.LeaderboardEntry, ranking string,
+) (int, error) {
+	q := newLeaderboardQuery(since, until)
+	score, userID := q.arg(entries[0].Score), q.arg(entries[0].UserID)
+
+	query := `select count(*) filter (where score > ` + score + `),
+              count(distinct score) filter (where score > ` + score + `), count(*)
+              from (` + q.from + `) b
+              where score >= ` + score + ` and (score > ` + score + ` or id < ` + userID + `)`
+
+	var higher, higherScores, ahead int
+
+	err := u.Conn.QueryRowContext(ctx, query, q.args...).Scan(&higher, &higherScores, &ahead)
+	if err != nil {
+		return 0, fmt.Errorf("failed to rank leaderboard: %w", err)
+	}
+
+	entries[0].Rank = higher + 1
+	if ranking == consts.RankingDense {
+		entries[0].Rank = higherScores + 1
+	}
+
+	for i := 1; i < len(entries); i++ {
+		switch {
+		case entries[i].Score == entries[i-1].Score:
+			entries[i].Rank = entries[i-1].Rank
+		case ranking == consts.RankingDense:
+			entries[i].Rank = entries[i-1].Rank + 1
+		default:
+			entries[i].Rank = ahead + i + 1
+		}
+	}
+
+	return ahead, nil
+}
+
+// displayName is the name other users see: the first name with the initial of the last one.
+func displayName(userID int, firstName, lastName string) string {
+	if firstName == "" {
+		return "User " + strconv.Itoa(userID)
+	}
+
+	if initial, _ := utf8.DecodeRuneInString(lastName); lastName != "" {
+		return firstName + " " + string(initial) + "."
+	}
+
+	return firstName
+}
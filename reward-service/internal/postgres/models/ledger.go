@@ -0,0 +1,124 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"reward-service/api/calltypes"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"time"
+)
+
+// GetTransactions returns user's ledger entries, newest first.
+// Only entries older than the cursor (an entry ID) are returned when the cursor is set.
+func (u *PostgresRepository) GetTransactions(userID, cursor, limit int) ([]*calltypes.PointTransaction, error) {
+	query := `select id, user_id, delta, reason, reference_id, coalesce(actor_id, 0), created_at
+              from point_transactions
+              where user_id = $1 and ($2 = 0 or id < $2)
+              order by id desc
+              limit $3`
+
+	ctx, cancel := context.WithTimeout(context.Background(), consts.DbTimeout)
+	defer cancel()
+
+	rows, err := u.Conn.QueryContext(ctx, query, userID, cursor, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch transactions: %w", err)
+	}
+	defer rows.Close()
+
+	transactions := []*calltypes.PointTransaction{}
+
+	for rows.Next() {
+		var transaction calltypes.PointTransaction
+
+		err := rows.Scan(
+			&transaction.ID,
+			&transaction.UserID,
+			&transaction.Delta,
+			&transaction.Reason,
+			&transaction.ReferenceID,
+			&transaction.ActorID,
+			&transaction.CreatedAt,
+		)
+		if err != nil {
+			log.Printf("Error scanning transaction: %v", err)
+
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+
+		transactions = append(transactions, &transaction)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("Error after row iteration: %v", err)
+
+		return nil, fmt.Errorf("failed to fetch transactions: %w", err)
+	}
+
+	return transactions, nil
+}
+
+// applyLedgerEntry writes one ledger entry and updates the cached balance in users.score. A credit opens
+// a point grant that expires consts.PointsLifetimeMonths later and counts towards the user's tier,
+// a debit consumes the oldest grants first.
+// It must be called inside a transaction, the user's row stays locked until the transaction ends.
+func (u *PostgresRepository) applyLedgerEntry(ctx context.Context, tx *sql.Tx, entry calltypes.PointTransaction) error {
+	transactionID, now, err := writeLedgerEntry(ctx, tx, entry)
+	if err != nil {
+		return err
+	}
+
+	if entry.Delta > 0 {
+		err = openGrant(ctx, tx, entry.UserID, transactionID, entry.Delta, now)
+		if err != nil {
+			return err
+		}
+
+		return raiseLifetimePoints(ctx, tx, entry, transactionID, now)
+	}
+
+	return consumeGrants(ctx, tx, entry.UserID, -entry.Delta, now)
+}
+
+// writeLedgerEntry locks the user's row, writes the ledger entry and updates users.score without touching
+// the grants. It returns the id and the time of the entry.
+func writeLedgerEntry(ctx context.Context, tx *sql.Tx, entry calltypes.PointTransaction) (int, time.Time, error) {
+	var balance int
+
+	err := tx.QueryRowContext(ctx, `SELECT score FROM users WHERE id = $1 FOR UPDATE`, entry.UserID).Scan(&balance)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, time.Time{}, errormsg.ErrUserNotFound
+	}
+
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to lock user's balance: %w", err)
+	}
+
+	var actorID sql.NullInt64
+	if entry.ActorID != 0 {
+		actorID = sql.NullInt64{Int64: int64(entry.ActorID), Valid: true}
+	}
+
+	var transactionID int
+
+	now := time.Now()
+
+	err = tx.QueryRowContext(ctx, `INSERT INTO point_transactions (user_id, delta, reason, reference_id, actor_id, created_at)
+             VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+		entry.UserID, entry.Delta, entry.Reason, entry.ReferenceID, actorID, now).Scan(&transactionID)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to write ledger entry: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE users SET score = score + $1, updated_at = $2 WHERE id = $3`,
+		entry.Delta, now, entry.UserID)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to update user's balance: %w", err)
+	}
+
+	return transactionID, now, nil
+}
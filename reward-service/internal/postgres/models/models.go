@@ -7,13 +7,19 @@ import (
 	"fmt"
 	"log"
 	"reward-service/api/calltypes"
+	"reward-service/internal/referral"
 	"reward-service/pkg/consts"
 	"time"
 
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
 	"golang.org/x/crypto/bcrypt"
 	"reward-service/pkg/errormsg"
 )
 
+// usersReferrerConstraint is the unique constraint on users' referral codes.
+const usersReferrerConstraint = "users_referrer_key"
+
 type PostgresRepository struct {
 	Conn *sql.DB
 }
@@ -38,24 +44,25 @@ func (u *PostgresRepository) UserExists(id int) (bool, error) {
 	return exists, nil
 }
 
-// AddPoints adds some points.
-func (u *PostgresRepository) AddPoints(id, point int) error {
-	idExists, err := u.UserExists(id)
-	if err != nil {
-		return err
-	}
+// AddPoints writes the entry to the points ledger and updates user's balance in one transaction,
+// the badges the user earns with it are awarded in the same transaction.
+func (u *PostgresRepository) AddPoints(entry calltypes.PointTransaction) error {
+	err := u.withTx(context.Background(), nil, func(ctx context.Context, tx *sql.Tx) error {
+		err := u.applyLedgerEntry(ctx, tx, entry)
+		if err != nil {
+			return err
+		}
 
-	if !idExists {
+		return u.awardBadges(ctx, tx, entry.UserID)
+	})
+	if errors.Is(err, errormsg.ErrUserNotFound) {
 		log.Println("User does not exist")
 
 		return errormsg.ErrUserNotFound
 	}
 
-	stmt := `update users set score = score + $1, updated_at = $2 where id = $3`
-
-	_, err = u.execQuery(context.Background(), stmt, point, time.Now(), id)
 	if err != nil {
-		log.Printf("Error adding points to user %d: %v", id, err)
+		log.Printf("Error adding points to user %d: %v", entry.UserID, err)
 
 		return errormsg.ErrAddPointsFailed
 	}
@@ -65,7 +72,7 @@ func (u *PostgresRepository) AddPoints(id, point int) error {
 
 // GetAll returns a slice of all users, sorted by last name.
 func (u *PostgresRepository) GetAll() ([]*calltypes.User, error) {
-	query := `select id, email, first_name, last_name, active, score, created_at, updated_at, referrer
+	query := `select id, email, first_name, last_name, active, score, created_at, updated_at, referrer, role
               from users order by score desc`
 
 	rows, err := u.Conn.QueryContext(context.Background(), query)
@@ -88,6 +95,7 @@ func (u *PostgresRepository) GetAll() ([]*calltypes.User, error) {
 			&user.CreatedAt,
 			&user.UpdatedAt,
 			&user.Referrer,
+			&user.Role,
 		)
 
 		if err != nil {
@@ -145,7 +153,7 @@ func (u *PostgresRepository) EmailCheck(email string) (*calltypes.User, error) {
 
 // GetByEmail returns info of one user by email.
 func (u *PostgresRepository) GetByEmail(email string) (*calltypes.User, error) {
-	query := `select id, email, first_name, last_name, password, active, score, created_at, updated_at 
+	query := `select id, email, first_name, last_name, password, active, score, created_at, updated_at, role
               from users where email = $1`
 
 	var user calltypes.User
@@ -159,6 +167,7 @@ func (u *PostgresRepository) GetByEmail(email string) (*calltypes.User, error) {
 		&user.Score,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.Role,
 	)
 
 	if err != nil {
@@ -170,53 +179,6 @@ func (u *PostgresRepository) GetByEmail(email string) (*calltypes.User, error) {
 	return &user, nil
 }
 
-// RedeemReferrer redeems the referrer with provided id and referrer, adds points to both users.
-func (u *PostgresRepository) RedeemReferrer(id int, referrer string) error {
-	var referrerExists, idExists bool
-
-	var sameCheck string
-
-	err := u.queryRow(context.Background(),
-		"SELECT EXISTS(SELECT 1 FROM users WHERE referrer = $1)", referrer).Scan(&referrerExists)
-	if err != nil {
-		return fmt.Errorf("err occurred during executing query row: %w", err)
-	}
-
-	if !referrerExists {
-		return fmt.Errorf("provided referrer does not exist: %w", err)
-	}
-
-	idExists, err = u.UserExists(id)
-	if err != nil {
-		return fmt.Errorf("error checking user existence: %w", err)
-	}
-
-	if !idExists {
-		return fmt.Errorf("user does not exist: %w", err)
-	}
-
-	err = u.queryRow(context.Background(), "SELECT referrer FROM users WHERE id = $1", id).Scan(&sameCheck)
-	if err != nil {
-		return fmt.Errorf("error finding the referrer: %w", err)
-	}
-
-	if sameCheck == referrer {
-		return fmt.Errorf("user cannot redeem for their own referrer: %w", err)
-	}
-
-	_, err = u.execQuery(context.Background(), "UPDATE users SET score = score + 100 WHERE referrer = $1", referrer)
-	if err != nil {
-		return fmt.Errorf("failed to update referrer's score: %w", err)
-	}
-
-	_, err = u.execQuery(context.Background(), "UPDATE users SET score = score + 25 WHERE id = $1", id)
-	if err != nil {
-		return fmt.Errorf("failed to update score for who redeemed referrer: %w", err)
-	}
-
-	return nil
-}
-
 // GetOne returns one user by id.
 func (u *PostgresRepository) GetOne(id int) (*calltypes.User, error) {
 	idExists, err := u.UserExists(id)
@@ -230,7 +192,7 @@ func (u *PostgresRepository) GetOne(id int) (*calltypes.User, error) {
 		return nil, errormsg.ErrUserNotFound
 	}
 
-	query := `select id, email, first_name, last_name, active, score, created_at, updated_at, referrer 
+	query := `select id, email, first_name, last_name, active, score, created_at, updated_at, referrer, role
               from users where id = $1`
 
 	var user calltypes.User
@@ -244,6 +206,7 @@ func (u *PostgresRepository) GetOne(id int) (*calltypes.User, error) {
 		&user.CreatedAt,
 		&user.UpdatedAt,
 		&user.Referrer,
+		&user.Role,
 	)
 
 	if err != nil {
@@ -293,29 +256,41 @@ func (u *PostgresRepository) Update(user calltypes.User) error {
 	return nil
 }
 
-// UpdateScore provides whole new score to the user.
+// UpdateScore provides whole new score to the user. The difference is written to the ledger as an adjustment.
 func (u *PostgresRepository) UpdateScore(user calltypes.User) error {
-	idExists, err := u.UserExists(user.ID)
-	if err != nil {
-		return err
-	}
+	err := u.withTx(context.Background(), nil, func(ctx context.Context, tx *sql.Tx) error {
+		var balance int
 
-	if !idExists {
+		err := tx.QueryRowContext(ctx, `SELECT score FROM users WHERE id = $1 FOR UPDATE`, user.ID).Scan(&balance)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errormsg.ErrUserNotFound
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to lock user's balance: %w", err)
+		}
+
+		if user.Score == balance {
+			return nil
+		}
+
+		err = u.applyLedgerEntry(ctx, tx, calltypes.PointTransaction{
+			UserID: user.ID,
+			Delta:  user.Score - balance,
+			Reason: consts.ReasonAdjustment,
+		})
+		if err != nil {
+			return err
+		}
+
+		return u.awardBadges(ctx, tx, user.ID)
+	})
+	if errors.Is(err, errormsg.ErrUserNotFound) {
 		log.Println("User does not exist")
 
 		return errormsg.ErrUserNotFound
 	}
 
-	stmt := `update users set
-             score = $1,
-             updated_at = $2
-             where id = $3`
-
-	_, err = u.execQuery(context.Background(), stmt,
-		user.Score,
-		time.Now(),
-		user.ID,
-	)
 	if err != nil {
 		log.Println("failed to update user's score: ", err)
 
@@ -325,7 +300,8 @@ func (u *PostgresRepository) UpdateScore(user calltypes.User) error {
 	return nil
 }
 
-// Insert adds new user to the database.
+// Insert adds new user to the database. User.Referrer is used as a vanity referral code when it is set,
+// otherwise a code is generated, a new one is tried when the generated code is already taken.
 func (u *PostgresRepository) Insert(user calltypes.User) (int, error) {
 	if len(user.Password) < consts.PassMinLength {
 		return 0, errormsg.ErrPasswordLength
@@ -336,18 +312,44 @@ func (u *PostgresRepository) Insert(user calltypes.User) (int, error) {
 		return 0, fmt.Errorf("failed to hash password: %w", err)
 	}
 
+	if user.Referrer != "" {
+		newID, err := u.insertUser(user, hashedPassword)
+		if isReferralCodeConflict(err) {
+			return 0, errormsg.ErrReferralCodeTaken
+		}
+
+		return newID, err
+	}
+
+	for range consts.ReferralCodeAttempts {
+		user.Referrer, err = referral.GenerateCode()
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert new user: %w", err)
+		}
+
+		newID, err := u.insertUser(user, hashedPassword)
+		if !isReferralCodeConflict(err) {
+			return newID, err
+		}
+	}
+
+	return 0, errormsg.ErrGenerateReferralCode
+}
+
+// insertUser inserts the user with the already hashed password. New users always start with zero balance,
+// points are only ever added through the ledger.
+func (u *PostgresRepository) insertUser(user calltypes.User, hashedPassword []byte) (int, error) {
 	var newID int
 
 	stmt := `insert into users (email, first_name, last_name, password, active, score, created_at, updated_at, referrer)
-         values ($1, $2, $3, $4, $5, $6, $7, $8, $9) returning id`
+         values ($1, $2, $3, $4, $5, 0, $6, $7, $8) returning id`
 
-	err = u.queryRow(context.Background(), stmt,
+	err := u.queryRow(context.Background(), stmt,
 		user.Email,
 		user.FirstName,
 		user.LastName,
 		hashedPassword,
 		user.Active,
-		user.Score,
 		time.Now(),
 		time.Now(),
 		user.Referrer,
@@ -361,6 +363,60 @@ func (u *PostgresRepository) Insert(user calltypes.User) (int, error) {
 	return newID, nil
 }
 
+// SetRole changes the role of the user.
+func (u *PostgresRepository) SetRole(userID int, role string) error {
+	result, err := u.execQuery(context.Background(),
+		`update users set role = $1, updated_at = $2 where id = $3`, role, time.Now(), userID)
+	if err != nil {
+		log.Println("failed to update user's role: ", err)
+
+		return fmt.Errorf("failed to update user's role: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update user's role: %w", err)
+	}
+
+	if affected == 0 {
+		return errormsg.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// EnsureAdmin makes sure the user with provided email exists and is an admin.
+// Existing users are promoted, their password is left untouched.
+func (u *PostgresRepository) EnsureAdmin(email, password string) (int, error) {
+	var userID int
+
+	err := u.queryRow(context.Background(),
+		`update users set role = $1, updated_at = $2 where email = $3 returning id`,
+		consts.RoleAdmin, time.Now(), email).Scan(&userID)
+	if err == nil {
+		return userID, nil
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("failed to promote admin: %w", err)
+	}
+
+	userID, err = u.Insert(calltypes.User{
+		Email:    email,
+		Password: password,
+		Active:   1,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := u.SetRole(userID, consts.RoleAdmin); err != nil {
+		return 0, err
+	}
+
+	return userID, nil
+}
+
 // PasswordMatches uses Go's bcrypt package to compare a user supplied password
 // with the hash we have stored for a given user in the database. If the password
 // and hash match, we return true; otherwise, we return false.
@@ -378,33 +434,6 @@ func (u *PostgresRepository) PasswordMatches(plainText string, user calltypes.Us
 	return true, nil
 }
 
-// StoreRefreshToken stores provided refresh token.
-func (u *PostgresRepository) StoreRefreshToken(userID int, hashedToken string) error {
-	idExists, err := u.UserExists(userID)
-	if err != nil {
-		return err
-	}
-
-	if !idExists {
-		log.Println("User does not exist")
-
-		return errormsg.ErrUserNotFound
-	}
-
-	stmt := `UPDATE users SET refresh_token = $1, refresh_token_expires = $2 WHERE id = $3`
-
-	_, err = u.execQuery(context.Background(), stmt,
-		hashedToken,
-		time.Now().Add(consts.RefreshTokenExpireTime),
-		userID,
-	)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
 func (u *PostgresRepository) execQuery(ctx context.Context, query string, args ...interface{}) (sql.Result, error) { //nolint: unparam
 	ctx, cancel := context.WithTimeout(ctx, consts.DbTimeout)
 	defer cancel()
@@ -423,3 +452,70 @@ func (u *PostgresRepository) queryRow(ctx context.Context, query string, args ..
 
 	return u.Conn.QueryRowContext(ctx, query, args...)
 }
+
+// withSerializableTx runs fn inside a serializable transaction,
+// retrying it a few times when Postgres aborts it because of a concurrent one.
+func (u *PostgresRepository) withSerializableTx(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	var err error
+
+	for range consts.SerializableTxAttempts {
+		err = u.withTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable}, fn)
+		if !isSerializationFailure(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// isSerializationFailure reports whether err is a serialization failure of a serializable transaction.
+func isSerializationFailure(err error) bool {
+	var pgErr *pgconn.PgError
+
+	return errors.As(err, &pgErr) && pgErr.Code == pgerrcode.SerializationFailure
+}
+
+// isUniqueViolation reports whether err is caused by a unique constraint.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+
+	return errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation
+}
+
+// isReferralCodeConflict reports whether err is caused by a referral code that is already taken.
+func isReferralCodeConflict(err error) bool {
+	var pgErr *pgconn.PgError
+
+	return errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation &&
+		pgErr.ConstraintName == usersReferrerConstraint
+}
+
+// withTx runs fn inside a transaction, committing on success and rolling back on any error.
+func (u *PostgresRepository) withTx(ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	return u.withTxTimeout(ctx, consts.DbTimeout, opts, fn)
+}
+
+// withTxTimeout is withTx for transactions that may run longer than consts.DbTimeout.
+func (u *PostgresRepository) withTxTimeout(ctx context.Context, timeout time.Duration, opts *sql.TxOptions,
+	fn func(ctx context.Context, tx *sql.Tx) error,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	tx, err := u.Conn.BeginTx(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(ctx, tx); err != nil {
+		_ = tx.Rollback()
+
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
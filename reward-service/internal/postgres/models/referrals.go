@@ -0,0 +1,225 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"reward-service/api/calltypes"
+	"reward-service/internal/referral"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"strconv"
+	"time"
+)
+
+// referralTree selects the referrals made below user $1 down to level $2.
+// invitee_id is the direct invitee the referral comes through.
+const referralTree = `WITH RECURSIVE tree AS (
+        SELECT id, referee_id, referee_id AS invitee_id, 1 AS level
+        FROM referrals
+        WHERE referrer_id = $1
+        UNION ALL
+        SELECT r.id, r.referee_id, t.invitee_id, t.level + 1
+        FROM referrals r
+        JOIN tree t ON r.referrer_id = t.referee_id
+        WHERE t.level < $2 AND r.referee_id <> $1
+    )`
+
+// RedeemReferrer redeems the referrer with provided id and referrer. The owner of the code and the ones above
+// them in the referral tree get rewards.Tiers, the user who redeemed the code gets rewards.Referee.
+// Every user can redeem a referrer only once, the whole redemption runs in one serializable transaction
+// together with the badges both users earn.
+func (u *PostgresRepository) RedeemReferrer(id int, referrer string, rewards calltypes.ReferralRewards) error {
+	err := u.withSerializableTx(context.Background(), func(ctx context.Context, tx *sql.Tx) error {
+		var alreadyRedeemed bool
+
+		err := tx.QueryRowContext(ctx,
+			"SELECT EXISTS(SELECT 1 FROM referrals WHERE referee_id = $1)", id).Scan(&alreadyRedeemed)
+		if err != nil {
+			return fmt.Errorf("failed to check previous redemption: %w", err)
+		}
+
+		if alreadyRedeemed {
+			return errormsg.ErrReferrerAlreadyRedeemed
+		}
+
+		code := referral.Normalize(referrer)
+
+		var referrerID int
+
+		err = tx.QueryRowContext(ctx, "SELECT id FROM users WHERE upper(referrer) = $1", code).Scan(&referrerID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errormsg.ErrReferrerNotFound
+		}
+
+		if err != nil {
+			return fmt.Errorf("err occurred during executing query row: %w", err)
+		}
+
+		if referrerID == id {
+			return errormsg.ErrOwnReferrer
+		}
+
+		var referralID int
+
+		err = tx.QueryRowContext(ctx,
+			"INSERT INTO referrals (referrer_id, referee_id, code, created_at) VALUES ($1, $2, $3, $4) RETURNING id",
+			referrerID, id, code, time.Now()).Scan(&referralID)
+		if err != nil {
+			return fmt.Errorf("failed to record referral: %w", err)
+		}
+
+		err = u.payReferralTiers(ctx, tx, referralID, id, referrerID, rewards.Tiers)
+		if err != nil {
+			return err
+		}
+
+		if rewards.Referee != 0 {
+			err = u.applyLedgerEntry(ctx, tx, calltypes.PointTransaction{
+				UserID:      id,
+				Delta:       rewards.Referee,
+				Reason:      consts.ReasonRefereeBonus,
+				ReferenceID: strconv.Itoa(referralID),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to update score for who redeemed referrer: %w", err)
+			}
+		}
+
+		return u.awardBadges(ctx, tx, referrerID, id)
+	})
+	if isUniqueViolation(err) {
+		return errormsg.ErrReferrerAlreadyRedeemed
+	}
+
+	return err
+}
+
+// payReferralTiers walks up the referral tree from the owner of the redeemed code and pays every level its reward.
+// The walk stops at the top of the tree or when it comes back to the user who redeemed the code.
+func (u *PostgresRepository) payReferralTiers(ctx context.Context, tx *sql.Tx, referralID, refereeID, referrerID int,
+	tiers []int,
+) error {
+	beneficiaryID := referrerID
+
+	for level, reward := range tiers {
+		if reward != 0 {
+			err := u.applyLedgerEntry(ctx, tx, calltypes.PointTransaction{
+				UserID:      beneficiaryID,
+				Delta:       reward,
+				Reason:      consts.ReasonReferrerBonus,
+				ReferenceID: strconv.Itoa(referralID),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to update score of level %d referrer: %w", level+1, err)
+			}
+		}
+
+		if level == len(tiers)-1 {
+			return nil
+		}
+
+		err := tx.QueryRowContext(ctx,
+			"SELECT referrer_id FROM referrals WHERE referee_id = $1", beneficiaryID).Scan(&beneficiaryID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to find level %d referrer: %w", level+2, err)
+		}
+
+		if beneficiaryID == refereeID {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// GetReferralStats returns the users invited by the user, the referral tree is counted down to depth levels.
+func (u *PostgresRepository) GetReferralStats(userID, depth int) (*calltypes.ReferralStats, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), consts.DbTimeout)
+	defer cancel()
+
+	stats := calltypes.ReferralStats{
+		Levels:   []calltypes.ReferralLevel{},
+		Invitees: []*calltypes.Invitee{},
+	}
+
+	levelsQuery := referralTree + `
+        SELECT t.level, count(DISTINCT t.id), coalesce(sum(pt.delta), 0)
+        FROM tree t
+        LEFT JOIN point_transactions pt
+            ON pt.user_id = $1 AND pt.reason = $3 AND pt.reference_id = t.id::text
+        GROUP BY t.level
+        ORDER BY t.level`
+
+	rows, err := u.Conn.QueryContext(ctx, levelsQuery, userID, depth, consts.ReasonReferrerBonus)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch referral levels: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var level calltypes.ReferralLevel
+
+		if err := rows.Scan(&level.Level, &level.Count, &level.PointsEarned); err != nil {
+			log.Printf("Error scanning referral level: %v", err)
+
+			return nil, fmt.Errorf("failed to scan referral level: %w", err)
+		}
+
+		stats.PointsEarned += level.PointsEarned
+		stats.Levels = append(stats.Levels, level)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch referral levels: %w", err)
+	}
+
+	inviteesQuery := referralTree + `
+        SELECT u.id, coalesce(u.first_name, ''), coalesce(u.last_name, ''), d.created_at, coalesce(sum(pt.delta), 0)
+        FROM tree t
+        JOIN referrals d ON d.referee_id = t.invitee_id
+        JOIN users u ON u.id = t.invitee_id
+        LEFT JOIN point_transactions pt
+            ON pt.user_id = $1 AND pt.reason = $3 AND pt.reference_id = t.id::text
+        GROUP BY u.id, u.first_name, u.last_name, d.created_at
+        ORDER BY d.created_at DESC, u.id DESC`
+
+	inviteeRows, err := u.Conn.QueryContext(ctx, inviteesQuery, userID, depth, consts.ReasonReferrerBonus)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch invitees: %w", err)
+	}
+	defer inviteeRows.Close()
+
+	for inviteeRows.Next() {
+		var invitee calltypes.Invitee
+
+		err := inviteeRows.Scan(
+			&invitee.UserID,
+			&invitee.FirstName,
+			&invitee.LastName,
+			&invitee.RedeemedAt,
+			&invitee.PointsEarned,
+		)
+		if err != nil {
+			log.Printf("Error scanning invitee: %v", err)
+
+			return nil, fmt.Errorf("failed to scan invitee: %w", err)
+		}
+
+		stats.Invitees = append(stats.Invitees, &invitee)
+	}
+
+	if err := inviteeRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch invitees: %w", err)
+	}
+
+	stats.Invited = len(stats.Invitees)
+
+	return &stats, nil
+}
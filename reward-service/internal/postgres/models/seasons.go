@@ -0,0 +1,403 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"reward-service/api/calltypes"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"strconv"
+	"time"
+)
+
+const seasonColumns = `id, name, starts_at, ends_at, closed_at, created_at, updated_at`
+
+type seasonPrize struct {
+	userID int
+	points int
+}
+
+// GetSeasons returns all seasons with their prizes, the latest first.
+func (u *PostgresRepository) GetSeasons() ([]*calltypes.Season, error) {
+	query := `select ` + seasonColumns + ` from seasons order by starts_at desc, id desc`
+
+	ctx, cancel := context.WithTimeout(context.Background(), consts.DbTimeout)
+	defer cancel()
+
+	rows, err := u.Conn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch seasons: %w", err)
+	}
+	defer rows.Close()
+
+	seasons := []*calltypes.Season{}
+	byID := map[int]*calltypes.Season{}
+
+	for rows.Next() {
+		var season *calltypes.Season
+
+		season, err = scanSeason(rows)
+		if err != nil {
+			log.Printf("Error scanning season: %v", err)
+
+			return nil, err
+		}
+
+		seasons = append(seasons, season)
+		byID[season.ID] = season
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch seasons: %w", err)
+	}
+
+	prizes, err := u.Conn.QueryContext(ctx, `select season_id, rank, points from season_prizes order by season_id, rank`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch season prizes: %w", err)
+	}
+	defer prizes.Close()
+
+	for prizes.Next() {
+		var seasonID, rank, points int
+
+		if err := prizes.Scan(&seasonID, &rank, &points); err != nil {
+			return nil, fmt.Errorf("failed to scan season prize: %w", err)
+		}
+
+		if season, ok := byID[seasonID]; ok {
+			season.Prizes = setPrize(season.Prizes, rank, points)
+		}
+	}
+
+	if err := prizes.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch season prizes: %w", err)
+	}
+
+	return seasons, nil
+}
+
+// GetSeason returns the season with its prizes.
+func (u *PostgresRepository) GetSeason(id int) (*calltypes.Season, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), consts.DbTimeout)
+	defer cancel()
+
+	season, err := scanSeason(u.Conn.QueryRowContext(ctx, `select `+seasonColumns+` from seasons where id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errormsg.ErrSeasonNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := u.Conn.QueryContext(ctx, `select rank, points from season_prizes where season_id = $1 order by rank`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch season prizes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rank, points int
+
+		if err := rows.Scan(&rank, &points); err != nil {
+			return nil, fmt.Errorf("failed to scan season prize: %w", err)
+		}
+
+		season.Prizes = setPrize(season.Prizes, rank, points)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch season prizes: %w", err)
+	}
+
+	return season, nil
+}
+
+// CreateSeason adds new season with its prizes.
+func (u *PostgresRepository) CreateSeason(season calltypes.Season) (int, error) {
+	var newID int
+
+	err := u.withTx(context.Background(), nil, func(ctx context.Context, tx *sql.Tx) error {
+		now := time.Now()
+
+		err := tx.QueryRowContext(ctx, `INSERT INTO seasons (name, starts_at, ends_at, created_at, updated_at)
+             VALUES ($1, $2, $3, $4, $4) RETURNING id`,
+			season.Name, season.StartsAt, season.EndsAt, now).Scan(&newID)
+		if err != nil {
+			return fmt.Errorf("failed to insert new season: %w", err)
+		}
+
+		for i, points := range season.Prizes {
+			if points == 0 {
+				continue
+			}
+
+			_, err = tx.ExecContext(ctx, `INSERT INTO season_prizes (season_id, rank, points) VALUES ($1, $2, $3)`,
+				newID, i+1, points)
+			if err != nil {
+				return fmt.Errorf("failed to insert season prize: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		log.Println("failed to create season: ", err)
+
+		return 0, err
+	}
+
+	return newID, nil
+}
+
+// GetSeasonLeaderboard returns up to limit entries of the season leaderboard after the cursor. The leaderboard
+// of an open season is ranked live from the points earned in it, a closed one returns the final standings.
+func (u *PostgresRepository) GetSeasonLeaderboard(season *calltypes.Season, after *calltypes.LeaderboardCursor,
+	limit int, ranking string,
+) ([]*calltypes.LeaderboardEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), consts.DbTimeout)
+	defer cancel()
+
+	if season.ClosedAt == nil {
+		return u.leaderboardPage(ctx, season.StartsAt, season.EndsAt, after, limit, ranking)
+	}
+
+	q := &leaderboardQuery{}
+	query := `select s.user_id, coalesce(u.first_name, ''), coalesce(u.last_name, ''), s.score, s.rank, s.prize
+              from season_standings s
+              join users u on u.id = s.user_id
+              where s.season_id = ` + q.arg(season.ID)
+
+	if after != nil {
+		score, userID := q.arg(after.Score), q.arg(after.UserID)
+		query += ` and s.score <= ` + score + ` and (s.score < ` + score + ` or s.user_id > ` + userID + `)`
+	}
+
+	query += ` order by s.score desc, s.user_id limit ` + q.arg(limit)
+
+	rows, err := u.Conn.QueryContext(ctx, query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch season standings: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*calltypes.LeaderboardEntry{}
+
+	for rows.Next() {
+		var (
+			entry               calltypes.LeaderboardEntry
+			firstName, lastName string
+		)
+
+		err := rows.Scan(&entry.UserID, &firstName, &lastName, &entry.Score, &entry.Rank, &entry.Prize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan season standing: %w", err)
+		}
+
+		entry.DisplayName = displayName(entry.UserID, firstName, lastName)
+		entries = append(entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch season standings: %w", err)
+	}
+
+	return entries, nil
+}
+
+// CloseSeasons closes the seasons that ended by now and returns how many were closed. Every season is closed
+// in its own transaction: the standings are archived, the prizes are paid and the season is marked closed.
+func (u *PostgresRepository) CloseSeasons(now time.Time, ranking string) (int, error) {
+	seasonIDs, err := u.endedSeasons(now)
+	if err != nil {
+		return 0, err
+	}
+
+	closed := 0
+
+	for _, seasonID := range seasonIDs {
+		err := u.withTx(context.Background(), nil, func(ctx context.Context, tx *sql.Tx) error {
+			return u.closeSeason(ctx, tx, seasonID, now, ranking)
+		})
+		if err != nil {
+			return closed, fmt.Errorf("failed to close season %d: %w", seasonID, err)
+		}
+
+		closed++
+	}
+
+	return closed, nil
+}
+
+func (u *PostgresRepository) endedSeasons(now time.Time) ([]int, error) {
+	query := `select id from seasons where closed_at is null and ends_at <= $1 order by ends_at, id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), consts.DbTimeout)
+	defer cancel()
+
+	rows, err := u.Conn.QueryContext(ctx, query, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch ended seasons: %w", err)
+	}
+	defer rows.Close()
+
+	var seasonIDs []int
+
+	for rows.Next() {
+		var seasonID int
+
+		if err := rows.Scan(&seasonID); err != nil {
+			return nil, fmt.Errorf("failed to scan ended season: %w", err)
+		}
+
+		seasonIDs = append(seasonIDs, seasonID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch ended seasons: %w", err)
+	}
+
+	return seasonIDs, nil
+}
+
+// closeSeason archives the standings of the season and pays the prizes, users who share a rank get its prize each.
+// A season closed by a concurrent run is left as it is.
+func (u *PostgresRepository) closeSeason(ctx context.Context, tx *sql.Tx, seasonID int, now time.Time,
+	ranking string,
+) error {
+	var startsAt, endsAt time.Time
+
+	err := tx.QueryRowContext(ctx, `SELECT starts_at, ends_at FROM seasons WHERE id = $1 AND closed_at IS NULL
+             FOR UPDATE`, seasonID).Scan(&startsAt, &endsAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to lock season: %w", err)
+	}
+
+	rankFunction := "rank()"
+	if ranking == consts.RankingDense {
+		rankFunction = "dense_rank()"
+	}
+
+	q := newLeaderboardQuery(startsAt, endsAt)
+	stmt := `INSERT INTO season_standings (season_id, user_id, position, rank, score)
+             SELECT ` + q.arg(seasonID) + `, id, row_number() OVER (ORDER BY score DESC, id),
+                 ` + rankFunction + ` OVER (ORDER BY score DESC), score
+             FROM (` + q.from + `) b`
+
+	_, err = tx.ExecContext(ctx, stmt, q.args...)
+	if err != nil {
+		return fmt.Errorf("failed to archive season standings: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `UPDATE season_standings s SET prize = p.points
+             FROM season_prizes p
+             WHERE s.season_id = $1 AND p.season_id = s.season_id AND p.rank = s.rank
+             RETURNING s.user_id, s.prize`, seasonID)
+	if err != nil {
+		return fmt.Errorf("failed to award season prizes: %w", err)
+	}
+
+	var prizes []seasonPrize
+
+	for rows.Next() {
+		var prize seasonPrize
+
+		if err := rows.Scan(&prize.userID, &prize.points); err != nil {
+			rows.Close()
+
+			return fmt.Errorf("failed to scan season prize: %w", err)
+		}
+
+		prizes = append(prizes, prize)
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to award season prizes: %w", err)
+	}
+
+	winners := make([]int, 0, len(prizes))
+
+	for _, prize := range prizes {
+		err = u.applyLedgerEntry(ctx, tx, calltypes.PointTransaction{
+			UserID:      prize.userID,
+			Delta:       prize.points,
+			Reason:      consts.ReasonSeasonPrize,
+			ReferenceID: strconv.Itoa(seasonID),
+		})
+		if err != nil {
+			return err
+		}
+
+		winners = append(winners, prize.userID)
+	}
+
+	err = u.awardBadges(ctx, tx, winners...)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE seasons SET closed_at = $1, updated_at = $1 WHERE id = $2`, now, seasonID)
+	if err != nil {
+		return fmt.Errorf("failed to close season: %w", err)
+	}
+
+	log.Printf("Season %d is closed, %d prizes are paid", seasonID, len(prizes))
+
+	return nil
+}
+
+// setPrize returns the prizes with the points of the rank, the ranks without a prize are zero.
+func setPrize(prizes []int, rank, points int) []int {
+	for len(prizes) < rank {
+		prizes = append(prizes, 0)
+	}
+
+	prizes[rank-1] = points
+
+	return prizes
+}
+
+func scanSeason(row rowScanner) (*calltypes.Season, error) {
+	var (
+		season   calltypes.Season
+		closedAt sql.NullTime
+	)
+
+	err := row.Scan(
+		&season.ID,
+		&season.Name,
+		&season.StartsAt,
+		&season.EndsAt,
+		&closedAt,
+		&season.CreatedAt,
+		&season.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan season: %w", err)
+	}
+
+	now := time.Now()
+
+	switch {
+	case closedAt.Valid:
+		season.ClosedAt = &closedAt.Time
+		season.Status = consts.SeasonClosed
+	case now.Before(season.StartsAt):
+		season.Status = consts.SeasonUpcoming
+	case now.Before(season.EndsAt):
+		season.Status = consts.SeasonActive
+	default:
+		season.Status = consts.SeasonEnded
+	}
+
+	return &season, nil
+}
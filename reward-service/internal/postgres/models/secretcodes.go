@@ -0,0 +1,251 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"reward-service/api/calltypes"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"strconv"
+	"time"
+)
+
+// GetSecretCodes returns all secret-code quests with the number of their redemptions ordered by id.
+func (u *PostgresRepository) GetSecretCodes() ([]*calltypes.SecretCode, error) {
+	query := `select sc.id, sc.title, sc.reward, sc.max_redemptions, sc.per_user_limit, sc.active, sc.expires_at,
+              sc.created_at, (select count(*) from secret_code_redemptions r where r.code_id = sc.id)
+              from secret_codes sc
+              order by sc.id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), consts.DbTimeout)
+	defer cancel()
+
+	rows, err := u.Conn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch secret codes: %w", err)
+	}
+	defer rows.Close()
+
+	codes := []*calltypes.SecretCode{}
+
+	for rows.Next() {
+		var (
+			code      calltypes.SecretCode
+			expiresAt sql.NullTime
+		)
+
+		err := rows.Scan(
+			&code.ID,
+			&code.Title,
+			&code.Reward,
+			&code.MaxRedemptions,
+			&code.PerUserLimit,
+			&code.Active,
+			&expiresAt,
+			&code.CreatedAt,
+			&code.Redemptions,
+		)
+		if err != nil {
+			log.Printf("Error scanning secret code: %v", err)
+
+			return nil, fmt.Errorf("failed to scan secret code: %w", err)
+		}
+
+		if expiresAt.Valid {
+			code.ExpiresAt = &expiresAt.Time
+		}
+
+		codes = append(codes, &code)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch secret codes: %w", err)
+	}
+
+	return codes, nil
+}
+
+// CreateSecretCode adds new secret-code quest.
+func (u *PostgresRepository) CreateSecretCode(code calltypes.SecretCode) (int, error) {
+	stmt := `insert into secret_codes (title, code_hash, reward, max_redemptions, per_user_limit, active, expires_at,
+             created_at)
+             values ($1, $2, $3, $4, $5, $6, $7, $8) returning id`
+
+	var newID int
+
+	err := u.queryRow(context.Background(), stmt,
+		code.Title,
+		code.CodeHash,
+		code.Reward,
+		code.MaxRedemptions,
+		code.PerUserLimit,
+		code.Active,
+		code.ExpiresAt,
+		time.Now(),
+	).Scan(&newID)
+	if isUniqueViolation(err) {
+		return 0, errormsg.ErrSecretCodeTaken
+	}
+
+	if err != nil {
+		log.Println("failed to insert new secret code: ", err)
+
+		return 0, fmt.Errorf("failed to insert new secret code: %w", err)
+	}
+
+	return newID, nil
+}
+
+// RedeemSecretCode redeems the quest with provided code hash for entry.UserID and rewards them with entry,
+// the reward and the reference of the entry are taken from the quest. Quests created before the hashes were
+// keyed are found by legacyHash and rehashed with codeHash. The quest row is locked, so that the redemption
+// limits hold under concurrent requests.
+//
+// Attempts are throttled in the same transaction under the lock on the user's row: a wrong code is recorded
+// and errormsg.ErrTooManyCodeAttempts is returned once the user or all users together made too many wrong
+// attempts within consts.SecretCodeAttemptWindow.
+func (u *PostgresRepository) RedeemSecretCode(codeHash, legacyHash string, entry calltypes.PointTransaction,
+) (*calltypes.SecretCode, error) {
+	var (
+		code  calltypes.SecretCode
+		wrong bool
+	)
+
+	err := u.withTx(context.Background(), nil, func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `SELECT 1 FROM users WHERE id = $1 FOR UPDATE`, entry.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to lock user: %w", err)
+		}
+
+		now := time.Now()
+
+		err = checkCodeAttempts(ctx, tx, entry.UserID, now)
+		if err != nil {
+			return err
+		}
+
+		var (
+			expiresAt sql.NullTime
+			legacy    bool
+		)
+
+		err = tx.QueryRowContext(ctx, `SELECT id, title, reward, max_redemptions, per_user_limit, active, expires_at,
+             legacy_hash
+             FROM secret_codes WHERE code_hash = $1 OR (legacy_hash AND code_hash = $2) FOR UPDATE`,
+			codeHash, legacyHash).
+			Scan(&code.ID, &code.Title, &code.Reward, &code.MaxRedemptions, &code.PerUserLimit, &code.Active, &expiresAt,
+				&legacy)
+		if errors.Is(err, sql.ErrNoRows) {
+			// The attempt must be committed, the error is returned after the transaction.
+			wrong = true
+
+			return recordCodeAttempt(ctx, tx, entry.UserID, now)
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to fetch secret code: %w", err)
+		}
+
+		if legacy {
+			_, err = tx.ExecContext(ctx, `UPDATE secret_codes SET code_hash = $1, legacy_hash = FALSE WHERE id = $2`,
+				codeHash, code.ID)
+			if err != nil {
+				return fmt.Errorf("failed to rehash secret code: %w", err)
+			}
+		}
+
+		if !code.Active || (expiresAt.Valid && !now.Before(expiresAt.Time)) {
+			return errormsg.ErrSecretCodeExpired
+		}
+
+		var userRedemptions int
+
+		err = tx.QueryRowContext(ctx, `SELECT count(*), count(*) FILTER (WHERE user_id = $2)
+             FROM secret_code_redemptions WHERE code_id = $1`, code.ID, entry.UserID).
+			Scan(&code.Redemptions, &userRedemptions)
+		if err != nil {
+			return fmt.Errorf("failed to count secret code redemptions: %w", err)
+		}
+
+		if code.MaxRedemptions > 0 && code.Redemptions >= code.MaxRedemptions {
+			return errormsg.ErrSecretCodeExhausted
+		}
+
+		if userRedemptions >= code.PerUserLimit {
+			return errormsg.ErrSecretCodeAlreadyRedeemed
+		}
+
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO secret_code_redemptions (code_id, user_id, redeemed_at) VALUES ($1, $2, $3)`,
+			code.ID, entry.UserID, now)
+		if err != nil {
+			return fmt.Errorf("failed to record secret code redemption: %w", err)
+		}
+
+		code.Redemptions++
+		entry.Delta = code.Reward
+		entry.ReferenceID = strconv.Itoa(code.ID)
+
+		err = u.applyLedgerEntry(ctx, tx, entry)
+		if err != nil {
+			return err
+		}
+
+		return u.awardBadges(ctx, tx, entry.UserID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if wrong {
+		return nil, errormsg.ErrSecretCodeNotFound
+	}
+
+	return &code, nil
+}
+
+// checkCodeAttempts returns errormsg.ErrTooManyCodeAttempts when the user made too many wrong attempts within
+// consts.SecretCodeAttemptWindow. The caller holds the lock on the user's row. Attempts of all users together
+// don't lock anyone out, a warning is logged when they reach consts.SecretCodeAlertAttempts.
+func checkCodeAttempts(ctx context.Context, tx *sql.Tx, userID int, now time.Time) error {
+	var userAttempts, attempts int
+
+	err := tx.QueryRowContext(ctx, `SELECT count(*) FILTER (WHERE user_id = $1), count(*)
+             FROM secret_code_attempts WHERE attempted_at >= $2`, userID, now.Add(-consts.SecretCodeAttemptWindow)).
+		Scan(&userAttempts, &attempts)
+	if err != nil {
+		return fmt.Errorf("failed to count secret code attempts: %w", err)
+	}
+
+	if attempts == consts.SecretCodeAlertAttempts {
+		log.Printf("WARNING: %d wrong secret and promo codes were entered within %s", attempts,
+			consts.SecretCodeAttemptWindow)
+	}
+
+	if userAttempts >= consts.SecretCodeMaxAttempts {
+		return errormsg.ErrTooManyCodeAttempts
+	}
+
+	return nil
+}
+
+// recordCodeAttempt remembers that the user entered a wrong secret code. Attempts older than
+// consts.SecretCodeAttemptWindow are forgotten.
+func recordCodeAttempt(ctx context.Context, tx *sql.Tx, userID int, now time.Time) error {
+	_, err := tx.ExecContext(ctx, `DELETE FROM secret_code_attempts WHERE attempted_at < $1`,
+		now.Add(-consts.SecretCodeAttemptWindow))
+	if err != nil {
+		return fmt.Errorf("failed to delete old secret code attempts: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO secret_code_attempts (user_id, attempted_at) VALUES ($1, $2)`,
+		userID, now)
+	if err != nil {
+		return fmt.Errorf("failed to record secret code attempt: %w", err)
+	}
+
+	return nil
+}
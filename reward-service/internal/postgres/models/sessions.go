@@ -0,0 +1,294 @@
+package models
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"reward-service/api/calltypes"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"time"
+)
+
+// CreateSession starts a new session for the user. Only the keyed hash of the refresh token is persisted.
+func (u *PostgresRepository) CreateSession(session calltypes.Session, refreshToken calltypes.RefreshToken) (int, error) {
+	idExists, err := u.UserExists(session.UserID)
+	if err != nil {
+		return 0, err
+	}
+
+	if !idExists {
+		log.Println("User does not exist")
+
+		return 0, errormsg.ErrUserNotFound
+	}
+
+	var sessionID int
+
+	now := time.Now()
+
+	stmt := `INSERT INTO sessions (user_id, selector, token_hash, user_agent, ip, created_at, last_used_at, expires_at)
+             VALUES ($1, $2, $3, $4, $5, $6, $6, $7) RETURNING id`
+
+	err = u.queryRow(context.Background(), stmt,
+		session.UserID,
+		refreshToken.Selector,
+		refreshToken.Hash,
+		session.UserAgent,
+		session.IP,
+		now,
+		now.Add(consts.RefreshTokenExpireTime),
+	).Scan(&sessionID)
+	if err != nil {
+		log.Println("failed to create session: ", err)
+
+		return 0, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return sessionID, nil
+}
+
+// RotateSession replaces a valid refresh token of a session with a new one and remembers the old one,
+// so that a second use of an already rotated token can be detected.
+// When the old token was rotated before, its session is returned together with ErrRefreshTokenReused.
+func (u *PostgresRepository) RotateSession(oldToken, newToken calltypes.RefreshToken, userAgent, ip string) (*calltypes.Session, error) {
+	var session calltypes.Session
+
+	now := time.Now()
+
+	err := u.withTx(context.Background(), nil, func(ctx context.Context, tx *sql.Tx) error {
+		var storedHash string
+
+		err := tx.QueryRowContext(ctx, `SELECT s.id, s.user_id, s.token_hash, s.created_at, u.role
+                 FROM sessions s JOIN users u ON u.id = s.user_id
+                 WHERE s.selector = $1 AND s.expires_at > $2 FOR UPDATE OF s`,
+			oldToken.Selector, now).Scan(&session.ID, &session.UserID, &storedHash, &session.CreatedAt, &session.UserRole)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errormsg.ErrInvalidRefreshToken
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to find session: %w", err)
+		}
+
+		if !tokenHashMatches(storedHash, oldToken.Hash) {
+			return errormsg.ErrInvalidRefreshToken
+		}
+
+		session.UserAgent = userAgent
+		session.IP = ip
+		session.LastUsedAt = now
+		session.ExpiresAt = now.Add(consts.RefreshTokenExpireTime)
+
+		stmt := `UPDATE sessions SET selector = $1, token_hash = $2, user_agent = $3, ip = $4, last_used_at = $5, expires_at = $6
+                 WHERE id = $7`
+
+		_, err = tx.ExecContext(ctx, stmt,
+			newToken.Selector, newToken.Hash, userAgent, ip, now, session.ExpiresAt, session.ID)
+		if err != nil {
+			return fmt.Errorf("failed to rotate refresh token: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM refresh_token_history WHERE session_id = $1 AND rotated_at < $2`,
+			session.ID, now.Add(-consts.RefreshTokenExpireTime))
+		if err != nil {
+			return fmt.Errorf("failed to purge refresh token history: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `INSERT INTO refresh_token_history (user_id, session_id, selector, token, rotated_at)
+                 VALUES ($1, $2, $3, $4, $5)`,
+			session.UserID, session.ID, oldToken.Selector, oldToken.Hash, now)
+		if err != nil {
+			return fmt.Errorf("failed to remember rotated refresh token: %w", err)
+		}
+
+		return nil
+	})
+	if err == nil {
+		return &session, nil
+	}
+
+	if !errors.Is(err, errormsg.ErrInvalidRefreshToken) {
+		log.Println("failed to rotate session: ", err)
+
+		return nil, err
+	}
+
+	var storedHash string
+
+	err = u.queryRow(context.Background(),
+		"SELECT user_id, session_id, token FROM refresh_token_history WHERE selector = $1",
+		oldToken.Selector).Scan(&session.UserID, &session.ID, &storedHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errormsg.ErrInvalidRefreshToken
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to check refresh token history: %w", err)
+	}
+
+	if !tokenHashMatches(storedHash, oldToken.Hash) {
+		return nil, errormsg.ErrInvalidRefreshToken
+	}
+
+	return &session, errormsg.ErrRefreshTokenReused
+}
+
+// GetSessions returns active sessions of the user, most recently used first.
+func (u *PostgresRepository) GetSessions(userID int) ([]*calltypes.Session, error) {
+	query := `select id, user_id, user_agent, ip, created_at, last_used_at, expires_at
+              from sessions where user_id = $1 and expires_at > $2 order by last_used_at desc`
+
+	ctx, cancel := context.WithTimeout(context.Background(), consts.DbTimeout)
+	defer cancel()
+
+	rows, err := u.Conn.QueryContext(ctx, query, userID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []*calltypes.Session{}
+
+	for rows.Next() {
+		var session calltypes.Session
+
+		err := rows.Scan(
+			&session.ID,
+			&session.UserID,
+			&session.UserAgent,
+			&session.IP,
+			&session.CreatedAt,
+			&session.LastUsedAt,
+			&session.ExpiresAt,
+		)
+		if err != nil {
+			log.Printf("Error scanning session: %v", err)
+
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+
+		sessions = append(sessions, &session)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("Error after row iteration: %v", err)
+
+		return nil, fmt.Errorf("failed to fetch sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession ends one session of the user.
+func (u *PostgresRepository) RevokeSession(userID, sessionID int) error {
+	result, err := u.execQuery(context.Background(),
+		`DELETE FROM sessions WHERE id = $1 AND user_id = $2`, sessionID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	if affected == 0 {
+		return errormsg.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// RevokeSessionByToken ends the session the refresh token belongs to, unknown tokens are ignored.
+func (u *PostgresRepository) RevokeSessionByToken(refreshToken calltypes.RefreshToken) error {
+	var (
+		sessionID  int
+		storedHash string
+	)
+
+	err := u.queryRow(context.Background(),
+		"SELECT id, token_hash FROM sessions WHERE selector = $1", refreshToken.Selector).Scan(&sessionID, &storedHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to find session: %w", err)
+	}
+
+	if !tokenHashMatches(storedHash, refreshToken.Hash) {
+		return nil
+	}
+
+	_, err = u.execQuery(context.Background(), `DELETE FROM sessions WHERE id = $1`, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeAllSessions ends every session of the user and revokes every access token issued to the user up to now.
+func (u *PostgresRepository) RevokeAllSessions(userID int) error {
+	now := time.Now()
+
+	return u.withTx(context.Background(), nil, func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `DELETE FROM sessions WHERE user_id = $1`, userID)
+		if err != nil {
+			return fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+
+		// Access tokens carry iat with second precision, so the revocation moment is truncated the same way.
+		_, err = tx.ExecContext(ctx, `UPDATE users SET tokens_revoked_at = $1, updated_at = $2 WHERE id = $3`,
+			now.Truncate(time.Second), now, userID)
+		if err != nil {
+			return fmt.Errorf("failed to revoke access tokens: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// RevokeAccessToken adds the access token to the denylist until it expires.
+func (u *PostgresRepository) RevokeAccessToken(tokenID string, userID int, expiresAt time.Time) error {
+	_, err := u.execQuery(context.Background(), `DELETE FROM revoked_access_tokens WHERE expires_at < $1`, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to purge revoked access tokens: %w", err)
+	}
+
+	stmt := `INSERT INTO revoked_access_tokens (jti, user_id, expires_at) VALUES ($1, $2, $3)
+             ON CONFLICT (jti) DO NOTHING`
+
+	_, err = u.execQuery(context.Background(), stmt, tokenID, userID, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+
+	return nil
+}
+
+// IsAccessTokenRevoked checks the denylist, whether the token's session still exists
+// and whether all user's tokens were revoked after the token was issued.
+func (u *PostgresRepository) IsAccessTokenRevoked(tokenID string, userID, sessionID int, issuedAt time.Time) (bool, error) {
+	var revoked bool
+
+	query := `SELECT EXISTS(SELECT 1 FROM revoked_access_tokens WHERE jti = $1)
+              OR NOT EXISTS(SELECT 1 FROM sessions WHERE id = $3 AND user_id = $2)
+              OR EXISTS(SELECT 1 FROM users WHERE id = $2 AND tokens_revoked_at > $4)`
+
+	err := u.queryRow(context.Background(), query, tokenID, userID, sessionID, issuedAt).Scan(&revoked)
+	if err != nil {
+		return false, fmt.Errorf("failed to check access token revocation: %w", err)
+	}
+
+	return revoked, nil
+}
+
+// tokenHashMatches compares stored and presented token hashes in constant time.
+func tokenHashMatches(stored, presented string) bool {
+	return subtle.ConstantTimeCompare([]byte(stored), []byte(presented)) == 1
+}
@@ -0,0 +1,376 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"reward-service/api/calltypes"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"strconv"
+	"time"
+)
+
+const storeItemColumns = `id, title, description, price, stock, per_user_limit, digital, active, created_at, updated_at,
+                          (select count(*) from vouchers v where v.item_id = store_items.id and v.redemption_id is null)`
+
+const redemptionColumns = `r.id, r.user_id, r.item_id, i.title, r.price, r.status, r.created_at, r.updated_at`
+
+// GetStoreItems returns the items of the store ordered by price. Inactive items and items that are out of stock
+// or vouchers are returned only when includeUnavailable is set.
+func (u *PostgresRepository) GetStoreItems(includeUnavailable bool) ([]*calltypes.StoreItem, error) {
+	query := `select ` + storeItemColumns + `
+              from store_items
+              where $1 or (active and (stock is null or stock > 0) and (not digital or exists (
+                  select 1 from vouchers v where v.item_id = store_items.id and v.redemption_id is null)))
+              order by price, id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), consts.DbTimeout)
+	defer cancel()
+
+	rows, err := u.Conn.QueryContext(ctx, query, includeUnavailable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch store items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []*calltypes.StoreItem{}
+
+	for rows.Next() {
+		var item *calltypes.StoreItem
+
+		item, err = scanStoreItem(rows)
+		if err != nil {
+			log.Printf("Error scanning store item: %v", err)
+
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch store items: %w", err)
+	}
+
+	return items, nil
+}
+
+// CreateStoreItem adds new item to the store.
+func (u *PostgresRepository) CreateStoreItem(item calltypes.StoreItem) (int, error) {
+	stmt := `insert into store_items (title, description, price, stock, per_user_limit, digital, active, created_at,
+             updated_at)
+             values ($1, $2, $3, $4, $5, $6, $7, $8, $8) returning id`
+
+	var newID int
+
+	err := u.queryRow(context.Background(), stmt,
+		item.Title,
+		item.Description,
+		item.Price,
+		item.Stock,
+		item.PerUserLimit,
+		item.Digital,
+		item.Active,
+		time.Now(),
+	).Scan(&newID)
+	if err != nil {
+		log.Println("failed to insert new store item: ", err)
+
+		return 0, fmt.Errorf("failed to insert new store item: %w", err)
+	}
+
+	return newID, nil
+}
+
+// UpdateStoreItem updates the store item with the same id. The price of redemptions made before stays the same.
+func (u *PostgresRepository) UpdateStoreItem(item calltypes.StoreItem) error {
+	stmt := `update store_items set title = $1, description = $2, price = $3, stock = $4, per_user_limit = $5,
+             digital = $6, active = $7, updated_at = $8
+             where id = $9`
+
+	result, err := u.execQuery(context.Background(), stmt,
+		item.Title,
+		item.Description,
+		item.Price,
+		item.Stock,
+		item.PerUserLimit,
+		item.Digital,
+		item.Active,
+		time.Now(),
+		item.ID,
+	)
+	if err != nil {
+		log.Println("failed to update store item: ", err)
+
+		return fmt.Errorf("failed to update store item: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update store item: %w", err)
+	}
+
+	if affected == 0 {
+		return errormsg.ErrStoreItemNotFound
+	}
+
+	return nil
+}
+
+// RedeemItem buys the store item for the user. The balance is checked, the price is deducted with a negative
+// ledger entry and one item is reserved from the stock in one transaction. The redemption stays pending
+// until an admin fulfills or cancels it, except for digital items: they claim a voucher and are fulfilled at once.
+func (u *PostgresRepository) RedeemItem(userID, itemID, actorID int) (*calltypes.Redemption, error) {
+	redemption := calltypes.Redemption{UserID: userID, ItemID: itemID, Status: consts.RedemptionPending}
+
+	err := u.withTx(context.Background(), nil, func(ctx context.Context, tx *sql.Tx) error {
+		item, err := scanStoreItem(tx.QueryRowContext(ctx,
+			`SELECT `+storeItemColumns+` FROM store_items WHERE id = $1 FOR UPDATE`, itemID))
+		if errors.Is(err, sql.ErrNoRows) {
+			return errormsg.ErrStoreItemNotFound
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if !item.Active {
+			return errormsg.ErrStoreItemNotAvailable
+		}
+
+		if (item.Stock != nil && *item.Stock <= 0) || (item.Digital && *item.VouchersLeft <= 0) {
+			return errormsg.ErrOutOfStock
+		}
+
+		var balance int
+
+		// The lock on the user's row serializes their redemptions, so the balance and the limit hold.
+		err = tx.QueryRowContext(ctx, `SELECT score FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&balance)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errormsg.ErrUserNotFound
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to lock user's balance: %w", err)
+		}
+
+		if balance < item.Price {
+			return errormsg.ErrInsufficientPoints
+		}
+
+		if item.PerUserLimit > 0 {
+			var redeemed int
+
+			err = tx.QueryRowContext(ctx, `SELECT count(*) FROM redemptions
+                 WHERE item_id = $1 AND user_id = $2 AND status <> $3`,
+				itemID, userID, consts.RedemptionCancelled).Scan(&redeemed)
+			if err != nil {
+				return fmt.Errorf("failed to count redemptions: %w", err)
+			}
+
+			if redeemed >= item.PerUserLimit {
+				return errormsg.ErrRedemptionLimitReached
+			}
+		}
+
+		if item.Stock != nil {
+			_, err = tx.ExecContext(ctx, `UPDATE store_items SET stock = stock - 1 WHERE id = $1`, itemID)
+			if err != nil {
+				return fmt.Errorf("failed to reserve store item: %w", err)
+			}
+		}
+
+		now := time.Now()
+
+		if item.Digital {
+			redemption.Status = consts.RedemptionFulfilled
+		}
+
+		err = tx.QueryRowContext(ctx, `INSERT INTO redemptions (user_id, item_id, price, status, created_at, updated_at)
+             VALUES ($1, $2, $3, $4, $5, $5) RETURNING id`,
+			userID, itemID, item.Price, redemption.Status, now).Scan(&redemption.ID)
+		if err != nil {
+			return fmt.Errorf("failed to record redemption: %w", err)
+		}
+
+		if item.Digital {
+			err = claimVoucher(ctx, tx, item, redemption.ID, now)
+			if err != nil {
+				return err
+			}
+		}
+
+		redemption.ItemTitle = item.Title
+		redemption.Price = item.Price
+		redemption.CreatedAt = now
+		redemption.UpdatedAt = now
+
+		return u.applyLedgerEntry(ctx, tx, calltypes.PointTransaction{
+			UserID:      userID,
+			Delta:       -item.Price,
+			Reason:      consts.ReasonRedemption,
+			ReferenceID: strconv.Itoa(redemption.ID),
+			ActorID:     actorID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &redemption, nil
+}
+
+// GetRedemptions returns redemptions newest first. Zero userID and empty status don't filter.
+func (u *PostgresRepository) GetRedemptions(userID int, status string) ([]*calltypes.Redemption, error) {
+	query := `select ` + redemptionColumns + `
+              from redemptions r
+              join store_items i on i.id = r.item_id
+              where ($1 = 0 or r.user_id = $1) and ($2 = '' or r.status = $2)
+              order by r.id desc`
+
+	ctx, cancel := context.WithTimeout(context.Background(), consts.DbTimeout)
+	defer cancel()
+
+	rows, err := u.Conn.QueryContext(ctx, query, userID, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch redemptions: %w", err)
+	}
+	defer rows.Close()
+
+	redemptions := []*calltypes.Redemption{}
+
+	for rows.Next() {
+		var redemption *calltypes.Redemption
+
+		redemption, err = scanRedemption(rows)
+		if err != nil {
+			log.Printf("Error scanning redemption: %v", err)
+
+			return nil, err
+		}
+
+		redemptions = append(redemptions, redemption)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch redemptions: %w", err)
+	}
+
+	return redemptions, nil
+}
+
+// SetRedemptionStatus fulfills or cancels the pending redemption. A cancelled redemption returns the item
+// to the stock and refunds the price with a ledger entry made by actorID.
+func (u *PostgresRepository) SetRedemptionStatus(id int, status string, actorID int) (*calltypes.Redemption, error) {
+	var redemption *calltypes.Redemption
+
+	err := u.withTx(context.Background(), nil, func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+
+		redemption, err = scanRedemption(tx.QueryRowContext(ctx, `SELECT `+redemptionColumns+`
+             FROM redemptions r JOIN store_items i ON i.id = r.item_id
+             WHERE r.id = $1 FOR UPDATE OF r`, id))
+		if errors.Is(err, sql.ErrNoRows) {
+			return errormsg.ErrRedemptionNotFound
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if redemption.Status != consts.RedemptionPending {
+			return errormsg.ErrRedemptionNotPending
+		}
+
+		redemption.Status = status
+		redemption.UpdatedAt = time.Now()
+
+		_, err = tx.ExecContext(ctx, `UPDATE redemptions SET status = $1, updated_at = $2 WHERE id = $3`,
+			status, redemption.UpdatedAt, id)
+		if err != nil {
+			return fmt.Errorf("failed to update redemption: %w", err)
+		}
+
+		if status != consts.RedemptionCancelled {
+			return nil
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE store_items SET stock = stock + 1 WHERE id = $1 AND stock IS NOT NULL`,
+			redemption.ItemID)
+		if err != nil {
+			return fmt.Errorf("failed to return store item to the stock: %w", err)
+		}
+
+		return u.applyLedgerEntry(ctx, tx, calltypes.PointTransaction{
+			UserID:      redemption.UserID,
+			Delta:       redemption.Price,
+			Reason:      consts.ReasonRefund,
+			ReferenceID: strconv.Itoa(redemption.ID),
+			ActorID:     actorID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return redemption, nil
+}
+
+func scanStoreItem(row rowScanner) (*calltypes.StoreItem, error) {
+	var (
+		item         calltypes.StoreItem
+		stock        sql.NullInt64
+		vouchersLeft int
+	)
+
+	err := row.Scan(
+		&item.ID,
+		&item.Title,
+		&item.Description,
+		&item.Price,
+		&stock,
+		&item.PerUserLimit,
+		&item.Digital,
+		&item.Active,
+		&item.CreatedAt,
+		&item.UpdatedAt,
+		&vouchersLeft,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan store item: %w", err)
+	}
+
+	if stock.Valid {
+		itemStock := int(stock.Int64)
+		item.Stock = &itemStock
+	}
+
+	if item.Digital {
+		item.VouchersLeft = &vouchersLeft
+	}
+
+	return &item, nil
+}
+
+func scanRedemption(row rowScanner) (*calltypes.Redemption, error) {
+	var redemption calltypes.Redemption
+
+	err := row.Scan(
+		&redemption.ID,
+		&redemption.UserID,
+		&redemption.ItemID,
+		&redemption.ItemTitle,
+		&redemption.Price,
+		&redemption.Status,
+		&redemption.CreatedAt,
+		&redemption.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan redemption: %w", err)
+	}
+
+	return &redemption, nil
+}
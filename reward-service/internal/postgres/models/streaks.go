@@ -0,0 +1,247 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"reward-service/api/calltypes"
+	"reward-service/internal/streak"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"time"
+)
+
+// GetStreak returns the user's check-in streak as of now in the user's timezone.
+func (u *PostgresRepository) GetStreak(userID int) (*calltypes.Streak, error) {
+	query := `select u.timezone, coalesce(s.current, 0), coalesce(s.longest, 0), s.last_checkin, coalesce(s.freezes, 0)
+              from users u
+              left join streaks s on s.user_id = u.id
+              where u.id = $1`
+
+	state, timezone, err := scanStreak(u.queryRow(context.Background(), query, userID))
+	if err != nil {
+		return nil, err
+	}
+
+	return streakView(state, timezone, time.Now()), nil
+}
+
+// CheckIn records the user's check-in for today in their timezone and pays the reward of the streak it makes.
+func (u *PostgresRepository) CheckIn(userID int, now time.Time) (*calltypes.CheckIn, error) {
+	var checkIn calltypes.CheckIn
+
+	err := u.withTx(context.Background(), nil, func(ctx context.Context, tx *sql.Tx) error {
+		state, timezone, err := lockStreak(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+
+		today := streak.Date(now, location(timezone))
+
+		state, used, err := streak.CheckIn(state, today)
+		if err != nil {
+			return err //nolint: wrapcheck
+		}
+
+		checkIn = calltypes.CheckIn{
+			Date:        today.Format(time.DateOnly),
+			Streak:      state.Current,
+			Reward:      streak.Reward(state.Current),
+			FreezesUsed: used,
+		}
+
+		err = saveStreak(ctx, tx, userID, state, now)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `INSERT INTO checkins (user_id, checkin_date, streak, reward, freezes_used, created_at)
+             VALUES ($1, $2, $3, $4, $5, $6)`,
+			userID, today, checkIn.Streak, checkIn.Reward, used, now)
+		if isUniqueViolation(err) {
+			return errormsg.ErrAlreadyCheckedIn
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to record check-in: %w", err)
+		}
+
+		err = u.applyLedgerEntry(ctx, tx, calltypes.PointTransaction{
+			UserID:      userID,
+			Delta:       checkIn.Reward,
+			Reason:      consts.ReasonDailyCheckin,
+			ReferenceID: checkIn.Date,
+		})
+		if err != nil {
+			return err
+		}
+
+		return u.awardBadges(ctx, tx, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("User %d checked in on %s, streak %d", userID, checkIn.Date, checkIn.Streak)
+
+	return &checkIn, nil
+}
+
+// BuyStreakFreeze sells the user a streak freeze for consts.StreakFreezePrice points. A freeze covers one missed
+// day, the user holds at most consts.MaxStreakFreezes of them.
+func (u *PostgresRepository) BuyStreakFreeze(userID int) (*calltypes.Streak, error) {
+	var view *calltypes.Streak
+
+	err := u.withTx(context.Background(), nil, func(ctx context.Context, tx *sql.Tx) error {
+		state, timezone, err := lockStreak(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+
+		if state.Freezes >= consts.MaxStreakFreezes {
+			return errormsg.ErrStreakFreezeLimit
+		}
+
+		var balance int
+
+		err = tx.QueryRowContext(ctx, `SELECT score FROM users WHERE id = $1`, userID).Scan(&balance)
+		if err != nil {
+			return fmt.Errorf("failed to fetch user's balance: %w", err)
+		}
+
+		if balance < consts.StreakFreezePrice {
+			return errormsg.ErrInsufficientPoints
+		}
+
+		now := time.Now()
+		state.Freezes++
+
+		err = saveStreak(ctx, tx, userID, state, now)
+		if err != nil {
+			return err
+		}
+
+		view = streakView(state, timezone, now)
+
+		return u.applyLedgerEntry(ctx, tx, calltypes.PointTransaction{
+			UserID: userID,
+			Delta:  -consts.StreakFreezePrice,
+			Reason: consts.ReasonStreakFreeze,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return view, nil
+}
+
+// SetTimezone changes the timezone the user's check-in days are counted in.
+func (u *PostgresRepository) SetTimezone(userID int, timezone string) error {
+	if !streak.ValidTimezone(timezone) {
+		return errormsg.ErrInvalidTimezone
+	}
+
+	stmt := `update users set timezone = $1, updated_at = $2 where id = $3`
+
+	result, err := u.execQuery(context.Background(), stmt, timezone, time.Now(), userID)
+	if err != nil {
+		return fmt.Errorf("failed to set timezone: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to set timezone: %w", err)
+	}
+
+	if affected == 0 {
+		return errormsg.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// lockStreak locks the user's row, so check-ins and freeze purchases of the user run one at a time,
+// and returns the streak with the user's timezone.
+func lockStreak(ctx context.Context, tx *sql.Tx, userID int) (streak.State, string, error) {
+	return scanStreak(tx.QueryRowContext(ctx, `SELECT u.timezone, COALESCE(s.current, 0), COALESCE(s.longest, 0),
+             s.last_checkin, COALESCE(s.freezes, 0)
+             FROM users u
+             LEFT JOIN streaks s ON s.user_id = u.id
+             WHERE u.id = $1
+             FOR UPDATE OF u`, userID))
+}
+
+func saveStreak(ctx context.Context, tx *sql.Tx, userID int, state streak.State, now time.Time) error {
+	var lastCheckIn sql.NullTime
+	if !state.LastCheckIn.IsZero() {
+		lastCheckIn = sql.NullTime{Time: state.LastCheckIn, Valid: true}
+	}
+
+	_, err := tx.ExecContext(ctx, `INSERT INTO streaks (user_id, current, longest, last_checkin, freezes, updated_at)
+             VALUES ($1, $2, $3, $4, $5, $6)
+             ON CONFLICT (user_id) DO UPDATE SET current = EXCLUDED.current, longest = EXCLUDED.longest,
+                 last_checkin = EXCLUDED.last_checkin, freezes = EXCLUDED.freezes, updated_at = EXCLUDED.updated_at`,
+		userID, state.Current, state.Longest, lastCheckIn, state.Freezes, now)
+	if err != nil {
+		return fmt.Errorf("failed to save streak: %w", err)
+	}
+
+	return nil
+}
+
+func scanStreak(row rowScanner) (streak.State, string, error) {
+	var (
+		state       streak.State
+		timezone    string
+		lastCheckIn sql.NullTime
+	)
+
+	err := row.Scan(&timezone, &state.Current, &state.Longest, &lastCheckIn, &state.Freezes)
+	if errors.Is(err, sql.ErrNoRows) {
+		return streak.State{}, "", errormsg.ErrUserNotFound
+	}
+
+	if err != nil {
+		return streak.State{}, "", fmt.Errorf("failed to scan streak: %w", err)
+	}
+
+	if lastCheckIn.Valid {
+		state.LastCheckIn = streak.Date(lastCheckIn.Time, time.UTC)
+	}
+
+	return state, timezone, nil
+}
+
+// streakView returns the streak as the user sees it on the day of now.
+func streakView(state streak.State, timezone string, now time.Time) *calltypes.Streak {
+	view := calltypes.Streak{
+		Current:  streak.Current(state, streak.Date(now, location(timezone))),
+		Longest:  state.Longest,
+		Freezes:  state.Freezes,
+		Timezone: timezone,
+	}
+
+	if !state.LastCheckIn.IsZero() {
+		view.LastCheckIn = state.LastCheckIn.Format(time.DateOnly)
+	}
+
+	return &view
+}
+
+// location returns the user's timezone, a name the server doesn't know falls back to consts.DefaultTimezone.
+func location(timezone string) *time.Location {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		log.Printf("unknown timezone %q, using %s: %v", timezone, consts.DefaultTimezone, err)
+
+		loc, err = time.LoadLocation(consts.DefaultTimezone)
+		if err != nil {
+			return time.UTC
+		}
+	}
+
+	return loc
+}
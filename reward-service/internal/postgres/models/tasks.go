@@ -0,0 +1,343 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"reward-service/api/calltypes"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"time"
+)
+
+const taskColumns = `id, slug, title, description, reward, policy, max_completions, period_seconds, cooldown_seconds,
+                     verifier, active, starts_at, ends_at, created_at, updated_at`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// GetTasks returns the task catalog ordered by id. Inactive tasks and tasks outside
+// of their time window are returned only when includeUnavailable is set.
+func (u *PostgresRepository) GetTasks(includeUnavailable bool) ([]*calltypes.Task, error) {
+	query := `select ` + taskColumns + `
+              from tasks
+              where $1 or (active and (starts_at is null or starts_at <= $2) and (ends_at is null or ends_at > $2))
+              order by id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), consts.DbTimeout)
+	defer cancel()
+
+	rows, err := u.Conn.QueryContext(ctx, query, includeUnavailable, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tasks: %w", err)
+	}
+	defer rows.Close()
+
+	tasks := []*calltypes.Task{}
+
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			log.Printf("Error scanning task: %v", err)
+
+			return nil, err
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("Error after row iteration: %v", err)
+
+		return nil, fmt.Errorf("failed to fetch tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// GetTask returns the task with provided slug.
+func (u *PostgresRepository) GetTask(slug string) (*calltypes.Task, error) {
+	row := u.queryRow(context.Background(), `select `+taskColumns+` from tasks where slug = $1`, slug)
+
+	task, err := scanTask(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errormsg.ErrTaskNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// CreateTask adds new task to the catalog.
+func (u *PostgresRepository) CreateTask(task calltypes.Task) (int, error) {
+	stmt := `insert into tasks (slug, title, description, reward, policy, max_completions, period_seconds,
+             cooldown_seconds, verifier, active, starts_at, ends_at, created_at, updated_at)
+             values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $13) returning id`
+
+	var newID int
+
+	err := u.queryRow(context.Background(), stmt,
+		task.Slug,
+		task.Title,
+		task.Description,
+		task.Reward,
+		task.Policy,
+		task.MaxCompletions,
+		task.PeriodSeconds,
+		task.CooldownSeconds,
+		task.Verifier,
+		task.Active,
+		task.StartsAt,
+		task.EndsAt,
+		time.Now(),
+	).Scan(&newID)
+	if isUniqueViolation(err) {
+		return 0, errormsg.ErrTaskSlugTaken
+	}
+
+	if err != nil {
+		log.Println("failed to insert new task: ", err)
+
+		return 0, fmt.Errorf("failed to insert new task: %w", err)
+	}
+
+	return newID, nil
+}
+
+// UpdateTask updates the task with the same slug.
+func (u *PostgresRepository) UpdateTask(task calltypes.Task) error {
+	stmt := `update tasks set title = $1, description = $2, reward = $3, policy = $4, max_completions = $5,
+             period_seconds = $6, cooldown_seconds = $7, verifier = $8, active = $9, starts_at = $10, ends_at = $11,
+             updated_at = $12
+             where slug = $13`
+
+	result, err := u.execQuery(context.Background(), stmt,
+		task.Title,
+		task.Description,
+		task.Reward,
+		task.Policy,
+		task.MaxCompletions,
+		task.PeriodSeconds,
+		task.CooldownSeconds,
+		task.Verifier,
+		task.Active,
+		task.StartsAt,
+		task.EndsAt,
+		time.Now(),
+		task.Slug,
+	)
+	if err != nil {
+		log.Println("failed to update task: ", err)
+
+		return fmt.Errorf("failed to update task: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update task: %w", err)
+	}
+
+	if affected == 0 {
+		return errormsg.ErrTaskNotFound
+	}
+
+	return nil
+}
+
+// CompleteTask records the completion of the task. A verified completion is rewarded with entry, the bonus of
+// the running multiplier campaign and the bonus of the user's tier in the same transaction and is refused when
+// the task's policy doesn't allow the user another one yet. The badges the user earns are awarded with it.
+// Pending and failed completions are only recorded. A completion with an account bound to another user is refused.
+func (u *PostgresRepository) CompleteTask(task *calltypes.Task, completion calltypes.TaskCompletion,
+	entry calltypes.PointTransaction,
+) error {
+	return u.withTx(context.Background(), nil, func(ctx context.Context, tx *sql.Tx) error {
+		// Completions of one user are serialized by the lock on their row.
+		_, err := tx.ExecContext(ctx, `SELECT 1 FROM users WHERE id = $1 FOR UPDATE`, completion.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to lock user: %w", err)
+		}
+
+		now := time.Now()
+
+		if task.Verifier != "" {
+			err = bindProviderAccount(ctx, tx, task.Verifier, completion, now)
+			if err != nil {
+				return err
+			}
+		}
+
+		if completion.Status == consts.VerificationVerified {
+			err = checkCompletionPolicy(ctx, tx, task, completion.UserID, now)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, `INSERT INTO task_completions (user_id, task_id, status, account, detail, completed_at)
+             VALUES ($1, $2, $3, $4, $5, $6)`,
+			completion.UserID, task.ID, completion.Status, completion.Account, completion.Detail, now)
+		if err != nil {
+			return fmt.Errorf("failed to record task completion: %w", err)
+		}
+
+		if completion.Status != consts.VerificationVerified {
+			return nil
+		}
+
+		// Both bonuses are computed from the base entry and the user's state before it.
+		campaignEntry, err := campaignBonus(ctx, tx, entry)
+		if err != nil {
+			return err
+		}
+
+		tierEntry, err := tierBonus(ctx, tx, entry)
+		if err != nil {
+			return err
+		}
+
+		err = u.applyLedgerEntry(ctx, tx, entry)
+		if err != nil {
+			return err
+		}
+
+		for _, bonus := range []*calltypes.PointTransaction{campaignEntry, tierEntry} {
+			if bonus == nil {
+				continue
+			}
+
+			err = u.applyLedgerEntry(ctx, tx, *bonus)
+			if err != nil {
+				return err
+			}
+		}
+
+		return u.awardBadges(ctx, tx, completion.UserID)
+	})
+}
+
+// bindProviderAccount binds the account with the provider to the user of a verified completion and
+// returns errormsg.ErrTaskAccountTaken when the account is already bound to another user.
+func bindProviderAccount(ctx context.Context, tx *sql.Tx, provider string, completion calltypes.TaskCompletion,
+	now time.Time,
+) error {
+	if completion.Status == consts.VerificationVerified {
+		_, err := tx.ExecContext(ctx, `INSERT INTO provider_accounts (provider, account, user_id, bound_at)
+             VALUES ($1, $2, $3, $4) ON CONFLICT (provider, account) DO NOTHING`,
+			provider, completion.Account, completion.UserID, now)
+		if err != nil {
+			return fmt.Errorf("failed to bind provider account: %w", err)
+		}
+	}
+
+	var ownerID int
+
+	err := tx.QueryRowContext(ctx, `SELECT user_id FROM provider_accounts WHERE provider = $1 AND account = $2`,
+		provider, completion.Account).Scan(&ownerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to fetch provider account: %w", err)
+	}
+
+	if ownerID != completion.UserID {
+		return errormsg.ErrTaskAccountTaken
+	}
+
+	return nil
+}
+
+// checkCompletionPolicy returns an error when the task's policy forbids the user to complete it at now.
+func checkCompletionPolicy(ctx context.Context, tx *sql.Tx, task *calltypes.Task, userID int, now time.Time) error {
+	var (
+		since    time.Time
+		limit    int
+		limitErr error
+	)
+
+	// The day starts in the clock completed_at is written in, so it is truncated by the database.
+	window := `$4`
+
+	switch task.Policy {
+	case consts.TaskPolicyOnce:
+		limit, limitErr = 1, errormsg.ErrTaskAlreadyCompleted
+	case consts.TaskPolicyDaily:
+		since, limit, limitErr = now, 1, errormsg.ErrTaskLimitReached
+		window = `date_trunc('day', $4::timestamp)`
+	case consts.TaskPolicyLimited:
+		since = now.Add(-time.Duration(task.PeriodSeconds) * time.Second)
+		limit, limitErr = task.MaxCompletions, errormsg.ErrTaskLimitReached
+	case consts.TaskPolicyUnlimited:
+		if task.CooldownSeconds == 0 {
+			return nil
+		}
+
+		since = now.Add(-time.Duration(task.CooldownSeconds) * time.Second)
+		limit, limitErr = 1, errormsg.ErrTaskOnCooldown
+	default:
+		return fmt.Errorf("%w: %q", errormsg.ErrInvalidTaskPolicy, task.Policy)
+	}
+
+	var completions int
+
+	err := tx.QueryRowContext(ctx, `SELECT count(*) FROM task_completions
+             WHERE user_id = $1 AND task_id = $2 AND status = $3 AND completed_at >= `+window,
+		userID, task.ID, consts.VerificationVerified, since).Scan(&completions)
+	if err != nil {
+		return fmt.Errorf("failed to count task completions: %w", err)
+	}
+
+	if completions >= limit {
+		return limitErr
+	}
+
+	return nil
+}
+
+func scanTask(row rowScanner) (*calltypes.Task, error) {
+	var (
+		task     calltypes.Task
+		startsAt sql.NullTime
+		endsAt   sql.NullTime
+	)
+
+	err := row.Scan(
+		&task.ID,
+		&task.Slug,
+		&task.Title,
+		&task.Description,
+		&task.Reward,
+		&task.Policy,
+		&task.MaxCompletions,
+		&task.PeriodSeconds,
+		&task.CooldownSeconds,
+		&task.Verifier,
+		&task.Active,
+		&startsAt,
+		&endsAt,
+		&task.CreatedAt,
+		&task.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan task: %w", err)
+	}
+
+	if startsAt.Valid {
+		task.StartsAt = &startsAt.Time
+	}
+
+	if endsAt.Valid {
+		task.EndsAt = &endsAt.Time
+	}
+
+	return &task, nil
+}
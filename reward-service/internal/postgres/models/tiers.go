@@ -0,0 +1,271 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"reward-service/api/calltypes"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"strconv"
+	"time"
+)
+
+const tierColumns = `id, name, min_points, multiplier_percent, created_at, updated_at`
+
+// GetTiers returns all tiers from the lowest to the highest.
+func (u *PostgresRepository) GetTiers() ([]*calltypes.Tier, error) {
+	query := `select ` + tierColumns + ` from tiers order by min_points`
+
+	ctx, cancel := context.WithTimeout(context.Background(), consts.DbTimeout)
+	defer cancel()
+
+	rows, err := u.Conn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tiers: %w", err)
+	}
+	defer rows.Close()
+
+	tiers := []*calltypes.Tier{}
+
+	for rows.Next() {
+		var tier *calltypes.Tier
+
+		tier, err = scanTier(rows)
+		if err != nil {
+			log.Printf("Error scanning tier: %v", err)
+
+			return nil, err
+		}
+
+		tiers = append(tiers, tier)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch tiers: %w", err)
+	}
+
+	return tiers, nil
+}
+
+// CreateTier adds new tier.
+func (u *PostgresRepository) CreateTier(tier calltypes.Tier) (int, error) {
+	stmt := `insert into tiers (name, min_points, multiplier_percent, created_at, updated_at)
+             values ($1, $2, $3, $4, $4) returning id`
+
+	var newID int
+
+	err := u.queryRow(context.Background(), stmt, tier.Name, tier.MinPoints, tier.MultiplierPercent, time.Now()).
+		Scan(&newID)
+	if isUniqueViolation(err) {
+		return 0, errormsg.ErrTierExists
+	}
+
+	if err != nil {
+		log.Println("failed to insert new tier: ", err)
+
+		return 0, fmt.Errorf("failed to insert new tier: %w", err)
+	}
+
+	return newID, nil
+}
+
+// UpdateTier updates the tier with the same id. Users move between tiers by the new thresholds
+// without tier events, the events record only changes caused by earned points.
+func (u *PostgresRepository) UpdateTier(tier calltypes.Tier) error {
+	stmt := `update tiers set name = $1, min_points = $2, multiplier_percent = $3, updated_at = $4
+             where id = $5`
+
+	result, err := u.execQuery(context.Background(), stmt,
+		tier.Name,
+		tier.MinPoints,
+		tier.MultiplierPercent,
+		time.Now(),
+		tier.ID,
+	)
+	if isUniqueViolation(err) {
+		return errormsg.ErrTierExists
+	}
+
+	if err != nil {
+		log.Println("failed to update tier: ", err)
+
+		return fmt.Errorf("failed to update tier: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update tier: %w", err)
+	}
+
+	if affected == 0 {
+		return errormsg.ErrTierNotFound
+	}
+
+	return nil
+}
+
+// DeleteTier deletes the tier with provided id, its events keep the user and the points.
+func (u *PostgresRepository) DeleteTier(id int) error {
+	result, err := u.execQuery(context.Background(), `delete from tiers where id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete tier: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete tier: %w", err)
+	}
+
+	if affected == 0 {
+		return errormsg.ErrTierNotFound
+	}
+
+	return nil
+}
+
+// GetTierStatus returns the user's current and next tiers by their lifetime points.
+func (u *PostgresRepository) GetTierStatus(userID int) (*calltypes.TierStatus, error) {
+	var status calltypes.TierStatus
+
+	ctx, cancel := context.WithTimeout(context.Background(), consts.DbTimeout)
+	defer cancel()
+
+	err := u.Conn.QueryRowContext(ctx, `select lifetime_points from users where id = $1`, userID).
+		Scan(&status.LifetimePoints)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errormsg.ErrUserNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch lifetime points: %w", err)
+	}
+
+	status.Current, err = scanOptionalTier(u.Conn.QueryRowContext(ctx, `select `+tierColumns+` from tiers
+              where min_points <= $1 order by min_points desc limit 1`, status.LifetimePoints))
+	if err != nil {
+		return nil, err
+	}
+
+	status.Next, err = scanOptionalTier(u.Conn.QueryRowContext(ctx, `select `+tierColumns+` from tiers
+              where min_points > $1 order by min_points limit 1`, status.LifetimePoints))
+	if err != nil {
+		return nil, err
+	}
+
+	if status.Next != nil {
+		status.PointsToNext = status.Next.MinPoints - status.LifetimePoints
+	}
+
+	return &status, nil
+}
+
+// tierBonus returns the ledger entry of the bonus the user's tier adds to entry, or nil when there is none.
+// The tier is the one reached before the entry is applied.
+func tierBonus(ctx context.Context, tx *sql.Tx, entry calltypes.PointTransaction,
+) (*calltypes.PointTransaction, error) {
+	if entry.Delta <= 0 {
+		return nil, nil //nolint: nilnil
+	}
+
+	var tierID, multiplierPercent int
+
+	err := tx.QueryRowContext(ctx, `SELECT t.id, t.multiplier_percent
+             FROM tiers t JOIN users u ON u.id = $1
+             WHERE t.min_points <= u.lifetime_points
+             ORDER BY t.min_points DESC
+             LIMIT 1`, entry.UserID).Scan(&tierID, &multiplierPercent)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil //nolint: nilnil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user's tier: %w", err)
+	}
+
+	bonus := entry.Delta * (multiplierPercent - 100) / 100 //nolint: mnd
+	if bonus == 0 {
+		return nil, nil //nolint: nilnil
+	}
+
+	return &calltypes.PointTransaction{
+		UserID:      entry.UserID,
+		Delta:       bonus,
+		Reason:      consts.ReasonTierBonus,
+		ReferenceID: strconv.Itoa(tierID),
+		ActorID:     entry.ActorID,
+	}, nil
+}
+
+// raiseLifetimePoints adds the earned entry to the user's lifetime points and records a tier event when the user
+// reaches another tier. Refunds return spent points and are not earnings.
+func raiseLifetimePoints(ctx context.Context, tx *sql.Tx, entry calltypes.PointTransaction, transactionID int,
+	now time.Time,
+) error {
+	if entry.Reason == consts.ReasonRefund {
+		return nil
+	}
+
+	var lifetimePoints int
+
+	err := tx.QueryRowContext(ctx, `UPDATE users SET lifetime_points = lifetime_points + $1 WHERE id = $2
+             RETURNING lifetime_points`, entry.Delta, entry.UserID).Scan(&lifetimePoints)
+	if err != nil {
+		return fmt.Errorf("failed to update lifetime points: %w", err)
+	}
+
+	var fromTierID, toTierID sql.NullInt64
+
+	err = tx.QueryRowContext(ctx, `SELECT
+             (SELECT id FROM tiers WHERE min_points <= $1 ORDER BY min_points DESC LIMIT 1),
+             (SELECT id FROM tiers WHERE min_points <= $2 ORDER BY min_points DESC LIMIT 1)`,
+		lifetimePoints-entry.Delta, lifetimePoints).Scan(&fromTierID, &toTierID)
+	if err != nil {
+		return fmt.Errorf("failed to find user's tier: %w", err)
+	}
+
+	if fromTierID == toTierID {
+		return nil
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO tier_events (user_id, from_tier_id, to_tier_id, lifetime_points,
+             transaction_id, created_at)
+             VALUES ($1, $2, $3, $4, $5, $6)`,
+		entry.UserID, fromTierID, toTierID, lifetimePoints, transactionID, now)
+	if err != nil {
+		return fmt.Errorf("failed to record tier event: %w", err)
+	}
+
+	log.Printf("User %d moved to tier %d with %d lifetime points", entry.UserID, toTierID.Int64, lifetimePoints)
+
+	return nil
+}
+
+func scanOptionalTier(row rowScanner) (*calltypes.Tier, error) {
+	tier, err := scanTier(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil //nolint: nilnil
+	}
+
+	return tier, err
+}
+
+func scanTier(row rowScanner) (*calltypes.Tier, error) {
+	var tier calltypes.Tier
+
+	err := row.Scan(
+		&tier.ID,
+		&tier.Name,
+		&tier.MinPoints,
+		&tier.MultiplierPercent,
+		&tier.CreatedAt,
+		&tier.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan tier: %w", err)
+	}
+
+	return &tier, nil
+}
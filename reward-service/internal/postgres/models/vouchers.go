@@ -0,0 +1,260 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"reward-service/api/calltypes"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"strings"
+	"time"
+)
+
+// AddVouchers adds encrypted voucher codes to the inventory of the digital store item.
+// Codes the item already has are skipped, the number of added codes is returned. Codes are inserted
+// consts.VoucherInsertBatch at a time and the transaction gets consts.VoucherBatchTimeout for every batch,
+// so an upload is added as a whole or not at all.
+func (u *PostgresRepository) AddVouchers(itemID int, vouchers []calltypes.Voucher) (int, error) {
+	var added int
+
+	batches := (len(vouchers) + consts.VoucherInsertBatch - 1) / consts.VoucherInsertBatch
+	timeout := consts.DbTimeout + time.Duration(batches)*consts.VoucherBatchTimeout
+
+	err := u.withTxTimeout(context.Background(), timeout, nil, func(ctx context.Context, tx *sql.Tx) error {
+		var digital bool
+
+		err := tx.QueryRowContext(ctx, `SELECT digital FROM store_items WHERE id = $1 FOR UPDATE`, itemID).
+			Scan(&digital)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errormsg.ErrStoreItemNotFound
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to fetch store item: %w", err)
+		}
+
+		if !digital {
+			return errormsg.ErrStoreItemNotDigital
+		}
+
+		now := time.Now()
+
+		for start := 0; start < len(vouchers); start += consts.VoucherInsertBatch {
+			batch := vouchers[start:min(start+consts.VoucherInsertBatch, len(vouchers))]
+
+			inserted, err := insertVouchers(ctx, tx, itemID, batch, now)
+			if err != nil {
+				return err
+			}
+
+			added += inserted
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return added, nil
+}
+
+// insertVouchers adds the batch of vouchers with one multi-row insert and returns how many were not duplicates.
+func insertVouchers(ctx context.Context, tx *sql.Tx, itemID int, batch []calltypes.Voucher, now time.Time,
+) (int, error) {
+	const columns = 5
+
+	values := make([]string, len(batch))
+	args := make([]any, 0, len(batch)*columns)
+
+	for i, voucher := range batch {
+		n := i * columns
+		values[i] = fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5) //nolint: mnd
+		args = append(args, itemID, voucher.EncryptedCode, voucher.Fingerprint, voucher.FingerprintVersion, now)
+	}
+
+	result, err := tx.ExecContext(ctx, `INSERT INTO vouchers
+             (item_id, code_encrypted, fingerprint, fingerprint_version, created_at)
+             VALUES `+strings.Join(values, ", ")+` ON CONFLICT (item_id, fingerprint) DO NOTHING`, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert vouchers: %w", err)
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert vouchers: %w", err)
+	}
+
+	return int(inserted), nil
+}
+
+// GetVouchers returns the vouchers claimed by the user, newest first. Codes stay encrypted.
+func (u *PostgresRepository) GetVouchers(userID int) ([]*calltypes.Voucher, error) {
+	query := `select v.id, v.item_id, i.title, v.redemption_id, v.code_encrypted, v.claimed_at
+              from vouchers v
+              join redemptions r on r.id = v.redemption_id
+              join store_items i on i.id = v.item_id
+              where r.user_id = $1
+              order by v.claimed_at desc, v.id desc`
+
+	ctx, cancel := context.WithTimeout(context.Background(), consts.DbTimeout)
+	defer cancel()
+
+	rows, err := u.Conn.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch vouchers: %w", err)
+	}
+	defer rows.Close()
+
+	vouchers := []*calltypes.Voucher{}
+
+	for rows.Next() {
+		var voucher calltypes.Voucher
+
+		err := rows.Scan(
+			&voucher.ID,
+			&voucher.ItemID,
+			&voucher.ItemTitle,
+			&voucher.RedemptionID,
+			&voucher.EncryptedCode,
+			&voucher.ClaimedAt,
+		)
+		if err != nil {
+			log.Printf("Error scanning voucher: %v", err)
+
+			return nil, fmt.Errorf("failed to scan voucher: %w", err)
+		}
+
+		vouchers = append(vouchers, &voucher)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch vouchers: %w", err)
+	}
+
+	return vouchers, nil
+}
+
+// RefreshVoucherFingerprints recomputes with fingerprint, which gets the encrypted code, the fingerprints of the
+// vouchers made with a key version older than version. Vouchers are read in pages of consts.VoucherFingerprintBatch
+// by id and every page is updated in its own transaction, so claims are never blocked for long and an interrupted
+// refresh resumes where it stopped. The number of updated vouchers is returned.
+func (u *PostgresRepository) RefreshVoucherFingerprints(version int,
+	fingerprint func(encryptedCode string) (string, error),
+) (int, error) {
+	var (
+		updated int
+		afterID int64
+	)
+
+	for {
+		batch, err := u.staleVoucherFingerprints(version, afterID)
+		if err != nil {
+			return updated, err
+		}
+
+		if len(batch) == 0 {
+			return updated, nil
+		}
+
+		fresh := make([]string, len(batch))
+
+		for i, v := range batch {
+			fresh[i], err = fingerprint(v.encryptedCode)
+			if err != nil {
+				return updated, fmt.Errorf("failed to fingerprint voucher %d: %w", v.id, err)
+			}
+		}
+
+		err = u.withTx(context.Background(), nil, func(ctx context.Context, tx *sql.Tx) error {
+			for i, v := range batch {
+				// A code uploaded again after the key changed already has the fresh fingerprint,
+				// the old copy keeps its own so the item stays unique.
+				_, err := tx.ExecContext(ctx, `UPDATE vouchers v SET fingerprint_version = $1,
+                     fingerprint = CASE WHEN EXISTS (SELECT 1 FROM vouchers d
+                                                     WHERE d.item_id = v.item_id AND d.fingerprint = $2)
+                                        THEN v.fingerprint ELSE $2 END
+                     WHERE v.id = $3`, version, fresh[i], v.id)
+				if err != nil {
+					return fmt.Errorf("failed to update voucher fingerprint: %w", err)
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return updated, err
+		}
+
+		updated += len(batch)
+		afterID = batch[len(batch)-1].id
+	}
+}
+
+type staleVoucher struct {
+	id            int64
+	encryptedCode string
+}
+
+// staleVoucherFingerprints returns the next page of vouchers after afterID fingerprinted with a key version
+// older than version.
+func (u *PostgresRepository) staleVoucherFingerprints(version int, afterID int64) ([]staleVoucher, error) {
+	query := `select id, code_encrypted from vouchers
+              where fingerprint_version < $1 and id > $2
+              order by id limit $3`
+
+	ctx, cancel := context.WithTimeout(context.Background(), consts.DbTimeout)
+	defer cancel()
+
+	rows, err := u.Conn.QueryContext(ctx, query, version, afterID, consts.VoucherFingerprintBatch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch vouchers: %w", err)
+	}
+	defer rows.Close()
+
+	var batch []staleVoucher
+
+	for rows.Next() {
+		var v staleVoucher
+
+		if err := rows.Scan(&v.id, &v.encryptedCode); err != nil {
+			return nil, fmt.Errorf("failed to scan voucher: %w", err)
+		}
+
+		batch = append(batch, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch vouchers: %w", err)
+	}
+
+	return batch, nil
+}
+
+// claimVoucher hands the oldest unclaimed voucher of the item to the redemption.
+// It warns when the inventory of the item falls below consts.VoucherLowStockThreshold.
+func claimVoucher(ctx context.Context, tx *sql.Tx, item *calltypes.StoreItem, redemptionID int, now time.Time,
+) error {
+	var voucherID int
+
+	err := tx.QueryRowContext(ctx, `UPDATE vouchers SET redemption_id = $1, claimed_at = $2
+             WHERE id = (SELECT id FROM vouchers WHERE item_id = $3 AND redemption_id IS NULL
+                         ORDER BY id LIMIT 1 FOR UPDATE SKIP LOCKED)
+             RETURNING id`, redemptionID, now, item.ID).Scan(&voucherID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errormsg.ErrOutOfStock
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to claim voucher: %w", err)
+	}
+
+	if left := *item.VouchersLeft - 1; left < consts.VoucherLowStockThreshold {
+		log.Printf("WARNING: store item %d (%s) has %d vouchers left", item.ID, item.Title, left)
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"reward-service/api/calltypes"
+	"time"
 )
 
 type Repository interface {
@@ -11,9 +12,71 @@ type Repository interface {
 	Update(user calltypes.User) error
 	Insert(user calltypes.User) (int, error)
 	PasswordMatches(plainText string, user calltypes.User) (bool, error)
-	AddPoints(id, point int) error
-	RedeemReferrer(id int, referrer string) error
+	AddPoints(entry calltypes.PointTransaction) error
+	GetTransactions(userID, cursor, limit int) ([]*calltypes.PointTransaction, error)
+	GetTasks(includeUnavailable bool) ([]*calltypes.Task, error)
+	GetTask(slug string) (*calltypes.Task, error)
+	CreateTask(task calltypes.Task) (int, error)
+	UpdateTask(task calltypes.Task) error
+	CompleteTask(task *calltypes.Task, completion calltypes.TaskCompletion, entry calltypes.PointTransaction) error
+	GetSecretCodes() ([]*calltypes.SecretCode, error)
+	CreateSecretCode(code calltypes.SecretCode) (int, error)
+	RedeemSecretCode(codeHash, legacyHash string, entry calltypes.PointTransaction) (*calltypes.SecretCode, error)
+	GetCampaigns() ([]*calltypes.Campaign, error)
+	CreateCampaign(campaign calltypes.Campaign) (int, error)
+	UpdateCampaign(campaign calltypes.Campaign) error
+	DeleteCampaign(id int) error
+	RedeemPromoCode(code string, entry calltypes.PointTransaction) (*calltypes.Campaign, error)
+	GetStoreItems(includeUnavailable bool) ([]*calltypes.StoreItem, error)
+	CreateStoreItem(item calltypes.StoreItem) (int, error)
+	UpdateStoreItem(item calltypes.StoreItem) error
+	RedeemItem(userID, itemID, actorID int) (*calltypes.Redemption, error)
+	GetRedemptions(userID int, status string) ([]*calltypes.Redemption, error)
+	SetRedemptionStatus(id int, status string, actorID int) (*calltypes.Redemption, error)
+	GetExpiringPoints(userID int, before time.Time) (int, error)
+	ExpirePoints(now time.Time) (int, error)
+	GetTiers() ([]*calltypes.Tier, error)
+	CreateTier(tier calltypes.Tier) (int, error)
+	UpdateTier(tier calltypes.Tier) error
+	DeleteTier(id int) error
+	GetTierStatus(userID int) (*calltypes.TierStatus, error)
+	GetBadges() ([]*calltypes.Badge, error)
+	CreateBadge(badge calltypes.Badge) (int, error)
+	UpdateBadge(badge calltypes.Badge) error
+	GetUserBadges(userID int) ([]*calltypes.UserBadge, error)
+	GetStreak(userID int) (*calltypes.Streak, error)
+	CheckIn(userID int, now time.Time) (*calltypes.CheckIn, error)
+	BuyStreakFreeze(userID int) (*calltypes.Streak, error)
+	SetTimezone(userID int, timezone string) error
+	GetLeaderboard(since time.Time, after *calltypes.LeaderboardCursor, limit int, ranking string,
+	) ([]*calltypes.LeaderboardEntry, error)
+	GetRank(userID int, since time.Time, neighbours int, ranking string) (*calltypes.UserRank, error)
+	GetSeasons() ([]*calltypes.Season, error)
+	GetSeason(id int) (*calltypes.Season, error)
+	CreateSeason(season calltypes.Season) (int, error)
+	GetSeasonLeaderboard(season *calltypes.Season, after *calltypes.LeaderboardCursor, limit int, ranking string,
+	) ([]*calltypes.LeaderboardEntry, error)
+	CloseSeasons(now time.Time, ranking string) (int, error)
+	AwardRankBadges(since time.Time, ranking string) (int, error)
+	AddVouchers(itemID int, vouchers []calltypes.Voucher) (int, error)
+	GetVouchers(userID int) ([]*calltypes.Voucher, error)
+	RefreshVoucherFingerprints(version int, fingerprint func(encryptedCode string) (string, error)) (int, error)
+	RedeemReferrer(id int, referrer string, rewards calltypes.ReferralRewards) error
+	GetReferralStats(userID, depth int) (*calltypes.ReferralStats, error)
 	EmailCheck(email string) (*calltypes.User, error)
 	UpdateScore(user calltypes.User) error
-	StoreRefreshToken(userID int, hashedToken string) error
+	SetRole(userID int, role string) error
+	EnsureAdmin(email, password string) (int, error)
+	CreateSession(session calltypes.Session, refreshToken calltypes.RefreshToken) (int, error)
+	RotateSession(oldToken, newToken calltypes.RefreshToken, userAgent, ip string) (*calltypes.Session, error)
+	GetSessions(userID int) ([]*calltypes.Session, error)
+	RevokeSession(userID, sessionID int) error
+	RevokeSessionByToken(refreshToken calltypes.RefreshToken) error
+	RevokeAllSessions(userID int) error
+	RevokeAccessToken(tokenID string, userID int, expiresAt time.Time) error
+	IsAccessTokenRevoked(tokenID string, userID, sessionID int, issuedAt time.Time) (bool, error)
+	BeginIdempotentRequest(userID int, key, fingerprint string) (*calltypes.IdempotentResponse, error)
+	CompleteIdempotentRequest(userID int, key string, response calltypes.IdempotentResponse) error
+	PurgeIdempotencyKeys(before time.Time) (int, error)
+	ReleaseIdempotentRequest(userID int, key string) error
 }
@@ -0,0 +1,81 @@
+// Package referral generates and validates referral codes.
+package referral
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"strings"
+)
+
+// codeAlphabet leaves out characters that are easy to confuse with each other: 0/O, 1/I/L.
+const codeAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
+// blockedWords can't be a part of a vanity code.
+var blockedWords = []string{
+	"ADMIN",
+	"MODERATOR",
+	"SUPPORT",
+	"OFFICIAL",
+	"STAFF",
+	"FUCK",
+	"SHIT",
+	"BITCH",
+	"CUNT",
+	"NIGGER",
+	"FAGGOT",
+	"WHORE",
+}
+
+// GenerateCode returns a random code of consts.ReferralCodeLength characters from the unambiguous alphabet.
+func GenerateCode() (string, error) {
+	code := make([]byte, consts.ReferralCodeLength)
+	alphabetSize := big.NewInt(int64(len(codeAlphabet)))
+
+	for i := range code {
+		n, err := rand.Int(rand.Reader, alphabetSize)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate referral code: %w", err)
+		}
+
+		code[i] = codeAlphabet[n.Int64()]
+	}
+
+	return string(code), nil
+}
+
+// Normalize brings a code entered by a user to the form codes are stored in.
+func Normalize(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// ValidateVanityCode normalizes a code chosen by a user and checks it against the rules:
+// allowed length, latin letters, digits and inner hyphens only, no blocked words.
+func ValidateVanityCode(code string) (string, error) {
+	code = Normalize(code)
+
+	if len(code) < consts.VanityCodeMinLength || len(code) > consts.VanityCodeMaxLength {
+		return "", errormsg.ErrInvalidReferralCode
+	}
+
+	if strings.HasPrefix(code, "-") || strings.HasSuffix(code, "-") || strings.Contains(code, "--") {
+		return "", errormsg.ErrInvalidReferralCode
+	}
+
+	for _, c := range code {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '-' {
+			return "", errormsg.ErrInvalidReferralCode
+		}
+	}
+
+	compact := strings.ReplaceAll(code, "-", "")
+	for _, word := range blockedWords {
+		if strings.Contains(compact, word) {
+			return "", errormsg.ErrInvalidReferralCode
+		}
+	}
+
+	return code, nil
+}
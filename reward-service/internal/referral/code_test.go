@@ -0,0 +1,66 @@
+package referral_test
+
+import (
+	"reward-service/internal/referral"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateCode(t *testing.T) {
+	t.Parallel()
+
+	seen := make(map[string]struct{})
+
+	for range 100 {
+		code, err := referral.GenerateCode()
+		require.NoError(t, err)
+		assert.Len(t, code, consts.ReferralCodeLength)
+		assert.False(t, strings.ContainsAny(code, "01OIL"), "code %q contains ambiguous characters", code)
+
+		seen[code] = struct{}{}
+	}
+
+	assert.Len(t, seen, 100)
+}
+
+func TestValidateVanityCode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		code     string
+		expected string
+		err      error
+	}{
+		{name: "valid code", code: "summer-2025", expected: "SUMMER-2025"},
+		{name: "surrounding spaces are trimmed", code: "  alice  ", expected: "ALICE"},
+		{name: "too short", code: "abc", err: errormsg.ErrInvalidReferralCode},
+		{name: "too long", code: strings.Repeat("a", consts.VanityCodeMaxLength+1), err: errormsg.ErrInvalidReferralCode},
+		{name: "forbidden characters", code: "hello world", err: errormsg.ErrInvalidReferralCode},
+		{name: "leading hyphen", code: "-alice", err: errormsg.ErrInvalidReferralCode},
+		{name: "double hyphen", code: "al--ice", err: errormsg.ErrInvalidReferralCode},
+		{name: "blocked word", code: "the-admin", err: errormsg.ErrInvalidReferralCode},
+		{name: "blocked word split by hyphens", code: "ad-min-1", err: errormsg.ErrInvalidReferralCode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			code, err := referral.ValidateVanityCode(tt.code)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, code)
+		})
+	}
+}
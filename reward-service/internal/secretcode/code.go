@@ -0,0 +1,58 @@
+// Package secretcode validates and hashes the codes of secret-code quests.
+package secretcode
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"strings"
+)
+
+// Hasher hashes codes with HMAC-SHA256 under a server-side key, so the stored hashes can't be
+// brute-forced without the key.
+type Hasher struct {
+	key []byte
+}
+
+// NewHasher creates hasher from a key of at least 32 bytes.
+func NewHasher(key []byte) (*Hasher, error) {
+	if len(key) < 32 { //nolint: mnd
+		return nil, errormsg.ErrInvalidSecretCodeKey
+	}
+
+	return &Hasher{key: key}, nil
+}
+
+// Normalize brings a code entered by a user to the form codes are hashed in.
+func Normalize(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Hash returns the hex encoded HMAC-SHA256 of the normalized code, only hashes of the codes are stored.
+func (h *Hasher) Hash(code string) string {
+	mac := hmac.New(sha256.New, h.key)
+	mac.Write([]byte(Normalize(code)))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// LegacyHash returns the hex encoded sha256 of the normalized code. Quests created before the hashes
+// were keyed are found by it and rehashed on their first redemption.
+func LegacyHash(code string) string {
+	sum := sha256.Sum256([]byte(Normalize(code)))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Validate checks that the code chosen by an admin is long enough to resist guessing.
+func Validate(code string) error {
+	code = Normalize(code)
+
+	if len(code) < consts.SecretCodeMinLength || len(code) > consts.SecretCodeMaxLength {
+		return errormsg.ErrInvalidSecretCode
+	}
+
+	return nil
+}
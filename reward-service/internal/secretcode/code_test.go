@@ -0,0 +1,62 @@
+package secretcode_test
+
+import (
+	"bytes"
+	"reward-service/internal/secretcode"
+	"reward-service/pkg/errormsg"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasher(t *testing.T) {
+	t.Parallel()
+
+	hasher, err := secretcode.NewHasher(bytes.Repeat([]byte{7}, 32))
+	require.NoError(t, err)
+
+	assert.Equal(t, hasher.Hash("KUARHODRON"), hasher.Hash("  kuarhodron "))
+	assert.NotEqual(t, hasher.Hash("KUARHODRON"), hasher.Hash("KUARHODRONS"))
+	assert.NotEqual(t, secretcode.LegacyHash("KUARHODRON"), hasher.Hash("KUARHODRON"))
+
+	other, err := secretcode.NewHasher(bytes.Repeat([]byte{8}, 32))
+	require.NoError(t, err)
+	assert.NotEqual(t, hasher.Hash("KUARHODRON"), other.Hash("KUARHODRON"), "hashes depend on the key")
+
+	_, err = secretcode.NewHasher([]byte("short"))
+	require.ErrorIs(t, err, errormsg.ErrInvalidSecretCodeKey)
+}
+
+func TestLegacyHash(t *testing.T) {
+	t.Parallel()
+
+	// Matches encode(sha256('KUARHODRON'::bytea), 'hex') the migration seeds the first quest with.
+	expected := "066d200ebc6d48ee69f5b81c7cef494cf3f7d4c89bb256f847706a251aed29e3"
+
+	assert.Equal(t, expected, secretcode.LegacyHash("KUARHODRON"))
+	assert.Equal(t, expected, secretcode.LegacyHash("  kuarhodron "))
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		code string
+		err  error
+	}{
+		{name: "valid code", code: "water-password"},
+		{name: "too short", code: " abcdefg ", err: errormsg.ErrInvalidSecretCode},
+		{name: "too long", code: strings.Repeat("a", 65), err: errormsg.ErrInvalidSecretCode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.ErrorIs(t, secretcode.Validate(tt.code), tt.err)
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reward-service/api/calltypes"
+	"reward-service/api/server/httputils"
+	"reward-service/api/server/middleware"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+)
+
+// ListUsers godoc
+// @Summary List users
+// @Description Returns all users including their emails and roles. Available to moderators and admins.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} calltypes.JSONResponse{data=[]calltypes.User}
+// @Failure 403 {object} calltypes.ErrorResponse "Insufficient role"
+// @Failure 500 {object} calltypes.ErrorResponse "Failed to fetch users"
+// @Router /admin/users [get].
+func (s *RewardService) ListUsers(w http.ResponseWriter, _ *http.Request) {
+	users, err := s.Repo.GetAll()
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrFetchUsers, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: "Fetched all users",
+		Data:    users,
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// SetUserRole godoc
+// @Summary Change user's role
+// @Description Sets the role of the user. Sessions of the user are revoked so that the new role applies immediately.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body calltypes.RoleRequest true "New role"
+// @Success 200 {object} calltypes.JSONResponse
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid role or user ID"
+// @Failure 403 {object} calltypes.ErrorResponse "Insufficient role"
+// @Failure 404 {object} calltypes.ErrorResponse "User not found"
+// @Router /admin/users/{id}/role [put].
+func (s *RewardService) SetUserRole(w http.ResponseWriter, r *http.Request) {
+	var requestPayload struct {
+		Role string `json:"role"`
+	}
+
+	id, err := GetIDFromURL(r, "id")
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidID, http.StatusBadRequest)
+
+		return
+	}
+
+	err = httputils.ReadJSON(w, r, &requestPayload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	if !isValidRole(requestPayload.Role) {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidRole, http.StatusBadRequest)
+
+		return
+	}
+
+	if callerID, _ := middleware.UserIDFromContext(r.Context()); callerID == id {
+		httputils.ErrorJSON(w, errormsg.ErrChangeOwnRole, http.StatusBadRequest)
+
+		return
+	}
+
+	err = s.Repo.SetRole(id, requestPayload.Role)
+	if err != nil {
+		if errors.Is(err, errormsg.ErrUserNotFound) {
+			httputils.ErrorJSON(w, errormsg.ErrUserNotFound, http.StatusNotFound)
+
+			return
+		}
+
+		httputils.ErrorJSON(w, errormsg.ErrSetRole, http.StatusInternalServerError)
+
+		return
+	}
+
+	if err := s.Repo.RevokeAllSessions(id); err != nil {
+		log.Printf("failed to revoke sessions of user %d after role change: %v", id, err)
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: fmt.Sprintf("User %d now has role %s", id, requestPayload.Role),
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+func isValidRole(role string) bool {
+	switch role {
+	case consts.RoleUser, consts.RoleModerator, consts.RoleAdmin:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,89 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reward-service/api/server/middleware"
+	"reward-service/internal/service"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRewardService_SetUserRole(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		urlID          string
+		requestBody    string
+		mockSetup      func(*MockRepository)
+		expectedStatus int
+	}{
+		{
+			name:        "Promote user",
+			urlID:       "5",
+			requestBody: `{"role": "moderator"}`,
+			mockSetup: func(m *MockRepository) {
+				m.On("SetRole", 5, consts.RoleModerator).Return(nil)
+				m.On("RevokeAllSessions", 5).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Unknown role",
+			urlID:          "5",
+			requestBody:    `{"role": "superuser"}`,
+			mockSetup:      func(_ *MockRepository) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Own role",
+			urlID:          "1",
+			requestBody:    `{"role": "user"}`,
+			mockSetup:      func(_ *MockRepository) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "User not found",
+			urlID:       "9",
+			requestBody: `{"role": "admin"}`,
+			mockSetup: func(m *MockRepository) {
+				m.On("SetRole", 9, consts.RoleAdmin).Return(errormsg.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(MockRepository)
+			tt.mockSetup(mockRepo)
+
+			svc := service.NewRewardService(mockRepo)
+
+			req := httptest.NewRequest(http.MethodPut, "/admin/users/"+tt.urlID+"/role", strings.NewReader(tt.requestBody))
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.urlID)
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			ctx = middleware.WithIdentity(ctx, middleware.Identity{UserID: 1, Role: consts.RoleAdmin})
+			req = req.WithContext(ctx)
+
+			rr := httptest.NewRecorder()
+
+			svc.SetUserRole(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,390 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"reward-service/api/calltypes"
+	"reward-service/api/server/httputils"
+	"reward-service/api/server/middleware"
+	"reward-service/internal/token"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"time"
+)
+
+// Refresh godoc
+// @Summary Refresh auth tokens
+// @Description Exchanges the refresh token cookie for a new access and refresh token pair.
+// @Description The presented refresh token is invalidated. Presenting an already rotated token revokes its whole session.
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} calltypes.JSONResponse
+// @Header 200 {string} Set-Cookie "accessToken"
+// @Header 200 {string} Set-Cookie "refreshToken"
+// @Failure 401 {object} calltypes.ErrorResponse "Missing, invalid or reused refresh token"
+// @Router /refresh [post].
+func (s *RewardService) Refresh(w http.ResponseWriter, r *http.Request) {
+	refreshCookie, err := r.Cookie("refreshToken")
+	if err != nil || refreshCookie.Value == "" {
+		httputils.ErrorJSON(w, errormsg.ErrMissingRefreshToken, http.StatusUnauthorized)
+
+		return
+	}
+
+	tokenService := token.NewTokenService()
+
+	oldRefreshToken, err := tokenService.HashRefreshToken(refreshCookie.Value)
+	if err != nil {
+		clearAuthCookies(w)
+		httputils.ErrorJSON(w, errormsg.ErrInvalidRefreshToken, http.StatusUnauthorized)
+
+		return
+	}
+
+	newRefreshToken, err := token.GenerateRefreshToken()
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusInternalServerError)
+
+		return
+	}
+
+	hashedRefreshToken, err := tokenService.HashRefreshToken(newRefreshToken)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusInternalServerError)
+
+		return
+	}
+
+	session, err := s.Repo.RotateSession(oldRefreshToken, hashedRefreshToken, r.UserAgent(), clientIP(r))
+	if err != nil {
+		clearAuthCookies(w)
+
+		if errors.Is(err, errormsg.ErrRefreshTokenReused) {
+			if err := s.Repo.RevokeSession(session.UserID, session.ID); err != nil {
+				log.Printf("failed to revoke session %d of user %d: %v", session.ID, session.UserID, err)
+			}
+
+			httputils.ErrorJSON(w, errormsg.ErrRefreshTokenReused, http.StatusUnauthorized)
+
+			return
+		}
+
+		httputils.ErrorJSON(w, errormsg.ErrInvalidRefreshToken, http.StatusUnauthorized)
+
+		return
+	}
+
+	accessToken, err := tokenService.GenerateAccessToken(session.UserID, session.ID, session.UserRole)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusInternalServerError)
+
+		return
+	}
+
+	setAuthCookies(w, accessToken, newRefreshToken)
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: "Tokens refreshed",
+		Data:    map[string]interface{}{"user_id": session.UserID},
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// Logout godoc
+// @Summary Log out
+// @Description Ends the current session, revokes its access token and clears auth cookies
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} calltypes.JSONResponse
+// @Failure 500 {object} calltypes.ErrorResponse "Failed to revoke session"
+// @Router /logout [post].
+func (s *RewardService) Logout(w http.ResponseWriter, r *http.Request) {
+	tokenService := token.NewTokenService()
+
+	if refreshCookie, err := r.Cookie("refreshToken"); err == nil {
+		if hashed, err := tokenService.HashRefreshToken(refreshCookie.Value); err == nil {
+			if err := s.Repo.RevokeSessionByToken(hashed); err != nil {
+				log.Println("failed to revoke refresh token: ", err)
+				httputils.ErrorJSON(w, errormsg.ErrRevokeSessions, http.StatusInternalServerError)
+
+				return
+			}
+		}
+	}
+
+	if err := s.revokeAccessCookie(r); err != nil {
+		log.Println("failed to revoke access token: ", err)
+		httputils.ErrorJSON(w, errormsg.ErrRevokeSessions, http.StatusInternalServerError)
+
+		return
+	}
+
+	clearAuthCookies(w)
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: "Logged out",
+	}
+
+	err := httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// LogoutAll godoc
+// @Summary Log out everywhere
+// @Description Revokes every session of the current user, including access tokens that have not expired yet
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} calltypes.JSONResponse
+// @Failure 401 {object} calltypes.ErrorResponse "Unauthorized"
+// @Failure 500 {object} calltypes.ErrorResponse "Failed to revoke sessions"
+// @Router /logout-all [post].
+func (s *RewardService) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidID, http.StatusUnauthorized)
+
+		return
+	}
+
+	if err := s.Repo.RevokeAllSessions(userID); err != nil {
+		log.Printf("failed to revoke sessions of user %d: %v", userID, err)
+		httputils.ErrorJSON(w, errormsg.ErrRevokeSessions, http.StatusInternalServerError)
+
+		return
+	}
+
+	if err := s.revokeAccessCookie(r); err != nil {
+		log.Println("failed to revoke access token: ", err)
+	}
+
+	clearAuthCookies(w)
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: "Logged out from all sessions",
+	}
+
+	err := httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// GetSessions godoc
+// @Summary List sessions
+// @Description Returns active sessions (signed-in devices) of the current user
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} calltypes.JSONResponse{data=[]calltypes.Session}
+// @Failure 401 {object} calltypes.ErrorResponse "Unauthorized"
+// @Failure 500 {object} calltypes.ErrorResponse "Failed to fetch sessions"
+// @Router /users/me/sessions [get].
+func (s *RewardService) GetSessions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidID, http.StatusUnauthorized)
+
+		return
+	}
+
+	identity, _ := middleware.IdentityFromContext(r.Context())
+
+	sessions, err := s.Repo.GetSessions(userID)
+	if err != nil {
+		log.Printf("failed to fetch sessions of user %d: %v", userID, err)
+		httputils.ErrorJSON(w, errormsg.ErrFetchSessions, http.StatusInternalServerError)
+
+		return
+	}
+
+	for _, session := range sessions {
+		session.Current = session.ID == identity.SessionID
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: "Fetched sessions",
+		Data:    sessions,
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// RevokeSession godoc
+// @Summary Revoke session
+// @Description Ends one session of the current user, its access tokens stop working immediately
+// @Tags Auth
+// @Produce json
+// @Param id path int true "Session ID"
+// @Success 200 {object} calltypes.JSONResponse
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid session ID"
+// @Failure 404 {object} calltypes.ErrorResponse "Session not found"
+// @Router /users/me/sessions/{id} [delete].
+func (s *RewardService) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidID, http.StatusUnauthorized)
+
+		return
+	}
+
+	sessionID, err := GetIDFromURL(r, "id")
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidID, http.StatusBadRequest)
+
+		return
+	}
+
+	err = s.Repo.RevokeSession(userID, sessionID)
+	if err != nil {
+		if errors.Is(err, errormsg.ErrSessionNotFound) {
+			httputils.ErrorJSON(w, errormsg.ErrSessionNotFound, http.StatusNotFound)
+
+			return
+		}
+
+		log.Printf("failed to revoke session %d of user %d: %v", sessionID, userID, err)
+		httputils.ErrorJSON(w, errormsg.ErrRevokeSessions, http.StatusInternalServerError)
+
+		return
+	}
+
+	if identity, _ := middleware.IdentityFromContext(r.Context()); identity.SessionID == sessionID {
+		clearAuthCookies(w)
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: fmt.Sprintf("Session %d revoked", sessionID),
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// startSession creates a new session for the user and issues its access and refresh tokens.
+func (s *RewardService) startSession(r *http.Request, userID int, role string) (string, string, error) {
+	tokenService := token.NewTokenService()
+
+	refreshToken, err := token.GenerateRefreshToken()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	hashedRefreshToken, err := tokenService.HashRefreshToken(refreshToken)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to hash refresh token: %w", err)
+	}
+
+	session := calltypes.Session{
+		UserID:    userID,
+		UserAgent: r.UserAgent(),
+		IP:        clientIP(r),
+	}
+
+	sessionID, err := s.Repo.CreateSession(session, hashedRefreshToken)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create session: %w", err)
+	}
+
+	accessToken, err := tokenService.GenerateAccessToken(userID, sessionID, role)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	return accessToken, refreshToken, nil
+}
+
+// clientIP returns the address the request came from without the port.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// revokeAccessCookie puts a still valid access token from the request cookies on the denylist.
+func (s *RewardService) revokeAccessCookie(r *http.Request) error {
+	accessCookie, err := r.Cookie("accessToken")
+	if err != nil {
+		return nil //nolint: nilerr
+	}
+
+	claims, err := token.NewTokenService().ValidateAccessToken(accessCookie.Value)
+	if err != nil {
+		return nil //nolint: nilerr
+	}
+
+	accessClaims, err := token.ParseAccessClaims(claims)
+	if err != nil {
+		return nil //nolint: nilerr
+	}
+
+	return s.Repo.RevokeAccessToken(accessClaims.TokenID, accessClaims.UserID, accessClaims.ExpiresAt) //nolint: wrapcheck
+}
+
+// setAuthCookies sets access and refresh token cookies.
+func setAuthCookies(w http.ResponseWriter, accessToken, refreshToken string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "accessToken",
+		Value:    accessToken,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   false,
+		SameSite: http.SameSiteStrictMode,
+		Expires:  time.Now().Add(consts.AccessTokenExpireTime),
+	})
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     "refreshToken",
+		Value:    refreshToken,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   false,
+		SameSite: http.SameSiteStrictMode,
+		Expires:  time.Now().Add(consts.RefreshTokenExpireTime),
+	})
+}
+
+// clearAuthCookies tells the client to drop access and refresh token cookies.
+func clearAuthCookies(w http.ResponseWriter) {
+	for _, name := range []string{"accessToken", "refreshToken"} {
+		http.SetCookie(w, &http.Cookie{
+			Name:     name,
+			Value:    "",
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   false,
+			SameSite: http.SameSiteStrictMode,
+			MaxAge:   -1,
+		})
+	}
+}
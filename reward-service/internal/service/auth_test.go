@@ -0,0 +1,277 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reward-service/api/calltypes"
+	"reward-service/api/server/middleware"
+	"reward-service/internal/service"
+	"reward-service/internal/token"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewardService_Refresh(t *testing.T) {
+	t.Parallel()
+
+	presented := "selector.verifier"
+
+	hashed, err := token.NewTokenService().HashRefreshToken(presented)
+	require.NoError(t, err)
+
+	matchesPresented := mock.MatchedBy(func(rt calltypes.RefreshToken) bool {
+		return rt == hashed
+	})
+
+	tests := []struct {
+		name           string
+		cookie         *http.Cookie
+		mockSetup      func(*MockRepository)
+		expectedStatus int
+		expectTokens   bool
+	}{
+		{
+			name:   "Successful rotation",
+			cookie: &http.Cookie{Name: "refreshToken", Value: presented},
+			mockSetup: func(m *MockRepository) {
+				m.On("RotateSession", matchesPresented, mock.AnythingOfType("calltypes.RefreshToken"), mock.Anything, mock.Anything).
+					Return(&calltypes.Session{ID: 3, UserID: 1}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectTokens:   true,
+		},
+		{
+			name:           "Missing cookie",
+			cookie:         nil,
+			mockSetup:      func(_ *MockRepository) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Malformed token",
+			cookie:         &http.Cookie{Name: "refreshToken", Value: "no-selector"},
+			mockSetup:      func(_ *MockRepository) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "Unknown token",
+			cookie: &http.Cookie{Name: "refreshToken", Value: presented},
+			mockSetup: func(m *MockRepository) {
+				m.On("RotateSession", matchesPresented, mock.AnythingOfType("calltypes.RefreshToken"), mock.Anything, mock.Anything).
+					Return(nil, errormsg.ErrInvalidRefreshToken)
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "Reused token revokes its session",
+			cookie: &http.Cookie{Name: "refreshToken", Value: presented},
+			mockSetup: func(m *MockRepository) {
+				m.On("RotateSession", matchesPresented, mock.AnythingOfType("calltypes.RefreshToken"), mock.Anything, mock.Anything).
+					Return(&calltypes.Session{ID: 4, UserID: 7}, errormsg.ErrRefreshTokenReused)
+				m.On("RevokeSession", 7, 4).Return(nil)
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(MockRepository)
+			tt.mockSetup(mockRepo)
+
+			svc := service.NewRewardService(mockRepo)
+
+			req := httptest.NewRequest(http.MethodPost, "/refresh", nil)
+			if tt.cookie != nil {
+				req.AddCookie(tt.cookie)
+			}
+
+			rr := httptest.NewRecorder()
+
+			svc.Refresh(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.expectTokens {
+				cookies := rr.Result().Cookies()
+				assert.Len(t, cookies, 2)
+				assert.Equal(t, "accessToken", cookies[0].Name)
+				assert.Equal(t, "refreshToken", cookies[1].Name)
+				assert.NotEqual(t, presented, cookies[1].Value)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRewardService_Logout(t *testing.T) {
+	t.Parallel()
+
+	accessToken, err := token.NewTokenService().GenerateAccessToken(5, 2, consts.RoleUser)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		cookies        []*http.Cookie
+		mockSetup      func(*MockRepository)
+		expectedStatus int
+	}{
+		{
+			name: "Revokes both tokens",
+			cookies: []*http.Cookie{
+				{Name: "accessToken", Value: accessToken},
+				{Name: "refreshToken", Value: "selector.verifier"},
+			},
+			mockSetup: func(m *MockRepository) {
+				m.On("RevokeSessionByToken", mock.AnythingOfType("calltypes.RefreshToken")).Return(nil)
+				m.On("RevokeAccessToken", mock.AnythingOfType("string"), 5, mock.AnythingOfType("time.Time")).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Without cookies",
+			cookies:        nil,
+			mockSetup:      func(_ *MockRepository) {},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:    "Repository error",
+			cookies: []*http.Cookie{{Name: "refreshToken", Value: "selector.verifier"}},
+			mockSetup: func(m *MockRepository) {
+				m.On("RevokeSessionByToken", mock.AnythingOfType("calltypes.RefreshToken")).Return(errormsg.ErrRepositoryError)
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(MockRepository)
+			tt.mockSetup(mockRepo)
+
+			svc := service.NewRewardService(mockRepo)
+
+			req := httptest.NewRequest(http.MethodPost, "/logout", nil)
+			for _, cookie := range tt.cookies {
+				req.AddCookie(cookie)
+			}
+
+			rr := httptest.NewRecorder()
+
+			svc.Logout(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				for _, cookie := range rr.Result().Cookies() {
+					assert.Empty(t, cookie.Value)
+					assert.Negative(t, cookie.MaxAge)
+				}
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRewardService_GetSessions(t *testing.T) {
+	t.Parallel()
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetSessions", 5).Return([]*calltypes.Session{{ID: 1}, {ID: 2}}, nil)
+
+	svc := service.NewRewardService(mockRepo)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/me/sessions", nil)
+	req = req.WithContext(middleware.WithIdentity(req.Context(), middleware.Identity{UserID: 5, SessionID: 2}))
+
+	rr := httptest.NewRecorder()
+
+	svc.GetSessions(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response struct {
+		Data []calltypes.Session `json:"data"`
+	}
+
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	require.Len(t, response.Data, 2)
+	assert.False(t, response.Data[0].Current)
+	assert.True(t, response.Data[1].Current)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRewardService_RevokeSession(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		sessionID      string
+		mockSetup      func(*MockRepository)
+		expectedStatus int
+	}{
+		{
+			name:      "Successful revoke",
+			sessionID: "3",
+			mockSetup: func(m *MockRepository) {
+				m.On("RevokeSession", 5, 3).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:      "Foreign or unknown session",
+			sessionID: "4",
+			mockSetup: func(m *MockRepository) {
+				m.On("RevokeSession", 5, 4).Return(errormsg.ErrSessionNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Invalid ID",
+			sessionID:      "abc",
+			mockSetup:      func(_ *MockRepository) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(MockRepository)
+			tt.mockSetup(mockRepo)
+
+			svc := service.NewRewardService(mockRepo)
+
+			req := httptest.NewRequest(http.MethodDelete, "/users/me/sessions/"+tt.sessionID, nil)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.sessionID)
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			ctx = middleware.WithIdentity(ctx, middleware.Identity{UserID: 5})
+			req = req.WithContext(ctx)
+
+			rr := httptest.NewRecorder()
+
+			svc.RevokeSession(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
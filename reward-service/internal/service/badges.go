@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reward-service/api/calltypes"
+	"reward-service/api/server/httputils"
+	"reward-service/internal/badge"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"time"
+)
+
+// GetUserBadges godoc
+// @Summary Get user's badges
+// @Description Returns the badges awarded to the user, newest first
+// @Tags Users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} calltypes.JSONResponse{data=[]calltypes.UserBadge}
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid user ID"
+// @Failure 500 {object} calltypes.ErrorResponse "Failed to fetch badges"
+// @Router /users/{id}/badges [get].
+func (s *RewardService) GetUserBadges(w http.ResponseWriter, r *http.Request) {
+	id, err := GetIDFromURL(r, "id")
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidID, http.StatusBadRequest)
+
+		return
+	}
+
+	badges, err := s.Repo.GetUserBadges(id)
+	if err != nil {
+		log.Printf("failed to fetch badges of user %d: %v", id, err)
+		httputils.ErrorJSON(w, errormsg.ErrFetchBadges, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: fmt.Sprintf("Fetched badges of user with id %d", id),
+		Data:    badges,
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// ListBadges godoc
+// @Summary List badges
+// @Description Returns all badges with their rules including inactive ones. Available to admins.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} calltypes.JSONResponse{data=[]calltypes.Badge}
+// @Failure 403 {object} calltypes.ErrorResponse "Insufficient role"
+// @Failure 500 {object} calltypes.ErrorResponse "Failed to fetch badges"
+// @Router /admin/badges [get].
+func (s *RewardService) ListBadges(w http.ResponseWriter, _ *http.Request) {
+	badges, err := s.Repo.GetBadges()
+	if err != nil {
+		log.Printf("failed to fetch badges: %v", err)
+		httputils.ErrorJSON(w, errormsg.ErrFetchBadges, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: "Fetched badges",
+		Data:    badges,
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// CreateBadge godoc
+// @Summary Create badge
+// @Description Adds new badge. Users who already meet its rule get it with their next points, task or referral.
+// @Description Available to admins.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body calltypes.BadgeRequest true "Badge"
+// @Success 201 {object} calltypes.JSONResponse
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid badge"
+// @Failure 403 {object} calltypes.ErrorResponse "Insufficient role"
+// @Failure 409 {object} calltypes.ErrorResponse "Slug is taken"
+// @Router /admin/badges [post].
+func (s *RewardService) CreateBadge(w http.ResponseWriter, r *http.Request) {
+	var requestPayload calltypes.BadgeRequest
+
+	err := httputils.ReadJSON(w, r, &requestPayload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	b, err := badgeFromRequest(requestPayload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	id, err := s.Repo.CreateBadge(b)
+	if errors.Is(err, errormsg.ErrBadgeExists) {
+		httputils.ErrorJSON(w, err, http.StatusConflict)
+
+		return
+	}
+
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrSaveBadge, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: fmt.Sprintf("Created badge %s, id: %d", b.Slug, id),
+	}
+
+	err = httputils.WriteJSON(w, http.StatusCreated, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// UpdateBadge godoc
+// @Summary Update badge
+// @Description Replaces the badge with provided id, users keep the badge and its reward. Available to admins.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Badge ID"
+// @Param request body calltypes.BadgeRequest true "Badge"
+// @Success 200 {object} calltypes.JSONResponse
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid badge"
+// @Failure 403 {object} calltypes.ErrorResponse "Insufficient role"
+// @Failure 404 {object} calltypes.ErrorResponse "Badge not found"
+// @Failure 409 {object} calltypes.ErrorResponse "Slug is taken"
+// @Router /admin/badges/{id} [put].
+func (s *RewardService) UpdateBadge(w http.ResponseWriter, r *http.Request) {
+	id, err := GetIDFromURL(r, "id")
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidID, http.StatusBadRequest)
+
+		return
+	}
+
+	var requestPayload calltypes.BadgeRequest
+
+	err = httputils.ReadJSON(w, r, &requestPayload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	b, err := badgeFromRequest(requestPayload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	b.ID = id
+
+	err = s.Repo.UpdateBadge(b)
+
+	switch {
+	case errors.Is(err, errormsg.ErrBadgeNotFound):
+		httputils.ErrorJSON(w, err, http.StatusNotFound)
+
+		return
+	case errors.Is(err, errormsg.ErrBadgeExists):
+		httputils.ErrorJSON(w, err, http.StatusConflict)
+
+		return
+	case err != nil:
+		httputils.ErrorJSON(w, errormsg.ErrSaveBadge, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: "Updated badge " + b.Slug,
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// RunRankBadges awards the rank badges earned on the monthly leaderboard every interval until ctx is done.
+func (s *RewardService) RunRankBadges(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.awardRankBadges()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *RewardService) awardRankBadges() {
+	since, err := periodStart(consts.LeaderboardPeriodMonth, time.Now())
+	if err != nil {
+		log.Printf("failed to award rank badges: %v", err)
+
+		return
+	}
+
+	awarded, err := s.Repo.AwardRankBadges(since, s.Config.Ranking)
+	if err != nil {
+		// Users who were not awarded are picked up by the next run.
+		log.Printf("failed to award rank badges: %v", err)
+	}
+
+	if awarded > 0 {
+		log.Printf("Awarded %d rank badges", awarded)
+	}
+}
+
+// badgeFromRequest validates the request, slugs follow the rules of task slugs.
+func badgeFromRequest(request calltypes.BadgeRequest) (calltypes.Badge, error) {
+	if !isValidTaskSlug(request.Slug) || request.Name == "" || request.Reward < 0 {
+		return calltypes.Badge{}, errormsg.ErrInvalidBadge
+	}
+
+	err := badge.Validate(request.Metric, request.Threshold)
+	if err != nil {
+		return calltypes.Badge{}, err //nolint: wrapcheck
+	}
+
+	return calltypes.Badge{
+		Slug:        request.Slug,
+		Name:        request.Name,
+		Description: request.Description,
+		Metric:      request.Metric,
+		Threshold:   request.Threshold,
+		Reward:      request.Reward,
+		Active:      request.Active == nil || *request.Active,
+	}, nil
+}
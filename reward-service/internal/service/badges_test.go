@@ -0,0 +1,123 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reward-service/api/calltypes"
+	"reward-service/internal/service"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRewardService_GetUserBadges(t *testing.T) {
+	t.Parallel()
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetUserBadges", 123).Return([]*calltypes.UserBadge{
+		{Slug: "first-task", Name: "First task", AwardedAt: time.Now()},
+	}, nil)
+
+	svc := service.NewRewardService(mockRepo)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/123/badges", nil)
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "123")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+	rr := httptest.NewRecorder()
+
+	svc.GetUserBadges(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"slug":"first-task"`)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRewardService_CreateBadge(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		requestBody  string
+		setupMock    func(*MockRepository)
+		expectedCode int
+	}{
+		{
+			name:        "badge is created",
+			requestBody: `{"slug": "ten-friends", "name": "Invited 10 friends", "metric": "referrals", "threshold": 10, "reward": 500}`,
+			setupMock: func(m *MockRepository) {
+				m.On("CreateBadge", mock.MatchedBy(func(badge calltypes.Badge) bool {
+					return badge.Active && badge.Metric == "referrals" && badge.Reward == 500
+				})).Return(1, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:        "slug is taken",
+			requestBody: `{"slug": "first-task", "name": "First task", "metric": "tasks_completed", "threshold": 1}`,
+			setupMock: func(m *MockRepository) {
+				m.On("CreateBadge", mock.Anything).Return(0, errormsg.ErrBadgeExists)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "unknown metric",
+			requestBody:  `{"slug": "night-owl", "name": "Night owl", "metric": "logins", "threshold": 1}`,
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid slug",
+			requestBody:  `{"slug": "Top 100", "name": "Top 100", "metric": "monthly_rank", "threshold": 100}`,
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(MockRepository)
+			tt.setupMock(mockRepo)
+
+			svc := service.NewRewardService(mockRepo)
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/badges", strings.NewReader(tt.requestBody))
+			rr := httptest.NewRecorder()
+
+			svc.CreateBadge(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRewardService_RunRankBadges(t *testing.T) {
+	t.Parallel()
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("AwardRankBadges", mock.MatchedBy(func(since time.Time) bool {
+		return since.Day() == 1 && since.Hour() == 0 && since.Location() == time.UTC
+	}), consts.RankingCompetition).Return(2, nil).Once()
+
+	svc := service.NewRewardService(mockRepo)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Rank badges are awarded once on start, the cancelled context stops the job before the first tick.
+	svc.RunRankBadges(ctx, time.Hour)
+
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,358 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reward-service/api/calltypes"
+	"reward-service/api/server/httputils"
+	"reward-service/api/server/middleware"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"strconv"
+	"strings"
+)
+
+// ListCampaigns godoc
+// @Summary List campaigns
+// @Description Returns all campaigns with the number of their redemptions. Available to admins.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} calltypes.JSONResponse{data=[]calltypes.Campaign}
+// @Failure 403 {object} calltypes.ErrorResponse "Insufficient role"
+// @Failure 500 {object} calltypes.ErrorResponse "Failed to fetch campaigns"
+// @Router /admin/campaigns [get].
+func (s *RewardService) ListCampaigns(w http.ResponseWriter, _ *http.Request) {
+	campaigns, err := s.Repo.GetCampaigns()
+	if err != nil {
+		log.Printf("failed to fetch campaigns: %v", err)
+		httputils.ErrorJSON(w, errormsg.ErrFetchCampaigns, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: "Fetched campaigns",
+		Data:    campaigns,
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// CreateCampaign godoc
+// @Summary Create campaign
+// @Description Adds new reward multiplier or promo code campaign. Available to admins.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body calltypes.CampaignRequest true "Campaign"
+// @Success 201 {object} calltypes.JSONResponse
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid campaign"
+// @Failure 403 {object} calltypes.ErrorResponse "Insufficient role"
+// @Failure 409 {object} calltypes.ErrorResponse "Promo code is taken"
+// @Router /admin/campaigns [post].
+func (s *RewardService) CreateCampaign(w http.ResponseWriter, r *http.Request) {
+	var requestPayload calltypes.CampaignRequest
+
+	err := httputils.ReadJSON(w, r, &requestPayload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	campaign, err := campaignFromRequest(requestPayload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	id, err := s.Repo.CreateCampaign(campaign)
+	if errors.Is(err, errormsg.ErrPromoCodeTaken) {
+		httputils.ErrorJSON(w, err, http.StatusConflict)
+
+		return
+	}
+
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrSaveCampaign, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: fmt.Sprintf("Created campaign %s, id: %d", campaign.Name, id),
+	}
+
+	err = httputils.WriteJSON(w, http.StatusCreated, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// UpdateCampaign godoc
+// @Summary Update campaign
+// @Description Replaces the campaign with provided id. Available to admins.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Campaign ID"
+// @Param request body calltypes.CampaignRequest true "Campaign"
+// @Success 200 {object} calltypes.JSONResponse
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid campaign"
+// @Failure 403 {object} calltypes.ErrorResponse "Insufficient role"
+// @Failure 404 {object} calltypes.ErrorResponse "Campaign not found"
+// @Failure 409 {object} calltypes.ErrorResponse "Promo code is taken"
+// @Router /admin/campaigns/{id} [put].
+func (s *RewardService) UpdateCampaign(w http.ResponseWriter, r *http.Request) {
+	id, err := GetIDFromURL(r, "id")
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidID, http.StatusBadRequest)
+
+		return
+	}
+
+	var requestPayload calltypes.CampaignRequest
+
+	err = httputils.ReadJSON(w, r, &requestPayload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	campaign, err := campaignFromRequest(requestPayload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	campaign.ID = id
+
+	err = s.Repo.UpdateCampaign(campaign)
+
+	switch {
+	case errors.Is(err, errormsg.ErrCampaignNotFound):
+		httputils.ErrorJSON(w, err, http.StatusNotFound)
+
+		return
+	case errors.Is(err, errormsg.ErrPromoCodeTaken):
+		httputils.ErrorJSON(w, err, http.StatusConflict)
+
+		return
+	case err != nil:
+		httputils.ErrorJSON(w, errormsg.ErrSaveCampaign, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: "Updated campaign " + campaign.Name,
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// DeleteCampaign godoc
+// @Summary Delete campaign
+// @Description Deletes the campaign, points it has already awarded stay in the ledger. Available to admins.
+// @Tags Admin
+// @Produce json
+// @Param id path int true "Campaign ID"
+// @Success 200 {object} calltypes.JSONResponse
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid campaign ID"
+// @Failure 403 {object} calltypes.ErrorResponse "Insufficient role"
+// @Failure 404 {object} calltypes.ErrorResponse "Campaign not found"
+// @Router /admin/campaigns/{id} [delete].
+func (s *RewardService) DeleteCampaign(w http.ResponseWriter, r *http.Request) {
+	id, err := GetIDFromURL(r, "id")
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidID, http.StatusBadRequest)
+
+		return
+	}
+
+	err = s.Repo.DeleteCampaign(id)
+	if errors.Is(err, errormsg.ErrCampaignNotFound) {
+		httputils.ErrorJSON(w, err, http.StatusNotFound)
+
+		return
+	}
+
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrSaveCampaign, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: fmt.Sprintf("Deleted campaign %d", id),
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// RedeemPromoCode godoc
+// @Summary Redeem promo code
+// @Description Awards the reward of the promo campaign once per user. Wrong codes count towards the same
+// @Description limit of attempts as secret codes.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body calltypes.PromoCodeRequest true "Promo code"
+// @Success 200 {object} calltypes.JSONResponse
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid user ID or wrong code"
+// @Failure 409 {object} calltypes.ErrorResponse "Code is not available to the user, exhausted or already redeemed"
+// @Failure 429 {object} calltypes.ErrorResponse "Too many wrong codes"
+// @Router /users/{id}/promo-codes/redeem [post].
+func (s *RewardService) RedeemPromoCode(w http.ResponseWriter, r *http.Request) {
+	id, err := GetIDFromURL(r, "id")
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidID, http.StatusBadRequest)
+
+		return
+	}
+
+	var requestPayload calltypes.PromoCodeRequest
+
+	err = httputils.ReadJSON(w, r, &requestPayload)
+	if err != nil || normalizePromoCode(requestPayload.Code) == "" {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidPromoCode, http.StatusBadRequest)
+
+		return
+	}
+
+	actorID, _ := middleware.UserIDFromContext(r.Context())
+
+	campaign, err := s.Repo.RedeemPromoCode(normalizePromoCode(requestPayload.Code), calltypes.PointTransaction{
+		UserID:  id,
+		Reason:  consts.ReasonPromoCode,
+		ActorID: actorID,
+	})
+
+	switch {
+	case errors.Is(err, errormsg.ErrTooManyCodeAttempts):
+		w.Header().Set("Retry-After", strconv.Itoa(int(consts.SecretCodeAttemptWindow.Seconds())))
+		httputils.ErrorJSON(w, err, http.StatusTooManyRequests)
+
+		return
+	case errors.Is(err, errormsg.ErrPromoCodeNotFound):
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	case errors.Is(err, errormsg.ErrPromoCodeNotAvailable) || errors.Is(err, errormsg.ErrPromoCodeExhausted) ||
+		errors.Is(err, errormsg.ErrPromoCodeAlreadyRedeemed):
+		httputils.ErrorJSON(w, err, http.StatusConflict)
+
+		return
+	case err != nil:
+		log.Printf("failed to redeem promo code for user %d: %v", id, err)
+		httputils.ErrorJSON(w, errormsg.ErrRedeemPromoCode, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: fmt.Sprintf("Redeemed %s for user with id %d, added points %d", campaign.Name, id, campaign.Reward),
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// campaignFromRequest validates the request, only the parameters of the campaign's kind are kept.
+func campaignFromRequest(request calltypes.CampaignRequest) (calltypes.Campaign, error) {
+	if request.Name == "" || request.NewUserDays < 0 || request.MinScore < 0 || request.MaxRedemptions < 0 {
+		return calltypes.Campaign{}, errormsg.ErrInvalidCampaign
+	}
+
+	if request.StartsAt != nil && request.EndsAt != nil && !request.StartsAt.Before(*request.EndsAt) {
+		return calltypes.Campaign{}, errormsg.ErrInvalidCampaign
+	}
+
+	campaign := calltypes.Campaign{
+		Name:              request.Name,
+		Kind:              request.Kind,
+		MultiplierPercent: 100, //nolint: mnd
+		NewUserDays:       request.NewUserDays,
+		MinScore:          request.MinScore,
+		Active:            request.Active == nil || *request.Active,
+		StartsAt:          request.StartsAt,
+		EndsAt:            request.EndsAt,
+	}
+
+	switch request.Kind {
+	case consts.CampaignKindMultiplier:
+		if request.MultiplierPercent <= 100 || request.MultiplierPercent > consts.MaxMultiplierPercent {
+			return calltypes.Campaign{}, errormsg.ErrInvalidCampaignKind
+		}
+
+		campaign.MultiplierPercent = request.MultiplierPercent
+	case consts.CampaignKindPromo:
+		if request.Reward <= 0 {
+			return calltypes.Campaign{}, errormsg.ErrInvalidCampaignKind
+		}
+
+		code := normalizePromoCode(request.PromoCode)
+		if !isValidPromoCode(code) {
+			return calltypes.Campaign{}, errormsg.ErrInvalidPromoCode
+		}
+
+		campaign.PromoCode = code
+		campaign.Reward = request.Reward
+		campaign.MaxRedemptions = request.MaxRedemptions
+	default:
+		return calltypes.Campaign{}, errormsg.ErrInvalidCampaignKind
+	}
+
+	return campaign, nil
+}
+
+// normalizePromoCode brings a promo code to the upper-case form codes are stored in.
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func isValidPromoCode(code string) bool {
+	if len(code) < consts.PromoCodeMinLength || len(code) > consts.PromoCodeMaxLength {
+		return false
+	}
+
+	for _, c := range code {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '-' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,187 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reward-service/api/calltypes"
+	"reward-service/internal/service"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRewardService_CreateCampaign(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		requestBody  string
+		setupMock    func(*MockRepository)
+		expectedCode int
+	}{
+		{
+			name:        "multiplier campaign",
+			requestBody: `{"name": "Double points weekend", "kind": "multiplier", "multiplierPercent": 200, "reward": 500}`,
+			setupMock: func(m *MockRepository) {
+				m.On("CreateCampaign", mock.MatchedBy(func(campaign calltypes.Campaign) bool {
+					return campaign.MultiplierPercent == 200 && campaign.Reward == 0 && campaign.Active
+				})).Return(1, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:        "promo code is upper-cased",
+			requestBody: `{"name": "Spring", "kind": "promo", "promoCode": "spring25", "reward": 500, "newUserDays": 7}`,
+			setupMock: func(m *MockRepository) {
+				m.On("CreateCampaign", mock.MatchedBy(func(campaign calltypes.Campaign) bool {
+					return campaign.PromoCode == "SPRING25" && campaign.Reward == 500 && campaign.NewUserDays == 7 &&
+						campaign.MultiplierPercent == 100
+				})).Return(2, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "multiplier doesn't increase rewards",
+			requestBody:  `{"name": "Nothing", "kind": "multiplier", "multiplierPercent": 100}`,
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "promo without reward",
+			requestBody:  `{"name": "Spring", "kind": "promo", "promoCode": "SPRING25"}`,
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid promo code",
+			requestBody:  `{"name": "Spring", "kind": "promo", "promoCode": "SPRING 25", "reward": 500}`,
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "window ends before it starts",
+			requestBody: `{"name": "Double points weekend", "kind": "multiplier", "multiplierPercent": 200,
+				"startsAt": "2025-08-02T00:00:00Z", "endsAt": "2025-08-01T00:00:00Z"}`,
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:        "promo code is taken",
+			requestBody: `{"name": "Spring", "kind": "promo", "promoCode": "SPRING25", "reward": 500}`,
+			setupMock: func(m *MockRepository) {
+				m.On("CreateCampaign", mock.AnythingOfType("calltypes.Campaign")).Return(0, errormsg.ErrPromoCodeTaken)
+			},
+			expectedCode: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(MockRepository)
+			tt.setupMock(mockRepo)
+
+			svc := service.NewRewardService(mockRepo)
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/campaigns", strings.NewReader(tt.requestBody))
+			rr := httptest.NewRecorder()
+
+			svc.CreateCampaign(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRewardService_RedeemPromoCode(t *testing.T) {
+	t.Parallel()
+
+	campaign := calltypes.Campaign{ID: 2, Name: "Spring", Kind: consts.CampaignKindPromo, Reward: 500}
+
+	tests := []struct {
+		name         string
+		requestBody  string
+		setupMock    func(*MockRepository)
+		expectedCode int
+	}{
+		{
+			name:        "code is redeemed",
+			requestBody: `{"code": "spring25"}`,
+			setupMock: func(m *MockRepository) {
+				m.On("RedeemPromoCode", "SPRING25", mock.MatchedBy(func(entry calltypes.PointTransaction) bool {
+					return entry.UserID == 123 && entry.Reason == consts.ReasonPromoCode
+				})).Return(&campaign, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:        "wrong code",
+			requestBody: `{"code": "WINTER25"}`,
+			setupMock: func(m *MockRepository) {
+				m.On("RedeemPromoCode", "WINTER25", mock.AnythingOfType("calltypes.PointTransaction")).
+					Return((*calltypes.Campaign)(nil), errormsg.ErrPromoCodeNotFound)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:        "user is outside of the audience",
+			requestBody: `{"code": "SPRING25"}`,
+			setupMock: func(m *MockRepository) {
+				m.On("RedeemPromoCode", "SPRING25", mock.AnythingOfType("calltypes.PointTransaction")).
+					Return((*calltypes.Campaign)(nil), errormsg.ErrPromoCodeNotAvailable)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:        "already redeemed",
+			requestBody: `{"code": "SPRING25"}`,
+			setupMock: func(m *MockRepository) {
+				m.On("RedeemPromoCode", "SPRING25", mock.AnythingOfType("calltypes.PointTransaction")).
+					Return((*calltypes.Campaign)(nil), errormsg.ErrPromoCodeAlreadyRedeemed)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:        "too many wrong codes",
+			requestBody: `{"code": "SPRING25"}`,
+			setupMock: func(m *MockRepository) {
+				m.On("RedeemPromoCode", "SPRING25", mock.AnythingOfType("calltypes.PointTransaction")).
+					Return((*calltypes.Campaign)(nil), errormsg.ErrTooManyCodeAttempts)
+			},
+			expectedCode: http.StatusTooManyRequests,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(MockRepository)
+			tt.setupMock(mockRepo)
+
+			svc := service.NewRewardService(mockRepo)
+
+			req := httptest.NewRequest(http.MethodPost, "/users/123/promo-codes/redeem",
+				strings.NewReader(tt.requestBody))
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "123")
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+
+			svc.RedeemPromoCode(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"reward-service/api/calltypes"
+	"reward-service/pkg/consts"
+)
+
+// Config holds the service settings that can be overridden at startup.
+type Config struct {
+	// ReferralLinkTemplate is the share link of a referral code, "{code}" is replaced with the code itself.
+	ReferralLinkTemplate string
+	// ReferralRewards are the points paid for a redeemed referral code, the number of tiers
+	// is also the depth of the referral tree shown in the statistics.
+	ReferralRewards calltypes.ReferralRewards
+	// Ranking is how users with equal scores are ranked on the leaderboard, consts.RankingCompetition
+	// or consts.RankingDense.
+	Ranking string
+}
+
+// DefaultConfig returns the settings used unless they are overridden.
+func DefaultConfig() Config {
+	return Config{
+		ReferralLinkTemplate: consts.ReferralLinkTemplate,
+		ReferralRewards: calltypes.ReferralRewards{
+			Tiers:   []int{consts.FixedRewardForReferrer, consts.SecondTierReferrerReward},
+			Referee: consts.FixedRewardForReferee,
+		},
+		Ranking: consts.RankingCompetition,
+	}
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"log"
+	"reward-service/pkg/consts"
+	"time"
+)
+
+// RunPointExpiry writes expiry debits for the expired point grants and purges the expired idempotency keys
+// every interval until ctx is done.
+func (s *RewardService) RunPointExpiry(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.expirePoints()
+		s.purgeIdempotencyKeys()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *RewardService) expirePoints() {
+	expired, err := s.Repo.ExpirePoints(time.Now())
+	if err != nil {
+		// Grants that were not expired are picked up by the next run.
+		log.Printf("failed to expire points: %v", err)
+	}
+
+	if expired > 0 {
+		log.Printf("Expired %d points", expired)
+	}
+}
+
+func (s *RewardService) purgeIdempotencyKeys() {
+	purged, err := s.Repo.PurgeIdempotencyKeys(time.Now().Add(-consts.IdempotencyKeyTTL))
+	if err != nil {
+		log.Printf("failed to purge idempotency keys: %v", err)
+	}
+
+	if purged > 0 {
+		log.Printf("Purged %d idempotency keys", purged)
+	}
+}
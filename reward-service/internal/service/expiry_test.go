@@ -0,0 +1,31 @@
+package service_test
+
+import (
+	"context"
+	"reward-service/internal/service"
+	"reward-service/pkg/consts"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRewardService_RunPointExpiry(t *testing.T) {
+	t.Parallel()
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("ExpirePoints", mock.AnythingOfType("time.Time")).Return(300, nil).Once()
+	mockRepo.On("PurgeIdempotencyKeys", mock.MatchedBy(func(before time.Time) bool {
+		return time.Since(before) >= consts.IdempotencyKeyTTL
+	})).Return(4, nil).Once()
+
+	svc := service.NewRewardService(mockRepo)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// The expiry and the purge run once on start, the cancelled context stops the job before the first tick.
+	svc.RunPointExpiry(ctx, time.Hour)
+
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reward-service/api/calltypes"
+	"reward-service/api/server/httputils"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetLeaderboard godoc
+// @Summary Get user leaderboard
+// @Description Returns active users ordered by score with cursor pagination, only their public names are shown.
+// @Description The "all" period ranks the balance, "month", "week" and "day" rank the points earned since
+// @Description the period began in UTC (weeks start on Monday). Users with equal scores share the rank,
+// @Description competition (1, 2, 2, 4) or dense (1, 2, 2, 3) ranking is set by the config.
+// @Tags Users
+// @Produce json
+// @Param period query string false "Period" Enums(all, month, week, day) default(all)
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "Cursor from the previous page"
+// @Success 200 {object} calltypes.JSONResponse{data=calltypes.LeaderboardPage}
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid period, limit or cursor"
+// @Failure 500 {object} calltypes.ErrorResponse "Failed to fetch leaderboard"
+// @Router /users/leaderboard [get].
+func (s *RewardService) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
+	period, since, err := parsePeriod(r)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	limit, err := parseLimit(r)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	var after *calltypes.LeaderboardCursor
+
+	if rawCursor := r.URL.Query().Get("cursor"); rawCursor != "" {
+		after, err = decodeLeaderboardCursor(rawCursor)
+		if err != nil {
+			httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	// One extra entry tells whether there is a next page.
+	entries, err := s.Repo.GetLeaderboard(since, after, limit+1, s.Config.Ranking)
+	if err != nil {
+		log.Printf("failed to fetch %s leaderboard: %v", period, err)
+		httputils.ErrorJSON(w, errormsg.ErrFetchLeaderboard, http.StatusInternalServerError)
+
+		return
+	}
+
+	page := calltypes.LeaderboardPage{Period: period, Items: entries}
+
+	if len(entries) > limit {
+		page.Items = entries[:limit]
+		page.NextCursor = encodeLeaderboardCursor(page.Items[limit-1])
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: "Fetched leaderboard",
+		Data:    page,
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// GetRank godoc
+// @Summary Get user's rank
+// @Description Returns the user's place on the leaderboard of the period: the rank, the position that breaks
+// @Description the ties by user id, the number of ranked users, the percentile and the neighbouring entries.
+// @Tags Users
+// @Produce json
+// @Param id path int true "User ID"
+// @Param period query string false "Period" Enums(all, month, week, day) default(all)
+// @Param neighbours query int false "Entries above and below the user, up to 25" default(5)
+// @Success 200 {object} calltypes.JSONResponse{data=calltypes.UserRank}
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid user ID, period or neighbours"
+// @Failure 404 {object} calltypes.ErrorResponse "User is not ranked for the period"
+// @Failure 500 {object} calltypes.ErrorResponse "Failed to fetch rank"
+// @Router /users/{id}/rank [get].
+func (s *RewardService) GetRank(w http.ResponseWriter, r *http.Request) {
+	id, err := GetIDFromURL(r, "id")
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidID, http.StatusBadRequest)
+
+		return
+	}
+
+	period, since, err := parsePeriod(r)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	neighbours := consts.DefaultRankNeighbours
+
+	if rawNeighbours := r.URL.Query().Get("neighbours"); rawNeighbours != "" {
+		neighbours, err = strconv.Atoi(rawNeighbours)
+		if err != nil || neighbours < 0 || neighbours > consts.MaxRankNeighbours {
+			httputils.ErrorJSON(w, errormsg.ErrInvalidNeighbours, http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	rank, err := s.Repo.GetRank(id, since, neighbours, s.Config.Ranking)
+
+	switch {
+	case errors.Is(err, errormsg.ErrUserNotRanked):
+		httputils.ErrorJSON(w, err, http.StatusNotFound)
+
+		return
+	case err != nil:
+		log.Printf("failed to fetch %s rank of user %d: %v", period, id, err)
+		httputils.ErrorJSON(w, errormsg.ErrFetchRank, http.StatusInternalServerError)
+
+		return
+	}
+
+	rank.Period = period
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: fmt.Sprintf("Fetched rank of user with id %d", id),
+		Data:    rank,
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// parsePeriod reads the leaderboard period from the "period" query parameter and returns it with its start.
+func parsePeriod(r *http.Request) (string, time.Time, error) {
+	period := r.URL.Query().Get("period")
+	if period == "" {
+		period = consts.LeaderboardPeriodAll
+	}
+
+	since, err := periodStart(period, time.Now())
+
+	return period, since, err
+}
+
+// periodStart returns the time the leaderboard period began, zero for the whole time.
+func periodStart(period string, now time.Time) (time.Time, error) {
+	year, month, day := now.UTC().Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+	switch period {
+	case consts.LeaderboardPeriodAll:
+		return time.Time{}, nil
+	case consts.LeaderboardPeriodMonth:
+		return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC), nil
+	case consts.LeaderboardPeriodWeek:
+		// Weekday counts from Sunday, weeks start on Monday.
+		return today.AddDate(0, 0, -(int(today.Weekday())+6)%7), nil //nolint: mnd
+	case consts.LeaderboardPeriodDay:
+		return today, nil
+	default:
+		return time.Time{}, errormsg.ErrInvalidPeriod
+	}
+}
+
+// encodeLeaderboardCursor returns an opaque cursor pointing after the entry.
+func encodeLeaderboardCursor(entry *calltypes.LeaderboardEntry) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", entry.Score, entry.UserID)))
+}
+
+func decodeLeaderboardCursor(rawCursor string) (*calltypes.LeaderboardCursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(rawCursor)
+	if err != nil {
+		return nil, errormsg.ErrInvalidCursor
+	}
+
+	rawScore, rawUserID, found := strings.Cut(string(decoded), ":")
+	if !found {
+		return nil, errormsg.ErrInvalidCursor
+	}
+
+	score, err := strconv.Atoi(rawScore)
+	if err != nil {
+		return nil, errormsg.ErrInvalidCursor
+	}
+
+	userID, err := strconv.Atoi(rawUserID)
+	if err != nil || userID <= 0 {
+		return nil, errormsg.ErrInvalidCursor
+	}
+
+	return &calltypes.LeaderboardCursor{Score: score, UserID: userID}, nil
+}
@@ -0,0 +1,116 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reward-service/api/calltypes"
+	"reward-service/internal/service"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRewardService_GetRank(t *testing.T) {
+	t.Parallel()
+
+	// The handler sets the period of the rank it gets, every case gets its own.
+	newRank := func() *calltypes.UserRank {
+		return &calltypes.UserRank{
+			Rank:       2,
+			Position:   3,
+			Total:      10,
+			Percentile: 70,
+			Score:      100,
+			Above: []*calltypes.LeaderboardEntry{
+				{Rank: 1, UserID: 7, DisplayName: "Anna K.", Score: 300},
+				{Rank: 2, UserID: 5, DisplayName: "Oleg P.", Score: 100},
+			},
+			Below: []*calltypes.LeaderboardEntry{{Rank: 4, UserID: 9, DisplayName: "User 9", Score: 50}},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		query        string
+		ranking      string
+		setupMock    func(*MockRepository)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:    "all time rank",
+			query:   "?neighbours=2",
+			ranking: consts.RankingCompetition,
+			setupMock: func(m *MockRepository) {
+				m.On("GetRank", 123, time.Time{}, 2, consts.RankingCompetition).Return(newRank(), nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `"period":"all","rank":2,"position":3,"total":10,"percentile":70`,
+		},
+		{
+			name:    "monthly rank with dense ranking",
+			query:   "?period=month",
+			ranking: consts.RankingDense,
+			setupMock: func(m *MockRepository) {
+				m.On("GetRank", 123, mock.MatchedBy(func(since time.Time) bool {
+					return since.Day() == 1 && since.Hour() == 0
+				}), consts.DefaultRankNeighbours, consts.RankingDense).Return(newRank(), nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `"period":"month"`,
+		},
+		{
+			name:    "user is not ranked",
+			query:   "?period=day",
+			ranking: consts.RankingCompetition,
+			setupMock: func(m *MockRepository) {
+				m.On("GetRank", 123, mock.AnythingOfType("time.Time"), consts.DefaultRankNeighbours,
+					consts.RankingCompetition).Return((*calltypes.UserRank)(nil), errormsg.ErrUserNotRanked)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "too many neighbours",
+			query:        "?neighbours=100",
+			ranking:      consts.RankingCompetition,
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(MockRepository)
+			tt.setupMock(mockRepo)
+
+			svc := service.NewRewardService(mockRepo)
+			svc.Config.Ranking = tt.ranking
+
+			req := httptest.NewRequest(http.MethodGet, "/users/123/rank"+tt.query, nil)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "123")
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+
+			svc.GetRank(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+
+			if tt.expectedBody != "" {
+				assert.Contains(t, rr.Body.String(), tt.expectedBody)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
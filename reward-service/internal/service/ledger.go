@@ -0,0 +1,92 @@
+package service
+
+import (
+	"net/http"
+	"reward-service/api/calltypes"
+	"reward-service/api/server/httputils"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"strconv"
+)
+
+// GetTransactions godoc
+// @Summary Get points history
+// @Description Returns user's points ledger entries, newest first, with cursor pagination
+// @Tags Users
+// @Produce json
+// @Param id path int true "User ID"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "Cursor from the previous page"
+// @Success 200 {object} calltypes.JSONResponse{data=calltypes.TransactionsPage}
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid user ID, limit or cursor"
+// @Failure 500 {object} calltypes.ErrorResponse "Failed to fetch transactions"
+// @Router /users/{id}/transactions [get].
+func (s *RewardService) GetTransactions(w http.ResponseWriter, r *http.Request) {
+	id, err := GetIDFromURL(r, "id")
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidID, http.StatusBadRequest)
+
+		return
+	}
+
+	limit, err := parseLimit(r)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	cursor := 0
+
+	if rawCursor := r.URL.Query().Get("cursor"); rawCursor != "" {
+		cursor, err = strconv.Atoi(rawCursor)
+		if err != nil || cursor <= 0 {
+			httputils.ErrorJSON(w, errormsg.ErrInvalidCursor, http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	// One extra entry tells whether there is a next page.
+	transactions, err := s.Repo.GetTransactions(id, cursor, limit+1)
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrFetchTransactions, http.StatusInternalServerError)
+
+		return
+	}
+
+	page := calltypes.TransactionsPage{Items: transactions}
+
+	if len(transactions) > limit {
+		page.Items = transactions[:limit]
+		page.NextCursor = strconv.Itoa(page.Items[limit-1].ID)
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: "Fetched transactions",
+		Data:    page,
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// parseLimit reads the page size from the "limit" query parameter.
+func parseLimit(r *http.Request) (int, error) {
+	rawLimit := r.URL.Query().Get("limit")
+	if rawLimit == "" {
+		return consts.DefaultPageLimit, nil
+	}
+
+	limit, err := strconv.Atoi(rawLimit)
+	if err != nil || limit <= 0 {
+		return 0, errormsg.ErrInvalidLimit
+	}
+
+	return min(limit, consts.MaxPageLimit), nil
+}
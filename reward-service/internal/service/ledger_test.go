@@ -0,0 +1,95 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reward-service/api/calltypes"
+	"reward-service/internal/service"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewardService_GetTransactions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name               string
+		query              string
+		mockSetup          func(*MockRepository)
+		expectedStatus     int
+		expectedItems      int
+		expectedNextCursor string
+	}{
+		{
+			name:  "First page with more entries",
+			query: "?limit=2",
+			mockSetup: func(m *MockRepository) {
+				m.On("GetTransactions", 5, 0, 3).Return([]*calltypes.PointTransaction{{ID: 9}, {ID: 8}, {ID: 7}}, nil)
+			},
+			expectedStatus:     http.StatusOK,
+			expectedItems:      2,
+			expectedNextCursor: "8",
+		},
+		{
+			name:  "Last page",
+			query: "?limit=2&cursor=8",
+			mockSetup: func(m *MockRepository) {
+				m.On("GetTransactions", 5, 8, 3).Return([]*calltypes.PointTransaction{{ID: 7}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedItems:  1,
+		},
+		{
+			name:           "Invalid cursor",
+			query:          "?cursor=abc",
+			mockSetup:      func(_ *MockRepository) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid limit",
+			query:          "?limit=-1",
+			mockSetup:      func(_ *MockRepository) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(MockRepository)
+			tt.mockSetup(mockRepo)
+
+			svc := service.NewRewardService(mockRepo)
+
+			req := httptest.NewRequest(http.MethodGet, "/users/5/transactions"+tt.query, nil)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "5")
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+
+			svc.GetTransactions(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var response struct {
+					Data calltypes.TransactionsPage `json:"data"`
+				}
+
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+				assert.Len(t, response.Data.Items, tt.expectedItems)
+				assert.Equal(t, tt.expectedNextCursor, response.Data.NextCursor)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
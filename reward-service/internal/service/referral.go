@@ -0,0 +1,96 @@
+package service
+
+import (
+	"log"
+	"net/http"
+	"reward-service/api/calltypes"
+	"reward-service/api/server/httputils"
+	"reward-service/api/server/middleware"
+	"reward-service/pkg/errormsg"
+	"strings"
+)
+
+// GetReferral godoc
+// @Summary Get own referral code
+// @Description Returns the referral code of the current user and the link to share it
+// @Tags Users
+// @Produce json
+// @Success 200 {object} calltypes.JSONResponse{data=calltypes.ReferralInfo}
+// @Failure 401 {object} calltypes.ErrorResponse "Unauthorized"
+// @Failure 500 {object} calltypes.ErrorResponse "Failed to fetch referral code"
+// @Router /users/me/referral [get].
+func (s *RewardService) GetReferral(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidID, http.StatusUnauthorized)
+
+		return
+	}
+
+	user, err := s.Repo.GetOne(userID)
+	if err != nil {
+		log.Printf("failed to fetch referral code of user %d: %v", userID, err)
+		httputils.ErrorJSON(w, errormsg.ErrFetchReferral, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: "Fetched referral code",
+		Data: calltypes.ReferralInfo{
+			Code:              user.Referrer,
+			ShareLink:         strings.ReplaceAll(s.Config.ReferralLinkTemplate, "{code}", user.Referrer),
+			ShareLinkTemplate: s.Config.ReferralLinkTemplate,
+		},
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// GetReferralStats godoc
+// @Summary Get referral statistics
+// @Description Returns how many users the user invited, per referral level, the list of direct invitees and points earned from them
+// @Tags Users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} calltypes.JSONResponse{data=calltypes.ReferralStats}
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid user ID"
+// @Failure 500 {object} calltypes.ErrorResponse "Failed to fetch referral statistics"
+// @Router /users/{id}/referrals [get].
+func (s *RewardService) GetReferralStats(w http.ResponseWriter, r *http.Request) {
+	id, err := GetIDFromURL(r, "id")
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidID, http.StatusBadRequest)
+
+		return
+	}
+
+	depth := max(len(s.Config.ReferralRewards.Tiers), 1)
+
+	stats, err := s.Repo.GetReferralStats(id, depth)
+	if err != nil {
+		log.Printf("failed to fetch referral statistics of user %d: %v", id, err)
+		httputils.ErrorJSON(w, errormsg.ErrFetchReferralStats, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: "Fetched referral statistics",
+		Data:    stats,
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
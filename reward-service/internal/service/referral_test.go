@@ -0,0 +1,126 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reward-service/api/calltypes"
+	"reward-service/api/server/middleware"
+	"reward-service/internal/service"
+	"reward-service/pkg/errormsg"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewardService_GetReferral(t *testing.T) {
+	t.Parallel()
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetOne", 5).Return(&calltypes.User{ID: 5, Referrer: "7KQ2MZXA"}, nil)
+
+	svc := service.NewRewardService(mockRepo)
+	svc.Config.ReferralLinkTemplate = "https://example.com/r/{code}"
+
+	req := httptest.NewRequest(http.MethodGet, "/users/me/referral", nil)
+	req = req.WithContext(middleware.WithIdentity(req.Context(), middleware.Identity{UserID: 5, SessionID: 1}))
+
+	rr := httptest.NewRecorder()
+
+	svc.GetReferral(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response struct {
+		Data calltypes.ReferralInfo `json:"data"`
+	}
+
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.Equal(t, "7KQ2MZXA", response.Data.Code)
+	assert.Equal(t, "https://example.com/r/7KQ2MZXA", response.Data.ShareLink)
+	assert.Equal(t, "https://example.com/r/{code}", response.Data.ShareLinkTemplate)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRewardService_GetReferralStats(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		urlID        string
+		tiers        []int
+		setupMock    func(*MockRepository)
+		expectedCode int
+	}{
+		{
+			name:  "depth follows configured tiers",
+			urlID: "7",
+			tiers: []int{100, 20, 5},
+			setupMock: func(m *MockRepository) {
+				m.On("GetReferralStats", 7, 3).Return(&calltypes.ReferralStats{
+					Invited:      1,
+					PointsEarned: 120,
+					Levels:       []calltypes.ReferralLevel{{Level: 1, Count: 1, PointsEarned: 100}},
+					Invitees:     []*calltypes.Invitee{{UserID: 8, PointsEarned: 120}},
+				}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "invalid ID",
+			urlID:        "abc",
+			tiers:        []int{100},
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:  "repository error",
+			urlID: "7",
+			tiers: []int{100},
+			setupMock: func(m *MockRepository) {
+				m.On("GetReferralStats", 7, 1).Return(nil, errormsg.ErrRepositoryError)
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(MockRepository)
+			tt.setupMock(mockRepo)
+
+			svc := service.NewRewardService(mockRepo)
+			svc.Config.ReferralRewards.Tiers = tt.tiers
+
+			req := httptest.NewRequest(http.MethodGet, "/users/"+tt.urlID+"/referrals", nil)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.urlID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+
+			svc.GetReferralStats(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+
+			if tt.expectedCode == http.StatusOK {
+				var response struct {
+					Data calltypes.ReferralStats `json:"data"`
+				}
+
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+				assert.Equal(t, 1, response.Data.Invited)
+				assert.Equal(t, 120, response.Data.PointsEarned)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -3,23 +3,78 @@ package service
 import (
 	"net/http"
 	"reward-service/internal/postgres/repository"
+	"reward-service/internal/secretcode"
+	"reward-service/internal/voucher"
 )
 
 type RewardServiceInterface interface {
 	RetrieveOne(w http.ResponseWriter, r *http.Request)
 	GetLeaderboard(w http.ResponseWriter, r *http.Request)
+	GetRank(w http.ResponseWriter, r *http.Request)
+	ListSeasons(w http.ResponseWriter, r *http.Request)
+	GetSeasonLeaderboard(w http.ResponseWriter, r *http.Request)
+	CreateSeason(w http.ResponseWriter, r *http.Request)
 	CompleteTelegramSign(w http.ResponseWriter, r *http.Request)
 	CompleteXSign(w http.ResponseWriter, r *http.Request)
 	RedeemReferrer(w http.ResponseWriter, r *http.Request)
 	SomeTask(w http.ResponseWriter, r *http.Request)
-	Kuarhodron(w http.ResponseWriter, r *http.Request)
+	RedeemSecretCode(w http.ResponseWriter, r *http.Request)
+	ListSecretCodes(w http.ResponseWriter, r *http.Request)
+	CreateSecretCode(w http.ResponseWriter, r *http.Request)
+	RedeemPromoCode(w http.ResponseWriter, r *http.Request)
+	ListCampaigns(w http.ResponseWriter, r *http.Request)
+	CreateCampaign(w http.ResponseWriter, r *http.Request)
+	UpdateCampaign(w http.ResponseWriter, r *http.Request)
+	DeleteCampaign(w http.ResponseWriter, r *http.Request)
+	ListStoreItems(w http.ResponseWriter, r *http.Request)
+	ListAllStoreItems(w http.ResponseWriter, r *http.Request)
+	CreateStoreItem(w http.ResponseWriter, r *http.Request)
+	UpdateStoreItem(w http.ResponseWriter, r *http.Request)
+	RedeemItem(w http.ResponseWriter, r *http.Request)
+	GetRedemptions(w http.ResponseWriter, r *http.Request)
+	ListRedemptions(w http.ResponseWriter, r *http.Request)
+	SetRedemptionStatus(w http.ResponseWriter, r *http.Request)
+	UploadVouchers(w http.ResponseWriter, r *http.Request)
+	GetVouchers(w http.ResponseWriter, r *http.Request)
+	ListTiers(w http.ResponseWriter, r *http.Request)
+	CreateTier(w http.ResponseWriter, r *http.Request)
+	UpdateTier(w http.ResponseWriter, r *http.Request)
+	DeleteTier(w http.ResponseWriter, r *http.Request)
+	GetUserBadges(w http.ResponseWriter, r *http.Request)
+	ListBadges(w http.ResponseWriter, r *http.Request)
+	CreateBadge(w http.ResponseWriter, r *http.Request)
+	UpdateBadge(w http.ResponseWriter, r *http.Request)
+	CheckIn(w http.ResponseWriter, r *http.Request)
+	BuyStreakFreeze(w http.ResponseWriter, r *http.Request)
+	SetTimezone(w http.ResponseWriter, r *http.Request)
 	Authenticate(w http.ResponseWriter, r *http.Request)
+	Refresh(w http.ResponseWriter, r *http.Request)
+	Logout(w http.ResponseWriter, r *http.Request)
+	LogoutAll(w http.ResponseWriter, r *http.Request)
+	GetSessions(w http.ResponseWriter, r *http.Request)
+	RevokeSession(w http.ResponseWriter, r *http.Request)
+	GetTransactions(w http.ResponseWriter, r *http.Request)
+	ListTasks(w http.ResponseWriter, r *http.Request)
+	ListAllTasks(w http.ResponseWriter, r *http.Request)
+	CompleteTaskBySlug(w http.ResponseWriter, r *http.Request)
+	CreateTask(w http.ResponseWriter, r *http.Request)
+	UpdateTask(w http.ResponseWriter, r *http.Request)
+	GetReferral(w http.ResponseWriter, r *http.Request)
+	GetReferralStats(w http.ResponseWriter, r *http.Request)
+	ListUsers(w http.ResponseWriter, r *http.Request)
+	SetUserRole(w http.ResponseWriter, r *http.Request)
 	Registrate(w http.ResponseWriter, r *http.Request)
-	CompleteTask(w http.ResponseWriter, r *http.Request, points int)
 }
 
 type RewardService struct {
 	RewardServiceInterface
 	Repo   repository.Repository
 	Client *http.Client
+	Config Config
+	// Verifiers check task completions, keyed by calltypes.Task.Verifier.
+	Verifiers map[string]TaskVerifier
+	// Vouchers encrypts voucher codes, vouchers are disabled when it is nil.
+	Vouchers *voucher.Cipher
+	// SecretCodes hashes the codes of secret-code quests, they are disabled when it is nil.
+	SecretCodes *secretcode.Hasher
 }
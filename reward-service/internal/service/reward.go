@@ -2,13 +2,15 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"github.com/go-chi/chi/v5"
+	"log"
 	"net/http"
 	"reward-service/api/calltypes"
 	"reward-service/api/server/httputils"
 	"reward-service/internal/postgres/repository"
-	"reward-service/internal/token"
+	"reward-service/internal/referral"
 	"reward-service/pkg/consts"
 	"reward-service/pkg/errormsg"
 	"strconv"
@@ -18,8 +20,10 @@ import (
 
 func NewRewardService(repo repository.Repository) *RewardService {
 	return &RewardService{
-		Repo:   repo,
-		Client: &http.Client{},
+		Repo:      repo,
+		Client:    &http.Client{Timeout: consts.VerifierRequestTimeout},
+		Config:    DefaultConfig(),
+		Verifiers: map[string]TaskVerifier{},
 	}
 }
 
@@ -59,13 +63,15 @@ func GetIDFromURL(r *http.Request, paramName string) (int, error) {
 
 // Registrate godoc
 // @Summary Register new user
-// @Description Creates new user account
+// @Description Creates new active user account with zero balance. Referrer is an optional vanity referral code,
+// @Description it is generated when omitted
 // @Tags Users
 // @Accept json
 // @Produce json
 // @Param request body calltypes.RegisterRequest true "User registration data"
 // @Success 202 {object} calltypes.JSONResponse
 // @Failure 400 {object} calltypes.ErrorResponse "Invalid request data"
+// @Failure 409 {object} calltypes.ErrorResponse "Referral code is already taken"
 // @Router /register [post].
 func (s *RewardService) Registrate(w http.ResponseWriter, r *http.Request) {
 	var requestPayload struct {
@@ -73,8 +79,6 @@ func (s *RewardService) Registrate(w http.ResponseWriter, r *http.Request) {
 		FirstName string `json:"firstName"`
 		LastName  string `json:"lastName"`
 		Password  string `json:"password"`
-		Active    int    `json:"active,omitempty"`
-		Score     int    `json:"score,omitempty"`
 		Referrer  string `json:"referrer,omitempty"`
 	}
 
@@ -91,59 +95,43 @@ func (s *RewardService) Registrate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if requestPayload.Referrer != "" {
+		requestPayload.Referrer, err = referral.ValidateVanityCode(requestPayload.Referrer)
+		if err != nil {
+			httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+			return
+		}
+	}
+
 	user := calltypes.User{
 		Email:     requestPayload.Email,
 		FirstName: requestPayload.FirstName,
 		LastName:  requestPayload.LastName,
 		Password:  requestPayload.Password,
-		Active:    requestPayload.Active,
-		Score:     requestPayload.Score,
+		Active:    1,
 		Referrer:  requestPayload.Referrer,
 	}
 
 	id, err := s.Repo.Insert(user)
-	if err != nil {
-		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+	if errors.Is(err, errormsg.ErrReferralCodeTaken) {
+		httputils.ErrorJSON(w, err, http.StatusConflict)
 
 		return
 	}
 
-	payload := calltypes.JSONResponse{
-		Error:   false,
-		Message: fmt.Sprintf("Successfully created new user, id: %d", id),
-	}
-
-	err = httputils.WriteJSON(w, http.StatusAccepted, payload)
 	if err != nil {
 		httputils.ErrorJSON(w, err, http.StatusBadRequest)
 
 		return
 	}
-}
-
-// GetLeaderboard godoc
-// @Summary Get user leaderboard
-// @Description Returns all users ordered by score
-// @Tags Users
-// @Produce json
-// @Success 200 {object} calltypes.JSONResponse{data=[]calltypes.User}
-// @Failure 400 {object} calltypes.ErrorResponse "Failed to fetch users"
-// @Router /leaderboard [get].
-func (s *RewardService) GetLeaderboard(w http.ResponseWriter, _ *http.Request) {
-	users, err := s.Repo.GetAll()
-	if err != nil {
-		httputils.ErrorJSON(w, errormsg.ErrFetchUsers, http.StatusBadRequest)
-
-		return
-	}
 
 	payload := calltypes.JSONResponse{
 		Error:   false,
-		Message: "Fetched all users",
-		Data:    users,
+		Message: fmt.Sprintf("Successfully created new user, id: %d", id),
 	}
 
-	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	err = httputils.WriteJSON(w, http.StatusAccepted, payload)
 	if err != nil {
 		httputils.ErrorJSON(w, err, http.StatusBadRequest)
 
@@ -195,41 +183,15 @@ func (s *RewardService) Authenticate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tokenService := token.NewTokenService()
-
-	accessToken, hashedRefreshToken, err := tokenService.GenerateTokens(user.ID)
-	if err != nil {
-		httputils.ErrorJSON(w, err, http.StatusInternalServerError)
-
-		return
-	}
-
-	err = s.Repo.StoreRefreshToken(user.ID, hashedRefreshToken)
+	accessToken, refreshToken, err := s.startSession(r, user.ID, user.Role)
 	if err != nil {
-		fmt.Println("Error during storing refresh token is: ", err)
+		log.Println("Error during starting session is: ", err)
+		httputils.ErrorJSON(w, errormsg.ErrCreateSession, http.StatusInternalServerError)
 
 		return
 	}
 
-	http.SetCookie(w, &http.Cookie{
-		Name:     "accessToken",
-		Value:    accessToken,
-		Path:     "/",
-		HttpOnly: true,
-		Secure:   false,
-		SameSite: http.SameSiteStrictMode,
-		Expires:  time.Now().Add(consts.AccessTokenExpireTime),
-	})
-
-	http.SetCookie(w, &http.Cookie{
-		Name:     "refreshToken",
-		Value:    hashedRefreshToken,
-		Path:     "/",
-		HttpOnly: true,
-		Secure:   false,
-		SameSite: http.SameSiteStrictMode,
-		Expires:  time.Now().Add(consts.RefreshTokenExpireTime),
-	})
+	setAuthCookies(w, accessToken, refreshToken)
 
 	payload := calltypes.JSONResponse{
 		Error:   false,
@@ -247,94 +209,44 @@ func (s *RewardService) Authenticate(w http.ResponseWriter, r *http.Request) {
 
 // SomeTask godoc
 // @Summary Complete some task
-// @Description Awards fixed points for some task
+// @Description Awards points for some task, alias of /users/{id}/tasks/some-task/complete
 // @Tags Tasks
 // @Param id path int true "User ID"
 // @Success 200 {object} calltypes.JSONResponse
 // @Failure 400 {object} calltypes.ErrorResponse "Invalid user ID"
-// @Router /tasks/some/{id} [post].
+// @Router /users/{id}/task/complete [post].
 func (s *RewardService) SomeTask(w http.ResponseWriter, r *http.Request) {
-	s.CompleteTask(w, r, consts.FixedRewardForSomeTask)
+	s.completeCatalogTask(w, r, consts.TaskSlugSomeTask)
 }
 
-// CompleteTask godoc
-// @Summary Complete task and earn points
-// @Description Awards points to user for completing task
+// CompleteTelegramSign godoc
+// @Summary Complete Telegram subscription task
+// @Description Alias of /users/{id}/tasks/telegram-sign/complete
 // @Tags Tasks
 // @Param id path int true "User ID"
-// @Param points query int true "Points to award"
 // @Success 200 {object} calltypes.JSONResponse
-// @Failure 400 {object} calltypes.ErrorResponse "Failed to add points"
-// @Router /tasks/complete/{id} [post].
-func (s *RewardService) CompleteTask(w http.ResponseWriter, r *http.Request, points int) {
-	id, err := GetIDFromURL(r, "id")
-	if err != nil {
-		httputils.ErrorJSON(w, errormsg.ErrInvalidID, http.StatusBadRequest)
-
-		return
-	}
-
-	err = s.Repo.AddPoints(id, points)
-	if err != nil {
-		httputils.ErrorJSON(w, errormsg.ErrAddPoints, http.StatusBadRequest)
-
-		return
-	}
-
-	payload := calltypes.JSONResponse{
-		Error:   false,
-		Message: fmt.Sprintf("complete task worked for user with id %d, added points %d", id, points),
-	}
-
-	err = httputils.WriteJSON(w, http.StatusOK, payload)
-	if err != nil {
-		httputils.ErrorJSON(w, err, http.StatusBadRequest)
-
-		return
-	}
-}
-
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid user ID"
+// @Router /users/{id}/task/telegramSign [post].
 func (s *RewardService) CompleteTelegramSign(w http.ResponseWriter, r *http.Request) {
-	s.CompleteTask(w, r, consts.FixedRewardForTelegramSign)
+	s.completeCatalogTask(w, r, consts.TaskSlugTelegramSign)
 }
 
-func (s *RewardService) CompleteXSign(w http.ResponseWriter, r *http.Request) {
-	s.CompleteTask(w, r, consts.FixedRewardForXSign)
-}
-
-// Kuarhodron godoc
-// @Summary Secret task endpoint
-// @Description Complete secret task with special password
+// CompleteXSign godoc
+// @Summary Complete X subscription task
+// @Description Alias of /users/{id}/tasks/x-sign/complete
 // @Tags Tasks
-// @Accept json
-// @Param request body calltypes.SecretTaskRequest true "Secret password"
+// @Param id path int true "User ID"
 // @Success 200 {object} calltypes.JSONResponse
-// @Failure 400 {object} calltypes.ErrorResponse "Invalid password"
-// @Router /secret-task [post].
-func (s *RewardService) Kuarhodron(w http.ResponseWriter, r *http.Request) {
-	var requestPayload struct {
-		SecretWaterPassword string `json:"waterPassword"`
-	}
-
-	err := httputils.ReadJSON(w, r, &requestPayload)
-	if err != nil {
-		httputils.ErrorJSON(w, err, http.StatusBadRequest)
-
-		return
-	}
-
-	if requestPayload.SecretWaterPassword == "KUARHODRON" {
-		s.CompleteTask(w, r, consts.FixedReardForSecretTask)
-
-		return
-	}
-
-	httputils.ErrorJSON(w, errormsg.ErrRedeemReferrer, http.StatusBadRequest)
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid user ID"
+// @Router /users/{id}/task/XSign [post].
+func (s *RewardService) CompleteXSign(w http.ResponseWriter, r *http.Request) {
+	s.completeCatalogTask(w, r, consts.TaskSlugXSign)
 }
 
 // RetrieveOne godoc
 // @Summary Get user by ID
-// @Description Returns single user data
+// @Description Returns single user data with the points that expire in the next 30 days, the user's tier:
+// @Description the current one, the next one and the lifetime points left to reach it, and the check-in streak
 // @Tags Users
 // @Param id path int true "User ID"
 // @Produce json
@@ -356,6 +268,32 @@ func (s *RewardService) RetrieveOne(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	expiring, err := s.Repo.GetExpiringPoints(id, time.Now().Add(consts.PointsExpiryNoticePeriod))
+	if err != nil {
+		log.Printf("failed to fetch expiring points of user %d: %v", id, err)
+		httputils.ErrorJSON(w, errormsg.ErrFetchUser, http.StatusInternalServerError)
+
+		return
+	}
+
+	user.ExpiringPoints = &expiring
+
+	user.Tier, err = s.Repo.GetTierStatus(id)
+	if err != nil {
+		log.Printf("failed to fetch tier of user %d: %v", id, err)
+		httputils.ErrorJSON(w, errormsg.ErrFetchUser, http.StatusInternalServerError)
+
+		return
+	}
+
+	user.Streak, err = s.Repo.GetStreak(id)
+	if err != nil {
+		log.Printf("failed to fetch streak of user %d: %v", id, err)
+		httputils.ErrorJSON(w, errormsg.ErrFetchUser, http.StatusInternalServerError)
+
+		return
+	}
+
 	payload := calltypes.JSONResponse{
 		Error:   false,
 		Message: "Retrieved one user from the database",
@@ -372,13 +310,14 @@ func (s *RewardService) RetrieveOne(w http.ResponseWriter, r *http.Request) {
 
 // RedeemReferrer godoc
 // @Summary Redeem referrer code
-// @Description Applies referrer code to user account. Those, who entered the referrer is granted by 100 points, those, whom referrer were redeemed, claims 25 points.
+// @Description Applies referrer code to user account. Those, who entered the referrer is granted by 25 points, the owner of the code claims 100 points and the one who invited the owner claims 20 points (configurable).
 // @Tags Users
 // @Accept json
 // @Param id path int true "User ID"
 // @Param request body calltypes.ReferrerRequest true "Referrer code"
 // @Success 200 {object} calltypes.JSONResponse
 // @Failure 400 {object} calltypes.ErrorResponse "Invalid referrer code"
+// @Failure 409 {object} calltypes.ErrorResponse "Referrer already redeemed"
 // @Router /users/{id}/referrer [post].
 func (s *RewardService) RedeemReferrer(w http.ResponseWriter, r *http.Request) {
 	var requestPayload struct {
@@ -399,7 +338,13 @@ func (s *RewardService) RedeemReferrer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = s.Repo.RedeemReferrer(id, requestPayload.Referrer)
+	err = s.Repo.RedeemReferrer(id, requestPayload.Referrer, s.Config.ReferralRewards)
+	if errors.Is(err, errormsg.ErrReferrerAlreadyRedeemed) {
+		httputils.ErrorJSON(w, err, http.StatusConflict)
+
+		return
+	}
+
 	if err != nil {
 		httputils.ErrorJSON(w, errormsg.ErrRedeemReferrer, http.StatusBadRequest)
 
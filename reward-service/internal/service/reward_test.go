@@ -12,17 +12,13 @@ import (
 	"net/http/httptest"
 	"os"
 	"reward-service/api/calltypes"
+	"reward-service/api/server/middleware"
 	"reward-service/internal/service"
+	"reward-service/pkg/consts"
 	"reward-service/pkg/errormsg"
-	"strconv"
 	"strings"
 	"testing"
-)
-
-type contextKey string
-
-const (
-	userIDKey contextKey = "userID"
+	"time"
 )
 
 // MockRepository - мок репозитория для тестирования.
@@ -80,18 +76,461 @@ func (m *MockRepository) PasswordMatches(password string, user calltypes.User) (
 	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockRepository) AddPoints(id int, points int) error {
-	args := m.Called(id, points)
+func (m *MockRepository) AddPoints(entry calltypes.PointTransaction) error {
+	args := m.Called(entry)
+
+	return args.Error(0) //nolint: wrapcheck
+}
+
+func (m *MockRepository) GetTransactions(userID, cursor, limit int) ([]*calltypes.PointTransaction, error) {
+	args := m.Called(userID, cursor, limit)
+
+	transactions, ok := args.Get(0).([]*calltypes.PointTransaction)
+	if !ok {
+		return nil, fmt.Errorf("type assertion failed: expected []*calltypes.PointTransaction, got %T", args.Get(0)) //nolint: err113
+	}
+
+	return transactions, args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) RedeemReferrer(id int, referrer string, rewards calltypes.ReferralRewards) error {
+	args := m.Called(id, referrer, rewards)
+
+	return args.Error(0) //nolint: wrapcheck
+}
+
+func (m *MockRepository) GetTasks(includeUnavailable bool) ([]*calltypes.Task, error) {
+	args := m.Called(includeUnavailable)
+
+	tasks, ok := args.Get(0).([]*calltypes.Task)
+	if !ok {
+		return nil, fmt.Errorf("type assertion to []*calltypes.Task failed, got %T", args.Get(0)) //nolint: err113
+	}
+
+	return tasks, args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) GetTask(slug string) (*calltypes.Task, error) {
+	args := m.Called(slug)
+
+	task, ok := args.Get(0).(*calltypes.Task)
+	if !ok {
+		return nil, fmt.Errorf("type assertion to *calltypes.Task failed, got %T", args.Get(0)) //nolint: err113
+	}
+
+	return task, args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) CreateTask(task calltypes.Task) (int, error) {
+	args := m.Called(task)
+
+	return args.Int(0), args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) GetSecretCodes() ([]*calltypes.SecretCode, error) {
+	args := m.Called()
+
+	codes, ok := args.Get(0).([]*calltypes.SecretCode)
+	if !ok {
+		return nil, fmt.Errorf("type assertion to []*calltypes.SecretCode failed, got %T", args.Get(0)) //nolint: err113
+	}
+
+	return codes, args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) CreateSecretCode(code calltypes.SecretCode) (int, error) {
+	args := m.Called(code)
+
+	return args.Int(0), args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) RedeemSecretCode(codeHash, legacyHash string, entry calltypes.PointTransaction,
+) (*calltypes.SecretCode, error) {
+	args := m.Called(codeHash, legacyHash, entry)
+
+	code, ok := args.Get(0).(*calltypes.SecretCode)
+	if !ok {
+		return nil, fmt.Errorf("type assertion to *calltypes.SecretCode failed, got %T", args.Get(0)) //nolint: err113
+	}
+
+	return code, args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) GetCampaigns() ([]*calltypes.Campaign, error) {
+	args := m.Called()
+
+	campaigns, ok := args.Get(0).([]*calltypes.Campaign)
+	if !ok {
+		return nil, fmt.Errorf("type assertion to []*calltypes.Campaign failed, got %T", args.Get(0)) //nolint: err113
+	}
+
+	return campaigns, args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) CreateCampaign(campaign calltypes.Campaign) (int, error) {
+	args := m.Called(campaign)
+
+	return args.Int(0), args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) UpdateCampaign(campaign calltypes.Campaign) error {
+	args := m.Called(campaign)
+
+	return args.Error(0) //nolint: wrapcheck
+}
+
+func (m *MockRepository) DeleteCampaign(id int) error {
+	args := m.Called(id)
+
+	return args.Error(0) //nolint: wrapcheck
+}
+
+func (m *MockRepository) RedeemPromoCode(code string, entry calltypes.PointTransaction) (*calltypes.Campaign, error) {
+	args := m.Called(code, entry)
+
+	campaign, ok := args.Get(0).(*calltypes.Campaign)
+	if !ok {
+		return nil, fmt.Errorf("type assertion to *calltypes.Campaign failed, got %T", args.Get(0)) //nolint: err113
+	}
+
+	return campaign, args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) GetStoreItems(includeUnavailable bool) ([]*calltypes.StoreItem, error) {
+	args := m.Called(includeUnavailable)
+
+	items, ok := args.Get(0).([]*calltypes.StoreItem)
+	if !ok {
+		return nil, fmt.Errorf("type assertion to []*calltypes.StoreItem failed, got %T", args.Get(0)) //nolint: err113
+	}
+
+	return items, args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) CreateStoreItem(item calltypes.StoreItem) (int, error) {
+	args := m.Called(item)
+
+	return args.Int(0), args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) UpdateStoreItem(item calltypes.StoreItem) error {
+	args := m.Called(item)
+
+	return args.Error(0) //nolint: wrapcheck
+}
+
+func (m *MockRepository) RedeemItem(userID, itemID, actorID int) (*calltypes.Redemption, error) {
+	args := m.Called(userID, itemID, actorID)
+
+	redemption, ok := args.Get(0).(*calltypes.Redemption)
+	if !ok {
+		return nil, fmt.Errorf("type assertion to *calltypes.Redemption failed, got %T", args.Get(0)) //nolint: err113
+	}
+
+	return redemption, args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) GetRedemptions(userID int, status string) ([]*calltypes.Redemption, error) {
+	args := m.Called(userID, status)
+
+	redemptions, ok := args.Get(0).([]*calltypes.Redemption)
+	if !ok {
+		return nil, fmt.Errorf("type assertion to []*calltypes.Redemption failed, got %T", args.Get(0)) //nolint: err113
+	}
+
+	return redemptions, args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) SetRedemptionStatus(id int, status string, actorID int) (*calltypes.Redemption, error) {
+	args := m.Called(id, status, actorID)
+
+	redemption, ok := args.Get(0).(*calltypes.Redemption)
+	if !ok {
+		return nil, fmt.Errorf("type assertion to *calltypes.Redemption failed, got %T", args.Get(0)) //nolint: err113
+	}
+
+	return redemption, args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) AddVouchers(itemID int, vouchers []calltypes.Voucher) (int, error) {
+	args := m.Called(itemID, vouchers)
+
+	return args.Int(0), args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) GetVouchers(userID int) ([]*calltypes.Voucher, error) {
+	args := m.Called(userID)
+
+	vouchers, ok := args.Get(0).([]*calltypes.Voucher)
+	if !ok {
+		return nil, fmt.Errorf("type assertion to []*calltypes.Voucher failed, got %T", args.Get(0)) //nolint: err113
+	}
+
+	return vouchers, args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) GetExpiringPoints(userID int, before time.Time) (int, error) {
+	args := m.Called(userID, before)
+
+	return args.Int(0), args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) ExpirePoints(now time.Time) (int, error) {
+	args := m.Called(now)
+
+	return args.Int(0), args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) GetTiers() ([]*calltypes.Tier, error) {
+	args := m.Called()
+
+	tiers, ok := args.Get(0).([]*calltypes.Tier)
+	if !ok {
+		return nil, fmt.Errorf("type assertion to []*calltypes.Tier failed, got %T", args.Get(0)) //nolint: err113
+	}
+
+	return tiers, args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) CreateTier(tier calltypes.Tier) (int, error) {
+	args := m.Called(tier)
+
+	return args.Int(0), args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) UpdateTier(tier calltypes.Tier) error {
+	args := m.Called(tier)
+
+	return args.Error(0) //nolint: wrapcheck
+}
+
+func (m *MockRepository) DeleteTier(id int) error {
+	args := m.Called(id)
+
+	return args.Error(0) //nolint: wrapcheck
+}
+
+func (m *MockRepository) GetTierStatus(userID int) (*calltypes.TierStatus, error) {
+	args := m.Called(userID)
+
+	status, ok := args.Get(0).(*calltypes.TierStatus)
+	if !ok {
+		return nil, fmt.Errorf("type assertion to *calltypes.TierStatus failed, got %T", args.Get(0)) //nolint: err113
+	}
+
+	return status, args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) GetBadges() ([]*calltypes.Badge, error) {
+	args := m.Called()
+
+	badges, ok := args.Get(0).([]*calltypes.Badge)
+	if !ok {
+		return nil, fmt.Errorf("type assertion to []*calltypes.Badge failed, got %T", args.Get(0)) //nolint: err113
+	}
+
+	return badges, args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) CreateBadge(badge calltypes.Badge) (int, error) {
+	args := m.Called(badge)
+
+	return args.Int(0), args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) UpdateBadge(badge calltypes.Badge) error {
+	args := m.Called(badge)
+
+	return args.Error(0) //nolint: wrapcheck
+}
+
+func (m *MockRepository) GetUserBadges(userID int) ([]*calltypes.UserBadge, error) {
+	args := m.Called(userID)
+
+	badges, ok := args.Get(0).([]*calltypes.UserBadge)
+	if !ok {
+		return nil, fmt.Errorf("type assertion to []*calltypes.UserBadge failed, got %T", args.Get(0)) //nolint: err113
+	}
+
+	return badges, args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) GetStreak(userID int) (*calltypes.Streak, error) {
+	args := m.Called(userID)
+
+	streak, ok := args.Get(0).(*calltypes.Streak)
+	if !ok {
+		return nil, fmt.Errorf("type assertion to *calltypes.Streak failed, got %T", args.Get(0)) //nolint: err113
+	}
+
+	return streak, args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) CheckIn(userID int, now time.Time) (*calltypes.CheckIn, error) {
+	args := m.Called(userID, now)
+
+	checkIn, ok := args.Get(0).(*calltypes.CheckIn)
+	if !ok {
+		return nil, fmt.Errorf("type assertion to *calltypes.CheckIn failed, got %T", args.Get(0)) //nolint: err113
+	}
+
+	return checkIn, args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) BuyStreakFreeze(userID int) (*calltypes.Streak, error) {
+	args := m.Called(userID)
+
+	streak, ok := args.Get(0).(*calltypes.Streak)
+	if !ok {
+		return nil, fmt.Errorf("type assertion to *calltypes.Streak failed, got %T", args.Get(0)) //nolint: err113
+	}
+
+	return streak, args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) SetTimezone(userID int, timezone string) error {
+	args := m.Called(userID, timezone)
+
+	return args.Error(0) //nolint: wrapcheck
+}
+
+func (m *MockRepository) GetLeaderboard(since time.Time, after *calltypes.LeaderboardCursor, limit int,
+	ranking string,
+) ([]*calltypes.LeaderboardEntry, error) {
+	args := m.Called(since, after, limit, ranking)
+
+	entries, ok := args.Get(0).([]*calltypes.LeaderboardEntry)
+	if !ok {
+		return nil, fmt.Errorf("type assertion to []*calltypes.LeaderboardEntry failed, got %T", args.Get(0)) //nolint: err113
+	}
+
+	return entries, args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) GetRank(userID int, since time.Time, neighbours int, ranking string,
+) (*calltypes.UserRank, error) {
+	args := m.Called(userID, since, neighbours, ranking)
+
+	rank, ok := args.Get(0).(*calltypes.UserRank)
+	if !ok {
+		return nil, fmt.Errorf("type assertion to *calltypes.UserRank failed, got %T", args.Get(0)) //nolint: err113
+	}
+
+	return rank, args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) GetSeasons() ([]*calltypes.Season, error) {
+	args := m.Called()
+
+	seasons, ok := args.Get(0).([]*calltypes.Season)
+	if !ok {
+		return nil, fmt.Errorf("type assertion to []*calltypes.Season failed, got %T", args.Get(0)) //nolint: err113
+	}
+
+	return seasons, args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) GetSeason(id int) (*calltypes.Season, error) {
+	args := m.Called(id)
+
+	season, ok := args.Get(0).(*calltypes.Season)
+	if !ok {
+		return nil, fmt.Errorf("type assertion to *calltypes.Season failed, got %T", args.Get(0)) //nolint: err113
+	}
+
+	return season, args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) CreateSeason(season calltypes.Season) (int, error) {
+	args := m.Called(season)
+
+	return args.Int(0), args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) GetSeasonLeaderboard(season *calltypes.Season, after *calltypes.LeaderboardCursor, limit int,
+	ranking string,
+) ([]*calltypes.LeaderboardEntry, error) {
+	args := m.Called(season, after, limit, ranking)
+
+	entries, ok := args.Get(0).([]*calltypes.LeaderboardEntry)
+	if !ok {
+		return nil, fmt.Errorf("type assertion to []*calltypes.LeaderboardEntry failed, got %T", args.Get(0)) //nolint: err113
+	}
+
+	return entries, args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) CloseSeasons(now time.Time, ranking string) (int, error) {
+	args := m.Called(now, ranking)
+
+	return args.Int(0), args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) RefreshVoucherFingerprints(version int, fingerprint func(encryptedCode string) (string, error),
+) (int, error) {
+	args := m.Called(version, fingerprint)
+
+	return args.Int(0), args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) AwardRankBadges(since time.Time, ranking string) (int, error) {
+	args := m.Called(since, ranking)
+
+	return args.Int(0), args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) UpdateTask(task calltypes.Task) error {
+	args := m.Called(task)
+
+	return args.Error(0) //nolint: wrapcheck
+}
+
+func (m *MockRepository) CompleteTask(task *calltypes.Task, completion calltypes.TaskCompletion,
+	entry calltypes.PointTransaction,
+) error {
+	args := m.Called(task, completion, entry)
+
+	return args.Error(0) //nolint: wrapcheck
+}
+
+func (m *MockRepository) BeginIdempotentRequest(userID int, key, fingerprint string,
+) (*calltypes.IdempotentResponse, error) {
+	args := m.Called(userID, key, fingerprint)
+
+	response, _ := args.Get(0).(*calltypes.IdempotentResponse)
+
+	return response, args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) CompleteIdempotentRequest(userID int, key string, response calltypes.IdempotentResponse) error {
+	args := m.Called(userID, key, response)
 
 	return args.Error(0) //nolint: wrapcheck
 }
 
-func (m *MockRepository) RedeemReferrer(id int, referrer string) error {
-	args := m.Called(id, referrer)
+func (m *MockRepository) PurgeIdempotencyKeys(before time.Time) (int, error) {
+	args := m.Called(before)
+
+	return args.Int(0), args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) ReleaseIdempotentRequest(userID int, key string) error {
+	args := m.Called(userID, key)
 
 	return args.Error(0) //nolint: wrapcheck
 }
 
+func (m *MockRepository) GetReferralStats(userID, depth int) (*calltypes.ReferralStats, error) {
+	args := m.Called(userID, depth)
+
+	stats, ok := args.Get(0).(*calltypes.ReferralStats)
+	if !ok {
+		return nil, fmt.Errorf("type assertion to *calltypes.ReferralStats failed, got %T", args.Get(0)) //nolint: err113
+	}
+
+	return stats, args.Error(1) //nolint: wrapcheck
+}
+
 func (m *MockRepository) Update(user calltypes.User) error {
 	args := m.Called(user)
 
@@ -115,12 +554,78 @@ func (m *MockRepository) UpdateScore(user calltypes.User) error {
 	return args.Error(0) //nolint: wrapcheck
 }
 
-func (m *MockRepository) StoreRefreshToken(userID int, hashedToken string) error {
-	args := m.Called(userID, hashedToken)
+func (m *MockRepository) SetRole(userID int, role string) error {
+	args := m.Called(userID, role)
 
 	return args.Error(0) //nolint: wrapcheck
 }
 
+func (m *MockRepository) EnsureAdmin(email, password string) (int, error) {
+	args := m.Called(email, password)
+
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) CreateSession(session calltypes.Session, refreshToken calltypes.RefreshToken) (int, error) {
+	args := m.Called(session, refreshToken)
+
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) RotateSession(
+	oldToken, newToken calltypes.RefreshToken, userAgent, ip string,
+) (*calltypes.Session, error) {
+	args := m.Called(oldToken, newToken, userAgent, ip)
+
+	session, ok := args.Get(0).(*calltypes.Session)
+	if !ok {
+		return nil, args.Error(1) //nolint: wrapcheck
+	}
+
+	return session, args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) GetSessions(userID int) ([]*calltypes.Session, error) {
+	args := m.Called(userID)
+
+	sessions, ok := args.Get(0).([]*calltypes.Session)
+	if !ok {
+		return nil, fmt.Errorf("type assertion failed: expected []*calltypes.Session, got %T", args.Get(0)) //nolint: err113
+	}
+
+	return sessions, args.Error(1) //nolint: wrapcheck
+}
+
+func (m *MockRepository) RevokeSession(userID, sessionID int) error {
+	args := m.Called(userID, sessionID)
+
+	return args.Error(0) //nolint: wrapcheck
+}
+
+func (m *MockRepository) RevokeSessionByToken(refreshToken calltypes.RefreshToken) error {
+	args := m.Called(refreshToken)
+
+	return args.Error(0) //nolint: wrapcheck
+}
+
+func (m *MockRepository) RevokeAllSessions(userID int) error {
+	args := m.Called(userID)
+
+	return args.Error(0) //nolint: wrapcheck
+}
+
+func (m *MockRepository) RevokeAccessToken(tokenID string, userID int, expiresAt time.Time) error {
+	args := m.Called(tokenID, userID, expiresAt)
+
+	return args.Error(0) //nolint: wrapcheck
+}
+
+func (m *MockRepository) IsAccessTokenRevoked(tokenID string, userID, sessionID int, issuedAt time.Time) (bool, error) {
+	args := m.Called(tokenID, userID, sessionID, issuedAt)
+
+	return args.Bool(0), args.Error(1)
+}
+
 func TestRewardService_Registrate(t *testing.T) {
 	t.Parallel()
 
@@ -145,6 +650,24 @@ func TestRewardService_Registrate(t *testing.T) {
 			expectedStatus: http.StatusAccepted,
 			expectedError:  false,
 		},
+		{
+			name: "Balance and activity can't be set",
+			requestBody: `{
+				"email": "test@example.com",
+				"firstName": "Test",
+				"lastName": "User",
+				"password": "securepassword123",
+				"score": 1000000,
+				"active": 0
+			}`,
+			mockSetup: func(m *MockRepository) {
+				m.On("Insert", mock.MatchedBy(func(u calltypes.User) bool {
+					return u.Score == 0 && u.Active == 1
+				})).Return(1, nil)
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedError:  false,
+		},
 		{
 			name: "Short password",
 			requestBody: `{
@@ -171,6 +694,51 @@ func TestRewardService_Registrate(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedError:  true,
 		},
+		{
+			name: "Vanity referral code is normalized",
+			requestBody: `{
+				"email": "test@example.com",
+				"firstName": "Test",
+				"lastName": "User",
+				"password": "securepassword123",
+				"referrer": " summer-2025 "
+			}`,
+			mockSetup: func(m *MockRepository) {
+				m.On("Insert", mock.MatchedBy(func(u calltypes.User) bool {
+					return u.Referrer == "SUMMER-2025"
+				})).Return(1, nil)
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedError:  false,
+		},
+		{
+			name: "Invalid vanity referral code",
+			requestBody: `{
+				"email": "test@example.com",
+				"firstName": "Test",
+				"lastName": "User",
+				"password": "securepassword123",
+				"referrer": "no spaces allowed"
+			}`,
+			mockSetup:      func(_ *MockRepository) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  true,
+		},
+		{
+			name: "Vanity referral code is taken",
+			requestBody: `{
+				"email": "test@example.com",
+				"firstName": "Test",
+				"lastName": "User",
+				"password": "securepassword123",
+				"referrer": "summer-2025"
+			}`,
+			mockSetup: func(m *MockRepository) {
+				m.On("Insert", mock.AnythingOfType("calltypes.User")).Return(0, errormsg.ErrReferralCodeTaken)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedError:  true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -223,7 +791,8 @@ func TestRewardService_Authenticate(t *testing.T) {
 				}
 				m.On("GetByEmail", "test@example.com").Return(user, nil)
 				m.On("PasswordMatches", "correctpassword", *user).Return(true, nil)
-				m.On("StoreRefreshToken", user.ID, mock.AnythingOfType("string")).Return(nil)
+				m.On("CreateSession", mock.AnythingOfType("calltypes.Session"), mock.AnythingOfType("calltypes.RefreshToken")).
+					Return(1, nil)
 			},
 			expectedStatus: http.StatusOK,
 		},
@@ -293,29 +862,63 @@ func TestRewardService_Authenticate(t *testing.T) {
 func TestRewardService_GetLeaderboard(t *testing.T) {
 	t.Parallel()
 
+	entries := []*calltypes.LeaderboardEntry{
+		{Rank: 1, UserID: 2, DisplayName: "User2", Score: 200},
+		{Rank: 2, UserID: 1, DisplayName: "User1", Score: 100},
+		{Rank: 2, UserID: 3, DisplayName: "User3", Score: 100},
+	}
+
 	tests := []struct {
 		name           string
+		query          string
 		mockSetup      func(*MockRepository)
 		expectedStatus int
+		expectedBody   string
 	}{
 		{
-			name: "Successful fetch",
+			name:  "Successful fetch",
+			query: "?limit=2",
 			mockSetup: func(m *MockRepository) {
-				users := []*calltypes.User{
-					{ID: 1, FirstName: "User1", Score: 100},
-					{ID: 2, FirstName: "User2", Score: 200},
-				}
-				m.On("GetAll").Return(users, nil)
+				m.On("GetLeaderboard", time.Time{}, (*calltypes.LeaderboardCursor)(nil), 3, consts.RankingCompetition).
+					Return(entries, nil)
 			},
 			expectedStatus: http.StatusOK,
+			expectedBody:   `"nextCursor":"MTAwOjE"`,
 		},
 		{
-			name: "Repository error",
+			name:  "Next page of the weekly leaderboard",
+			query: "?period=week&cursor=MTAwOjE",
 			mockSetup: func(m *MockRepository) {
-				m.On("GetAll").Return([]*calltypes.User{}, errormsg.ErrRepositoryError)
+				m.On("GetLeaderboard", mock.MatchedBy(func(since time.Time) bool {
+					return since.Weekday() == time.Monday && time.Since(since) < 7*24*time.Hour
+				}), &calltypes.LeaderboardCursor{Score: 100, UserID: 1}, consts.DefaultPageLimit+1, consts.RankingCompetition).
+					Return(entries[2:], nil)
 			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"displayName":"User3"`,
+		},
+		{
+			name:           "Unknown period",
+			query:          "?period=year",
+			mockSetup:      func(_ *MockRepository) {},
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name:           "Invalid cursor",
+			query:          "?cursor=abc",
+			mockSetup:      func(_ *MockRepository) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "Repository error",
+			query: "",
+			mockSetup: func(m *MockRepository) {
+				m.On("GetLeaderboard", time.Time{}, (*calltypes.LeaderboardCursor)(nil), consts.DefaultPageLimit+1,
+					consts.RankingCompetition).
+					Return([]*calltypes.LeaderboardEntry{}, errormsg.ErrRepositoryError)
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
 	}
 
 	for _, tt := range tests {
@@ -327,13 +930,18 @@ func TestRewardService_GetLeaderboard(t *testing.T) {
 
 			svc := service.NewRewardService(mockRepo)
 
-			req := httptest.NewRequest(http.MethodGet, "/users/leaderboard", nil)
+			req := httptest.NewRequest(http.MethodGet, "/users/leaderboard"+tt.query, nil)
 
 			rr := httptest.NewRecorder()
 
 			svc.GetLeaderboard(rr, req)
 
 			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.NotContains(t, rr.Body.String(), "email")
+
+			if tt.expectedBody != "" {
+				assert.Contains(t, rr.Body.String(), tt.expectedBody)
+			}
 
 			mockRepo.AssertExpectations(t)
 		})
@@ -391,6 +999,22 @@ func TestRewardService_RetrieveOne(t *testing.T) {
 				mockRepo.On("GetOne", 123).Return(tt.repoResponse, tt.repoError)
 			}
 
+			if tt.repoResponse != nil {
+				mockRepo.On("GetExpiringPoints", 123, mock.AnythingOfType("time.Time")).Return(150, nil)
+				mockRepo.On("GetTierStatus", 123).Return(&calltypes.TierStatus{
+					LifetimePoints: 1200,
+					Current:        &calltypes.Tier{ID: 2, Name: "Silver", MinPoints: 1000, MultiplierPercent: 110},
+					Next:           &calltypes.Tier{ID: 3, Name: "Gold", MinPoints: 5000, MultiplierPercent: 125},
+					PointsToNext:   3800,
+				}, nil)
+				mockRepo.On("GetStreak", 123).Return(&calltypes.Streak{
+					Current:     3,
+					Longest:     5,
+					LastCheckIn: "2025-09-01",
+					Timezone:    "UTC",
+				}, nil)
+			}
+
 			svc := &service.RewardService{Repo: mockRepo}
 
 			req, err := http.NewRequest(http.MethodGet, "/users/"+tt.urlID+"/status", nil)
@@ -400,8 +1024,7 @@ func TestRewardService_RetrieveOne(t *testing.T) {
 			rctx.URLParams.Add("id", tt.urlID)
 			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
 
-			ctx := context.WithValue(req.Context(), userIDKey, "test-user")
-			req = req.WithContext(ctx)
+			req = req.WithContext(middleware.WithIdentity(req.Context(), middleware.Identity{UserID: 123}))
 
 			rr := httptest.NewRecorder()
 
@@ -422,85 +1045,11 @@ func TestRewardService_RetrieveOne(t *testing.T) {
 	}
 }
 
-func TestRewardService_CompleteTask(t *testing.T) {
-	t.Parallel()
-
-	tests := []struct {
-		name          string
-		urlID         string
-		points        int
-		repoError     error
-		expectedCode  int
-		expectedError bool
-	}{
-		{
-			name:          "successful points addition",
-			urlID:         "123",
-			points:        100,
-			repoError:     nil,
-			expectedCode:  http.StatusOK,
-			expectedError: false,
-		},
-		{
-			name:          "invalid ID in URL",
-			urlID:         "abc",
-			points:        100,
-			repoError:     nil,
-			expectedCode:  http.StatusBadRequest,
-			expectedError: true,
-		},
-		{
-			name:          "repository error",
-			urlID:         "123",
-			points:        100,
-			repoError:     errormsg.ErrRepositoryError,
-			expectedCode:  http.StatusBadRequest,
-			expectedError: true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Parallel()
-
-			mockRepo := new(MockRepository)
-
-			if tt.urlID == "123" {
-				id, _ := strconv.Atoi(tt.urlID)
-				mockRepo.On("AddPoints", id, tt.points).Return(tt.repoError)
-			}
-
-			svc := &service.RewardService{Repo: mockRepo}
-
-			req, err := http.NewRequest(http.MethodPost, "/users/"+tt.urlID+"/task/complete", nil)
-			require.NoError(t, err)
-
-			rctx := chi.NewRouteContext()
-			rctx.URLParams.Add("id", tt.urlID)
-			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
-
-			rr := httptest.NewRecorder()
-
-			svc.CompleteTask(rr, req, tt.points)
-
-			assert.Equal(t, tt.expectedCode, rr.Code)
-
-			if !tt.expectedError {
-				var response calltypes.JSONResponse
-				err := json.NewDecoder(rr.Body).Decode(&response)
-				require.NoError(t, err)
-				assert.False(t, response.Error)
-				assert.Contains(t, response.Message, "complete task worked for user with id "+tt.urlID)
-			}
-
-			mockRepo.AssertExpectations(t)
-		})
-	}
-}
-
 func TestRewardService_RedeemReferrer(t *testing.T) {
 	t.Parallel()
 
+	rewards := service.DefaultConfig().ReferralRewards
+
 	tests := []struct {
 		name          string
 		urlID         string
@@ -514,7 +1063,7 @@ func TestRewardService_RedeemReferrer(t *testing.T) {
 			urlID:    "123",
 			referrer: "ref123",
 			setupMock: func(m *MockRepository) {
-				m.On("RedeemReferrer", 123, "ref123").Return(nil)
+				m.On("RedeemReferrer", 123, "ref123", rewards).Return(nil)
 			},
 			expectedCode:  http.StatusOK,
 			expectedError: false,
@@ -532,11 +1081,21 @@ func TestRewardService_RedeemReferrer(t *testing.T) {
 			urlID:    "123",
 			referrer: "ref123",
 			setupMock: func(m *MockRepository) {
-				m.On("RedeemReferrer", 123, "ref123").Return(errormsg.ErrRepositoryError)
+				m.On("RedeemReferrer", 123, "ref123", rewards).Return(errormsg.ErrRepositoryError)
 			},
 			expectedCode:  http.StatusBadRequest,
 			expectedError: true,
 		},
+		{
+			name:     "referrer already redeemed",
+			urlID:    "123",
+			referrer: "ref123",
+			setupMock: func(m *MockRepository) {
+				m.On("RedeemReferrer", 123, "ref123", rewards).Return(errormsg.ErrReferrerAlreadyRedeemed)
+			},
+			expectedCode:  http.StatusConflict,
+			expectedError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -546,7 +1105,7 @@ func TestRewardService_RedeemReferrer(t *testing.T) {
 			mockRepo := new(MockRepository)
 			tt.setupMock(mockRepo)
 
-			svc := &service.RewardService{Repo: mockRepo}
+			svc := service.NewRewardService(mockRepo)
 
 			requestBody := fmt.Sprintf(`{"referrer": "%s"}`, tt.referrer)
 			req, err := http.NewRequest(http.MethodPost, "/users/"+tt.urlID+"/referrer", strings.NewReader(requestBody))
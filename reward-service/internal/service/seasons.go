@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reward-service/api/calltypes"
+	"reward-service/api/server/httputils"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"time"
+)
+
+// ListSeasons godoc
+// @Summary List seasons
+// @Description Returns all leaderboard seasons with their prizes and statuses, the latest first
+// @Tags Seasons
+// @Produce json
+// @Success 200 {object} calltypes.JSONResponse{data=[]calltypes.Season}
+// @Failure 401 {object} calltypes.ErrorResponse "Unauthorized"
+// @Failure 500 {object} calltypes.ErrorResponse "Failed to fetch seasons"
+// @Router /seasons [get].
+func (s *RewardService) ListSeasons(w http.ResponseWriter, _ *http.Request) {
+	seasons, err := s.Repo.GetSeasons()
+	if err != nil {
+		log.Printf("failed to fetch seasons: %v", err)
+		httputils.ErrorJSON(w, errormsg.ErrFetchSeasons, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: "Fetched seasons",
+		Data:    seasons,
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// GetSeasonLeaderboard godoc
+// @Summary Get season leaderboard
+// @Description Returns the season leaderboard with cursor pagination. Until the season is closed the points
+// @Description earned within it are ranked live, after that the archived final standings with the prizes are returned.
+// @Tags Seasons
+// @Produce json
+// @Param id path int true "Season ID"
+// @Param limit query int false "Page size" default(20)
+// @Param cursor query string false "Cursor from the previous page"
+// @Success 200 {object} calltypes.JSONResponse{data=calltypes.SeasonLeaderboardPage}
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid season ID, limit or cursor"
+// @Failure 404 {object} calltypes.ErrorResponse "Season not found"
+// @Failure 500 {object} calltypes.ErrorResponse "Failed to fetch leaderboard"
+// @Router /seasons/{id}/leaderboard [get].
+func (s *RewardService) GetSeasonLeaderboard(w http.ResponseWriter, r *http.Request) {
+	id, err := GetIDFromURL(r, "id")
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidID, http.StatusBadRequest)
+
+		return
+	}
+
+	limit, err := parseLimit(r)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	var after *calltypes.LeaderboardCursor
+
+	if rawCursor := r.URL.Query().Get("cursor"); rawCursor != "" {
+		after, err = decodeLeaderboardCursor(rawCursor)
+		if err != nil {
+			httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	season, err := s.Repo.GetSeason(id)
+
+	switch {
+	case errors.Is(err, errormsg.ErrSeasonNotFound):
+		httputils.ErrorJSON(w, err, http.StatusNotFound)
+
+		return
+	case err != nil:
+		log.Printf("failed to fetch season %d: %v", id, err)
+		httputils.ErrorJSON(w, errormsg.ErrFetchSeasons, http.StatusInternalServerError)
+
+		return
+	}
+
+	// One extra entry tells whether there is a next page.
+	entries, err := s.Repo.GetSeasonLeaderboard(season, after, limit+1, s.Config.Ranking)
+	if err != nil {
+		log.Printf("failed to fetch leaderboard of season %d: %v", id, err)
+		httputils.ErrorJSON(w, errormsg.ErrFetchLeaderboard, http.StatusInternalServerError)
+
+		return
+	}
+
+	page := calltypes.SeasonLeaderboardPage{Season: season, Items: entries}
+
+	if len(entries) > limit {
+		page.Items = entries[:limit]
+		page.NextCursor = encodeLeaderboardCursor(page.Items[limit-1])
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: "Fetched leaderboard of season " + season.Name,
+		Data:    page,
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// CreateSeason godoc
+// @Summary Create season
+// @Description Adds new leaderboard season. When it ends its standings are archived and the prizes are paid
+// @Description to the users of the ranks 1, 2, 3 and so on. Available to admins.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body calltypes.SeasonRequest true "Season"
+// @Success 201 {object} calltypes.JSONResponse
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid season"
+// @Failure 403 {object} calltypes.ErrorResponse "Insufficient role"
+// @Router /admin/seasons [post].
+func (s *RewardService) CreateSeason(w http.ResponseWriter, r *http.Request) {
+	var requestPayload calltypes.SeasonRequest
+
+	err := httputils.ReadJSON(w, r, &requestPayload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	season, err := seasonFromRequest(requestPayload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	id, err := s.Repo.CreateSeason(season)
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrSaveSeason, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: fmt.Sprintf("Created season %s, id: %d", season.Name, id),
+	}
+
+	err = httputils.WriteJSON(w, http.StatusCreated, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// RunSeasonClosing closes the seasons that ended every interval until ctx is done.
+func (s *RewardService) RunSeasonClosing(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.closeSeasons()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *RewardService) closeSeasons() {
+	closed, err := s.Repo.CloseSeasons(time.Now(), s.Config.Ranking)
+	if err != nil {
+		// Seasons that were not closed are picked up by the next run.
+		log.Printf("failed to close seasons: %v", err)
+	}
+
+	if closed > 0 {
+		log.Printf("Closed %d seasons", closed)
+	}
+}
+
+// seasonFromRequest validates the request.
+func seasonFromRequest(request calltypes.SeasonRequest) (calltypes.Season, error) {
+	if request.Name == "" || request.StartsAt.IsZero() || !request.EndsAt.After(request.StartsAt) ||
+		len(request.Prizes) > consts.MaxSeasonPrizes {
+		return calltypes.Season{}, errormsg.ErrInvalidSeason
+	}
+
+	for _, points := range request.Prizes {
+		if points < 0 {
+			return calltypes.Season{}, errormsg.ErrInvalidSeason
+		}
+	}
+
+	return calltypes.Season{
+		Name:     request.Name,
+		StartsAt: request.StartsAt,
+		EndsAt:   request.EndsAt,
+		Prizes:   request.Prizes,
+	}, nil
+}
@@ -0,0 +1,170 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reward-service/api/calltypes"
+	"reward-service/internal/service"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRewardService_GetSeasonLeaderboard(t *testing.T) {
+	t.Parallel()
+
+	closedAt := time.Date(2025, time.October, 1, 0, 30, 0, 0, time.UTC)
+	season := &calltypes.Season{
+		ID:       3,
+		Name:     "September 2025",
+		StartsAt: time.Date(2025, time.September, 1, 0, 0, 0, 0, time.UTC),
+		EndsAt:   time.Date(2025, time.October, 1, 0, 0, 0, 0, time.UTC),
+		Prizes:   []int{1000, 500},
+		Status:   consts.SeasonClosed,
+		ClosedAt: &closedAt,
+	}
+
+	tests := []struct {
+		name         string
+		urlID        string
+		setupMock    func(*MockRepository)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:  "final standings",
+			urlID: "3",
+			setupMock: func(m *MockRepository) {
+				m.On("GetSeason", 3).Return(season, nil)
+				m.On("GetSeasonLeaderboard", season, (*calltypes.LeaderboardCursor)(nil), consts.DefaultPageLimit+1,
+					consts.RankingCompetition).Return([]*calltypes.LeaderboardEntry{
+					{Rank: 1, UserID: 7, DisplayName: "Anna K.", Score: 900, Prize: 1000},
+				}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `"prize":1000`,
+		},
+		{
+			name:  "season not found",
+			urlID: "4",
+			setupMock: func(m *MockRepository) {
+				m.On("GetSeason", 4).Return((*calltypes.Season)(nil), errormsg.ErrSeasonNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "invalid season ID",
+			urlID:        "abc",
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(MockRepository)
+			tt.setupMock(mockRepo)
+
+			svc := service.NewRewardService(mockRepo)
+
+			req := httptest.NewRequest(http.MethodGet, "/seasons/"+tt.urlID+"/leaderboard", nil)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.urlID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+
+			svc.GetSeasonLeaderboard(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+
+			if tt.expectedBody != "" {
+				assert.Contains(t, rr.Body.String(), tt.expectedBody)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRewardService_CreateSeason(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		requestBody  string
+		setupMock    func(*MockRepository)
+		expectedCode int
+	}{
+		{
+			name: "season is created",
+			requestBody: `{"name": "September 2025", "startsAt": "2025-09-01T00:00:00Z", "endsAt": "2025-10-01T00:00:00Z",
+				"prizes": [1000, 500, 250]}`,
+			setupMock: func(m *MockRepository) {
+				m.On("CreateSeason", mock.MatchedBy(func(season calltypes.Season) bool {
+					return season.Name == "September 2025" && len(season.Prizes) == 3
+				})).Return(1, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "season ends before it starts",
+			requestBody:  `{"name": "Backwards", "startsAt": "2025-10-01T00:00:00Z", "endsAt": "2025-09-01T00:00:00Z"}`,
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "negative prize",
+			requestBody: `{"name": "September 2025", "startsAt": "2025-09-01T00:00:00Z", "endsAt": "2025-10-01T00:00:00Z",
+				"prizes": [-100]}`,
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(MockRepository)
+			tt.setupMock(mockRepo)
+
+			svc := service.NewRewardService(mockRepo)
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/seasons", strings.NewReader(tt.requestBody))
+			rr := httptest.NewRecorder()
+
+			svc.CreateSeason(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRewardService_RunSeasonClosing(t *testing.T) {
+	t.Parallel()
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("CloseSeasons", mock.AnythingOfType("time.Time"), consts.RankingCompetition).Return(1, nil).Once()
+
+	svc := service.NewRewardService(mockRepo)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// The seasons are closed once on start, the cancelled context stops the job before the first tick.
+	svc.RunSeasonClosing(ctx, time.Hour)
+
+	mockRepo.AssertExpectations(t)
+}
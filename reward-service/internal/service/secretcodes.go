@@ -0,0 +1,225 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reward-service/api/calltypes"
+	"reward-service/api/server/httputils"
+	"reward-service/api/server/middleware"
+	"reward-service/internal/secretcode"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"strconv"
+)
+
+// RedeemSecretCode godoc
+// @Summary Redeem secret code
+// @Description Completes the secret-code quest the code belongs to and awards its reward. After 5 wrong codes
+// @Description within 15 minutes the user has to wait before trying again, so does everyone after 200 wrong
+// @Description codes of all users.
+// @Tags Tasks
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body calltypes.RedeemCodeRequest true "Secret code"
+// @Success 200 {object} calltypes.JSONResponse
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid user ID or wrong code"
+// @Failure 409 {object} calltypes.ErrorResponse "Code is expired, exhausted or already redeemed by the user"
+// @Failure 429 {object} calltypes.ErrorResponse "Too many wrong codes"
+// @Failure 503 {object} calltypes.ErrorResponse "Secret codes are not configured"
+// @Router /users/{id}/codes/redeem [post].
+func (s *RewardService) RedeemSecretCode(w http.ResponseWriter, r *http.Request) {
+	if s.SecretCodes == nil {
+		httputils.ErrorJSON(w, errormsg.ErrSecretCodesDisabled, http.StatusServiceUnavailable)
+
+		return
+	}
+
+	id, err := GetIDFromURL(r, "id")
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidID, http.StatusBadRequest)
+
+		return
+	}
+
+	var requestPayload calltypes.RedeemCodeRequest
+
+	err = httputils.ReadJSON(w, r, &requestPayload)
+	if err != nil || secretcode.Normalize(requestPayload.Code) == "" {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidSecretCode, http.StatusBadRequest)
+
+		return
+	}
+
+	actorID, _ := middleware.UserIDFromContext(r.Context())
+
+	code, err := s.Repo.RedeemSecretCode(s.SecretCodes.Hash(requestPayload.Code),
+		secretcode.LegacyHash(requestPayload.Code), calltypes.PointTransaction{
+			UserID:  id,
+			Reason:  consts.ReasonSecretCode,
+			ActorID: actorID,
+		})
+
+	switch {
+	case errors.Is(err, errormsg.ErrTooManyCodeAttempts):
+		w.Header().Set("Retry-After", strconv.Itoa(int(consts.SecretCodeAttemptWindow.Seconds())))
+		httputils.ErrorJSON(w, err, http.StatusTooManyRequests)
+
+		return
+	case errors.Is(err, errormsg.ErrSecretCodeNotFound):
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	case errors.Is(err, errormsg.ErrSecretCodeExpired) || errors.Is(err, errormsg.ErrSecretCodeExhausted) ||
+		errors.Is(err, errormsg.ErrSecretCodeAlreadyRedeemed):
+		httputils.ErrorJSON(w, err, http.StatusConflict)
+
+		return
+	case err != nil:
+		log.Printf("failed to redeem secret code for user %d: %v", id, err)
+		httputils.ErrorJSON(w, errormsg.ErrRedeemSecretCode, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: fmt.Sprintf("Redeemed %s for user with id %d, added points %d", code.Title, id, code.Reward),
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// ListSecretCodes godoc
+// @Summary List secret-code quests
+// @Description Returns all secret-code quests with the number of their redemptions, the codes themselves
+// @Description are not stored. Available to admins.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} calltypes.JSONResponse{data=[]calltypes.SecretCode}
+// @Failure 403 {object} calltypes.ErrorResponse "Insufficient role"
+// @Failure 500 {object} calltypes.ErrorResponse "Failed to fetch quests"
+// @Router /admin/codes [get].
+func (s *RewardService) ListSecretCodes(w http.ResponseWriter, _ *http.Request) {
+	codes, err := s.Repo.GetSecretCodes()
+	if err != nil {
+		log.Printf("failed to fetch secret codes: %v", err)
+		httputils.ErrorJSON(w, errormsg.ErrFetchSecretCodes, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: "Fetched secret code quests",
+		Data:    codes,
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// CreateSecretCode godoc
+// @Summary Create secret-code quest
+// @Description Adds new secret-code quest, only the hash of the code is stored. Available to admins.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body calltypes.SecretCodeRequest true "Quest"
+// @Success 201 {object} calltypes.JSONResponse
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid quest"
+// @Failure 403 {object} calltypes.ErrorResponse "Insufficient role"
+// @Failure 409 {object} calltypes.ErrorResponse "Code is used by another quest"
+// @Failure 503 {object} calltypes.ErrorResponse "Secret codes are not configured"
+// @Router /admin/codes [post].
+func (s *RewardService) CreateSecretCode(w http.ResponseWriter, r *http.Request) {
+	if s.SecretCodes == nil {
+		httputils.ErrorJSON(w, errormsg.ErrSecretCodesDisabled, http.StatusServiceUnavailable)
+
+		return
+	}
+
+	var requestPayload calltypes.SecretCodeRequest
+
+	err := httputils.ReadJSON(w, r, &requestPayload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	code, err := secretCodeFromRequest(s.SecretCodes, requestPayload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	id, err := s.Repo.CreateSecretCode(code)
+	if errors.Is(err, errormsg.ErrSecretCodeTaken) {
+		httputils.ErrorJSON(w, err, http.StatusConflict)
+
+		return
+	}
+
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrSaveSecretCode, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: fmt.Sprintf("Created secret code quest %s, id: %d", code.Title, id),
+	}
+
+	err = httputils.WriteJSON(w, http.StatusCreated, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// secretCodeFromRequest validates the request and hashes its code with hasher.
+func secretCodeFromRequest(hasher *secretcode.Hasher, request calltypes.SecretCodeRequest,
+) (calltypes.SecretCode, error) {
+	if err := secretcode.Validate(request.Code); err != nil {
+		return calltypes.SecretCode{}, err //nolint: wrapcheck
+	}
+
+	if request.Title == "" || request.Reward <= 0 || request.MaxRedemptions < 0 || request.PerUserLimit < 0 {
+		return calltypes.SecretCode{}, errormsg.ErrInvalidSecretCodeQuest
+	}
+
+	code := calltypes.SecretCode{
+		Title:          request.Title,
+		CodeHash:       hasher.Hash(request.Code),
+		Reward:         request.Reward,
+		MaxRedemptions: request.MaxRedemptions,
+		PerUserLimit:   request.PerUserLimit,
+		Active:         true,
+		ExpiresAt:      request.ExpiresAt,
+	}
+
+	if code.PerUserLimit == 0 {
+		code.PerUserLimit = 1
+	}
+
+	if request.Active != nil {
+		code.Active = *request.Active
+	}
+
+	return code, nil
+}
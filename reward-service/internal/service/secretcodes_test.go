@@ -0,0 +1,201 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reward-service/api/calltypes"
+	"reward-service/internal/secretcode"
+	"reward-service/internal/service"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestHasher(t *testing.T) *secretcode.Hasher {
+	t.Helper()
+
+	hasher, err := secretcode.NewHasher(bytes.Repeat([]byte{7}, 32))
+	require.NoError(t, err)
+
+	return hasher
+}
+
+func TestRewardService_RedeemSecretCode(t *testing.T) {
+	t.Parallel()
+
+	hasher := newTestHasher(t)
+	quest := calltypes.SecretCode{ID: 1, Title: "Kuarhodron", Reward: 10000, PerUserLimit: 1, Active: true}
+
+	tests := []struct {
+		name         string
+		requestBody  string
+		disabled     bool
+		setupMock    func(*MockRepository)
+		expectedCode int
+	}{
+		{
+			name:        "code is redeemed",
+			requestBody: `{"code": " kuarhodron "}`,
+			setupMock: func(m *MockRepository) {
+				m.On("RedeemSecretCode", hasher.Hash("KUARHODRON"), secretcode.LegacyHash("KUARHODRON"),
+					mock.MatchedBy(func(entry calltypes.PointTransaction) bool {
+						return entry.UserID == 123 && entry.Reason == consts.ReasonSecretCode
+					})).Return(&quest, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:        "wrong code",
+			requestBody: `{"code": "guess"}`,
+			setupMock: func(m *MockRepository) {
+				m.On("RedeemSecretCode", hasher.Hash("guess"), secretcode.LegacyHash("guess"),
+					mock.AnythingOfType("calltypes.PointTransaction")).
+					Return((*calltypes.SecretCode)(nil), errormsg.ErrSecretCodeNotFound)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:        "too many wrong codes",
+			requestBody: `{"code": "KUARHODRON"}`,
+			setupMock: func(m *MockRepository) {
+				m.On("RedeemSecretCode", hasher.Hash("KUARHODRON"), secretcode.LegacyHash("KUARHODRON"),
+					mock.AnythingOfType("calltypes.PointTransaction")).
+					Return((*calltypes.SecretCode)(nil), errormsg.ErrTooManyCodeAttempts)
+			},
+			expectedCode: http.StatusTooManyRequests,
+		},
+		{
+			name:        "already redeemed",
+			requestBody: `{"code": "KUARHODRON"}`,
+			setupMock: func(m *MockRepository) {
+				m.On("RedeemSecretCode", hasher.Hash("KUARHODRON"), secretcode.LegacyHash("KUARHODRON"),
+					mock.AnythingOfType("calltypes.PointTransaction")).
+					Return((*calltypes.SecretCode)(nil), errormsg.ErrSecretCodeAlreadyRedeemed)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:        "code is expired",
+			requestBody: `{"code": "KUARHODRON"}`,
+			setupMock: func(m *MockRepository) {
+				m.On("RedeemSecretCode", hasher.Hash("KUARHODRON"), secretcode.LegacyHash("KUARHODRON"),
+					mock.AnythingOfType("calltypes.PointTransaction")).
+					Return((*calltypes.SecretCode)(nil), errormsg.ErrSecretCodeExpired)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "empty code",
+			requestBody:  `{"code": "  "}`,
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "secret codes are not configured",
+			requestBody:  `{"code": "KUARHODRON"}`,
+			disabled:     true,
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(MockRepository)
+			tt.setupMock(mockRepo)
+
+			svc := service.NewRewardService(mockRepo)
+			if !tt.disabled {
+				svc.SecretCodes = hasher
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/users/123/codes/redeem", strings.NewReader(tt.requestBody))
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "123")
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+
+			svc.RedeemSecretCode(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRewardService_CreateSecretCode(t *testing.T) {
+	t.Parallel()
+
+	hasher := newTestHasher(t)
+
+	tests := []struct {
+		name         string
+		requestBody  string
+		setupMock    func(*MockRepository)
+		expectedCode int
+	}{
+		{
+			name:        "only the hash of the code is saved",
+			requestBody: `{"code": "water-password", "title": "Water password", "reward": 500}`,
+			setupMock: func(m *MockRepository) {
+				m.On("CreateSecretCode", mock.MatchedBy(func(code calltypes.SecretCode) bool {
+					return code.CodeHash == hasher.Hash("WATER-PASSWORD") && code.PerUserLimit == 1 && code.Active
+				})).Return(2, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "code is too short",
+			requestBody:  `{"code": "abcdefg", "title": "Water password", "reward": 500}`,
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "non-positive reward",
+			requestBody:  `{"code": "water-password", "title": "Water password", "reward": 0}`,
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:        "code is taken",
+			requestBody: `{"code": "water-password", "title": "Water password", "reward": 500}`,
+			setupMock: func(m *MockRepository) {
+				m.On("CreateSecretCode", mock.AnythingOfType("calltypes.SecretCode")).
+					Return(0, errormsg.ErrSecretCodeTaken)
+			},
+			expectedCode: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(MockRepository)
+			tt.setupMock(mockRepo)
+
+			svc := service.NewRewardService(mockRepo)
+			svc.SecretCodes = hasher
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/codes", strings.NewReader(tt.requestBody))
+			rr := httptest.NewRecorder()
+
+			svc.CreateSecretCode(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,403 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reward-service/api/calltypes"
+	"reward-service/api/server/httputils"
+	"reward-service/api/server/middleware"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+)
+
+// ListStoreItems godoc
+// @Summary List store items
+// @Description Returns active items of the rewards store that are in stock, cheapest first
+// @Tags Store
+// @Produce json
+// @Success 200 {object} calltypes.JSONResponse{data=[]calltypes.StoreItem}
+// @Failure 401 {object} calltypes.ErrorResponse "Unauthorized"
+// @Failure 500 {object} calltypes.ErrorResponse "Failed to fetch store items"
+// @Router /store/items [get].
+func (s *RewardService) ListStoreItems(w http.ResponseWriter, _ *http.Request) {
+	s.writeStoreItems(w, false)
+}
+
+// ListAllStoreItems godoc
+// @Summary List all store items
+// @Description Returns all items of the rewards store including inactive and sold out ones. Available to admins.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} calltypes.JSONResponse{data=[]calltypes.StoreItem}
+// @Failure 403 {object} calltypes.ErrorResponse "Insufficient role"
+// @Failure 500 {object} calltypes.ErrorResponse "Failed to fetch store items"
+// @Router /admin/store/items [get].
+func (s *RewardService) ListAllStoreItems(w http.ResponseWriter, _ *http.Request) {
+	s.writeStoreItems(w, true)
+}
+
+// CreateStoreItem godoc
+// @Summary Create store item
+// @Description Adds new item to the rewards store. Available to admins.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body calltypes.StoreItemRequest true "Store item"
+// @Success 201 {object} calltypes.JSONResponse
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid store item"
+// @Failure 403 {object} calltypes.ErrorResponse "Insufficient role"
+// @Router /admin/store/items [post].
+func (s *RewardService) CreateStoreItem(w http.ResponseWriter, r *http.Request) {
+	var requestPayload calltypes.StoreItemRequest
+
+	err := httputils.ReadJSON(w, r, &requestPayload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	item, err := storeItemFromRequest(requestPayload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	id, err := s.Repo.CreateStoreItem(item)
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrSaveStoreItem, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: fmt.Sprintf("Created store item %s, id: %d", item.Title, id),
+	}
+
+	err = httputils.WriteJSON(w, http.StatusCreated, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// UpdateStoreItem godoc
+// @Summary Update store item
+// @Description Replaces the store item with provided id, pending redemptions keep their price. Available to admins.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Store item ID"
+// @Param request body calltypes.StoreItemRequest true "Store item"
+// @Success 200 {object} calltypes.JSONResponse
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid store item"
+// @Failure 403 {object} calltypes.ErrorResponse "Insufficient role"
+// @Failure 404 {object} calltypes.ErrorResponse "Store item not found"
+// @Router /admin/store/items/{id} [put].
+func (s *RewardService) UpdateStoreItem(w http.ResponseWriter, r *http.Request) {
+	id, err := GetIDFromURL(r, "id")
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidID, http.StatusBadRequest)
+
+		return
+	}
+
+	var requestPayload calltypes.StoreItemRequest
+
+	err = httputils.ReadJSON(w, r, &requestPayload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	item, err := storeItemFromRequest(requestPayload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	item.ID = id
+
+	err = s.Repo.UpdateStoreItem(item)
+	if errors.Is(err, errormsg.ErrStoreItemNotFound) {
+		httputils.ErrorJSON(w, err, http.StatusNotFound)
+
+		return
+	}
+
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrSaveStoreItem, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: "Updated store item " + item.Title,
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// RedeemItem godoc
+// @Summary Redeem store item
+// @Description Buys the store item with points. The price is deducted and the item is reserved at once,
+// @Description the redemption stays pending until an admin fulfills or cancels it. Digital items are
+// @Description fulfilled right away, the voucher code is read from /users/me/vouchers: the response may be
+// @Description stored for an Idempotency-Key replay and must not carry the plaintext code.
+// @Tags Store
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body calltypes.RedemptionRequest true "Store item"
+// @Success 201 {object} calltypes.JSONResponse{data=calltypes.Redemption}
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid user ID or store item"
+// @Failure 404 {object} calltypes.ErrorResponse "Store item not found"
+// @Failure 409 {object} calltypes.ErrorResponse "Item is not available, out of stock or its limit is reached"
+// @Failure 422 {object} calltypes.ErrorResponse "Not enough points"
+// @Router /users/{id}/redemptions [post].
+func (s *RewardService) RedeemItem(w http.ResponseWriter, r *http.Request) {
+	id, err := GetIDFromURL(r, "id")
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidID, http.StatusBadRequest)
+
+		return
+	}
+
+	var requestPayload calltypes.RedemptionRequest
+
+	err = httputils.ReadJSON(w, r, &requestPayload)
+	if err != nil || requestPayload.ItemID <= 0 {
+		httputils.ErrorJSON(w, errormsg.ErrStoreItemNotFound, http.StatusBadRequest)
+
+		return
+	}
+
+	actorID, _ := middleware.UserIDFromContext(r.Context())
+
+	redemption, err := s.Repo.RedeemItem(id, requestPayload.ItemID, actorID)
+
+	switch {
+	case errors.Is(err, errormsg.ErrStoreItemNotFound):
+		httputils.ErrorJSON(w, err, http.StatusNotFound)
+
+		return
+	case errors.Is(err, errormsg.ErrStoreItemNotAvailable) || errors.Is(err, errormsg.ErrOutOfStock) ||
+		errors.Is(err, errormsg.ErrRedemptionLimitReached):
+		httputils.ErrorJSON(w, err, http.StatusConflict)
+
+		return
+	case errors.Is(err, errormsg.ErrInsufficientPoints):
+		httputils.ErrorJSON(w, err, http.StatusUnprocessableEntity)
+
+		return
+	case err != nil:
+		log.Printf("failed to redeem store item %d for user %d: %v", requestPayload.ItemID, id, err)
+		httputils.ErrorJSON(w, errormsg.ErrRedeemItem, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: fmt.Sprintf("Redeemed %s for user with id %d, spent points %d", redemption.ItemTitle, id, redemption.Price),
+		Data:    redemption,
+	}
+
+	err = httputils.WriteJSON(w, http.StatusCreated, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// GetRedemptions godoc
+// @Summary Get user's redemptions
+// @Description Returns the user's purchases in the rewards store, newest first
+// @Tags Store
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} calltypes.JSONResponse{data=[]calltypes.Redemption}
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid user ID"
+// @Failure 500 {object} calltypes.ErrorResponse "Failed to fetch redemptions"
+// @Router /users/{id}/redemptions [get].
+func (s *RewardService) GetRedemptions(w http.ResponseWriter, r *http.Request) {
+	id, err := GetIDFromURL(r, "id")
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidID, http.StatusBadRequest)
+
+		return
+	}
+
+	s.writeRedemptions(w, id, "")
+}
+
+// ListRedemptions godoc
+// @Summary List redemptions
+// @Description Returns purchases of all users newest first, optionally filtered by status. Available to admins.
+// @Tags Admin
+// @Produce json
+// @Param status query string false "pending, fulfilled or cancelled"
+// @Success 200 {object} calltypes.JSONResponse{data=[]calltypes.Redemption}
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid status"
+// @Failure 403 {object} calltypes.ErrorResponse "Insufficient role"
+// @Failure 500 {object} calltypes.ErrorResponse "Failed to fetch redemptions"
+// @Router /admin/redemptions [get].
+func (s *RewardService) ListRedemptions(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status != "" && status != consts.RedemptionPending && !isFinalRedemptionStatus(status) {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidRedemptionStatus, http.StatusBadRequest)
+
+		return
+	}
+
+	s.writeRedemptions(w, 0, status)
+}
+
+// SetRedemptionStatus godoc
+// @Summary Fulfill or cancel redemption
+// @Description Moves the pending redemption to fulfilled or cancelled. Cancelling returns the item to the stock
+// @Description and refunds the points. Available to admins.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Redemption ID"
+// @Param request body calltypes.RedemptionStatusRequest true "New status"
+// @Success 200 {object} calltypes.JSONResponse{data=calltypes.Redemption}
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid redemption ID or status"
+// @Failure 403 {object} calltypes.ErrorResponse "Insufficient role"
+// @Failure 404 {object} calltypes.ErrorResponse "Redemption not found"
+// @Failure 409 {object} calltypes.ErrorResponse "Redemption is not pending"
+// @Router /admin/redemptions/{id}/status [put].
+func (s *RewardService) SetRedemptionStatus(w http.ResponseWriter, r *http.Request) {
+	id, err := GetIDFromURL(r, "id")
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidID, http.StatusBadRequest)
+
+		return
+	}
+
+	var requestPayload calltypes.RedemptionStatusRequest
+
+	err = httputils.ReadJSON(w, r, &requestPayload)
+	if err != nil || !isFinalRedemptionStatus(requestPayload.Status) {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidRedemptionStatus, http.StatusBadRequest)
+
+		return
+	}
+
+	actorID, _ := middleware.UserIDFromContext(r.Context())
+
+	redemption, err := s.Repo.SetRedemptionStatus(id, requestPayload.Status, actorID)
+
+	switch {
+	case errors.Is(err, errormsg.ErrRedemptionNotFound):
+		httputils.ErrorJSON(w, err, http.StatusNotFound)
+
+		return
+	case errors.Is(err, errormsg.ErrRedemptionNotPending):
+		httputils.ErrorJSON(w, err, http.StatusConflict)
+
+		return
+	case err != nil:
+		log.Printf("failed to set status of redemption %d: %v", id, err)
+		httputils.ErrorJSON(w, errormsg.ErrUpdateRedemption, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: fmt.Sprintf("Redemption %d is %s", id, redemption.Status),
+		Data:    redemption,
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+func (s *RewardService) writeStoreItems(w http.ResponseWriter, includeUnavailable bool) {
+	items, err := s.Repo.GetStoreItems(includeUnavailable)
+	if err != nil {
+		log.Printf("failed to fetch store items: %v", err)
+		httputils.ErrorJSON(w, errormsg.ErrFetchStoreItems, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: "Fetched store items",
+		Data:    items,
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+func (s *RewardService) writeRedemptions(w http.ResponseWriter, userID int, status string) {
+	redemptions, err := s.Repo.GetRedemptions(userID, status)
+	if err != nil {
+		log.Printf("failed to fetch redemptions: %v", err)
+		httputils.ErrorJSON(w, errormsg.ErrFetchRedemptions, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: "Fetched redemptions",
+		Data:    redemptions,
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// storeItemFromRequest validates the request.
+func storeItemFromRequest(request calltypes.StoreItemRequest) (calltypes.StoreItem, error) {
+	if request.Title == "" || request.Price <= 0 || request.PerUserLimit < 0 ||
+		(request.Stock != nil && (*request.Stock < 0 || request.Digital)) {
+		return calltypes.StoreItem{}, errormsg.ErrInvalidStoreItem
+	}
+
+	return calltypes.StoreItem{
+		Title:        request.Title,
+		Description:  request.Description,
+		Price:        request.Price,
+		Stock:        request.Stock,
+		PerUserLimit: request.PerUserLimit,
+		Digital:      request.Digital,
+		Active:       request.Active == nil || *request.Active,
+	}, nil
+}
+
+// isFinalRedemptionStatus reports whether a pending redemption can be moved to the status.
+func isFinalRedemptionStatus(status string) bool {
+	return status == consts.RedemptionFulfilled || status == consts.RedemptionCancelled
+}
@@ -0,0 +1,234 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reward-service/api/calltypes"
+	"reward-service/api/server/middleware"
+	"reward-service/internal/service"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRewardService_RedeemItem(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		requestBody  string
+		setupMock    func(*MockRepository)
+		expectedCode int
+	}{
+		{
+			name:        "item is redeemed",
+			requestBody: `{"itemId": 3}`,
+			setupMock: func(m *MockRepository) {
+				m.On("RedeemItem", 123, 3, 123).Return(&calltypes.Redemption{
+					ID: 1, UserID: 123, ItemID: 3, ItemTitle: "T-shirt", Price: 5000, Status: consts.RedemptionPending,
+				}, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:        "not enough points",
+			requestBody: `{"itemId": 3}`,
+			setupMock: func(m *MockRepository) {
+				m.On("RedeemItem", 123, 3, 123).Return((*calltypes.Redemption)(nil), errormsg.ErrInsufficientPoints)
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "out of stock",
+			requestBody: `{"itemId": 3}`,
+			setupMock: func(m *MockRepository) {
+				m.On("RedeemItem", 123, 3, 123).Return((*calltypes.Redemption)(nil), errormsg.ErrOutOfStock)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:        "limit is reached",
+			requestBody: `{"itemId": 3}`,
+			setupMock: func(m *MockRepository) {
+				m.On("RedeemItem", 123, 3, 123).Return((*calltypes.Redemption)(nil), errormsg.ErrRedemptionLimitReached)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:        "unknown item",
+			requestBody: `{"itemId": 3}`,
+			setupMock: func(m *MockRepository) {
+				m.On("RedeemItem", 123, 3, 123).Return((*calltypes.Redemption)(nil), errormsg.ErrStoreItemNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "missing item",
+			requestBody:  `{}`,
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(MockRepository)
+			tt.setupMock(mockRepo)
+
+			svc := service.NewRewardService(mockRepo)
+
+			req := httptest.NewRequest(http.MethodPost, "/users/123/redemptions", strings.NewReader(tt.requestBody))
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "123")
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			ctx = middleware.WithIdentity(ctx, middleware.Identity{UserID: 123})
+			req = req.WithContext(ctx)
+
+			rr := httptest.NewRecorder()
+
+			svc.RedeemItem(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRewardService_SetRedemptionStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		requestBody  string
+		setupMock    func(*MockRepository)
+		expectedCode int
+	}{
+		{
+			name:        "redemption is cancelled",
+			requestBody: `{"status": "cancelled"}`,
+			setupMock: func(m *MockRepository) {
+				m.On("SetRedemptionStatus", 7, consts.RedemptionCancelled, 1).
+					Return(&calltypes.Redemption{ID: 7, Status: consts.RedemptionCancelled}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:        "redemption is already fulfilled",
+			requestBody: `{"status": "cancelled"}`,
+			setupMock: func(m *MockRepository) {
+				m.On("SetRedemptionStatus", 7, consts.RedemptionCancelled, 1).
+					Return((*calltypes.Redemption)(nil), errormsg.ErrRedemptionNotPending)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:        "unknown redemption",
+			requestBody: `{"status": "fulfilled"}`,
+			setupMock: func(m *MockRepository) {
+				m.On("SetRedemptionStatus", 7, consts.RedemptionFulfilled, 1).
+					Return((*calltypes.Redemption)(nil), errormsg.ErrRedemptionNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "back to pending",
+			requestBody:  `{"status": "pending"}`,
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(MockRepository)
+			tt.setupMock(mockRepo)
+
+			svc := service.NewRewardService(mockRepo)
+
+			req := httptest.NewRequest(http.MethodPut, "/admin/redemptions/7/status", strings.NewReader(tt.requestBody))
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "7")
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			ctx = middleware.WithIdentity(ctx, middleware.Identity{UserID: 1, Role: consts.RoleAdmin})
+			req = req.WithContext(ctx)
+
+			rr := httptest.NewRecorder()
+
+			svc.SetRedemptionStatus(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRewardService_CreateStoreItem(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		requestBody  string
+		setupMock    func(*MockRepository)
+		expectedCode int
+	}{
+		{
+			name:        "stock is unlimited by default",
+			requestBody: `{"title": "T-shirt", "price": 5000}`,
+			setupMock: func(m *MockRepository) {
+				m.On("CreateStoreItem", mock.MatchedBy(func(item calltypes.StoreItem) bool {
+					return item.Stock == nil && item.Active && item.Price == 5000
+				})).Return(1, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "negative stock",
+			requestBody:  `{"title": "T-shirt", "price": 5000, "stock": -1}`,
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "digital item with stock",
+			requestBody:  `{"title": "Gift card", "price": 5000, "stock": 10, "digital": true}`,
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "free item",
+			requestBody:  `{"title": "T-shirt", "price": 0}`,
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(MockRepository)
+			tt.setupMock(mockRepo)
+
+			svc := service.NewRewardService(mockRepo)
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/store/items", strings.NewReader(tt.requestBody))
+			rr := httptest.NewRecorder()
+
+			svc.CreateStoreItem(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
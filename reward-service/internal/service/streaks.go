@@ -0,0 +1,185 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reward-service/api/calltypes"
+	"reward-service/api/server/httputils"
+	"reward-service/internal/streak"
+	"reward-service/pkg/errormsg"
+	"time"
+)
+
+// CheckIn godoc
+// @Summary Daily check-in
+// @Description Checks the user in for today in their timezone. The reward grows with every consecutive day:
+// @Description 10 points on the first day and 5 more every next day up to 40 points on the 7th day and later.
+// @Description A missed day resets the streak unless the user holds streak freezes to cover it.
+// @Tags Users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 201 {object} calltypes.JSONResponse{data=calltypes.CheckIn}
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid user ID"
+// @Failure 404 {object} calltypes.ErrorResponse "User not found"
+// @Failure 409 {object} calltypes.ErrorResponse "Already checked in today"
+// @Router /users/{id}/checkin [post].
+func (s *RewardService) CheckIn(w http.ResponseWriter, r *http.Request) {
+	id, err := GetIDFromURL(r, "id")
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidID, http.StatusBadRequest)
+
+		return
+	}
+
+	checkIn, err := s.Repo.CheckIn(id, time.Now())
+
+	switch {
+	case errors.Is(err, errormsg.ErrUserNotFound):
+		httputils.ErrorJSON(w, err, http.StatusNotFound)
+
+		return
+	case errors.Is(err, errormsg.ErrAlreadyCheckedIn):
+		httputils.ErrorJSON(w, err, http.StatusConflict)
+
+		return
+	case err != nil:
+		log.Printf("failed to check in user %d: %v", id, err)
+		httputils.ErrorJSON(w, errormsg.ErrCheckIn, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: fmt.Sprintf("User with id %d checked in, streak %d, earned points %d", id, checkIn.Streak, checkIn.Reward),
+		Data:    checkIn,
+	}
+
+	err = httputils.WriteJSON(w, http.StatusCreated, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// BuyStreakFreeze godoc
+// @Summary Buy streak freeze
+// @Description Buys a streak freeze for 100 points. A freeze covers one missed day of the check-in streak,
+// @Description the user holds at most 2 of them.
+// @Tags Users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 201 {object} calltypes.JSONResponse{data=calltypes.Streak}
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid user ID"
+// @Failure 404 {object} calltypes.ErrorResponse "User not found"
+// @Failure 409 {object} calltypes.ErrorResponse "Freeze limit reached"
+// @Failure 422 {object} calltypes.ErrorResponse "Not enough points"
+// @Router /users/{id}/streak/freezes [post].
+func (s *RewardService) BuyStreakFreeze(w http.ResponseWriter, r *http.Request) {
+	id, err := GetIDFromURL(r, "id")
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidID, http.StatusBadRequest)
+
+		return
+	}
+
+	streak, err := s.Repo.BuyStreakFreeze(id)
+
+	switch {
+	case errors.Is(err, errormsg.ErrUserNotFound):
+		httputils.ErrorJSON(w, err, http.StatusNotFound)
+
+		return
+	case errors.Is(err, errormsg.ErrStreakFreezeLimit):
+		httputils.ErrorJSON(w, err, http.StatusConflict)
+
+		return
+	case errors.Is(err, errormsg.ErrInsufficientPoints):
+		httputils.ErrorJSON(w, err, http.StatusUnprocessableEntity)
+
+		return
+	case err != nil:
+		log.Printf("failed to sell streak freeze to user %d: %v", id, err)
+		httputils.ErrorJSON(w, errormsg.ErrBuyStreakFreeze, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: fmt.Sprintf("User with id %d bought streak freeze, freezes %d", id, streak.Freezes),
+		Data:    streak,
+	}
+
+	err = httputils.WriteJSON(w, http.StatusCreated, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// SetTimezone godoc
+// @Summary Set user's timezone
+// @Description Sets the IANA timezone the user's check-in days are counted in, UTC by default
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body calltypes.TimezoneRequest true "Timezone"
+// @Success 200 {object} calltypes.JSONResponse
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid user ID or timezone"
+// @Failure 404 {object} calltypes.ErrorResponse "User not found"
+// @Router /users/{id}/timezone [put].
+func (s *RewardService) SetTimezone(w http.ResponseWriter, r *http.Request) {
+	id, err := GetIDFromURL(r, "id")
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidID, http.StatusBadRequest)
+
+		return
+	}
+
+	var requestPayload calltypes.TimezoneRequest
+
+	err = httputils.ReadJSON(w, r, &requestPayload)
+	if err != nil || !streak.ValidTimezone(requestPayload.Timezone) {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidTimezone, http.StatusBadRequest)
+
+		return
+	}
+
+	err = s.Repo.SetTimezone(id, requestPayload.Timezone)
+	if errors.Is(err, errormsg.ErrInvalidTimezone) {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	if errors.Is(err, errormsg.ErrUserNotFound) {
+		httputils.ErrorJSON(w, err, http.StatusNotFound)
+
+		return
+	}
+
+	if err != nil {
+		log.Printf("failed to set timezone of user %d: %v", id, err)
+		httputils.ErrorJSON(w, errormsg.ErrSetTimezone, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: fmt.Sprintf("Set timezone of user with id %d to %s", id, requestPayload.Timezone),
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
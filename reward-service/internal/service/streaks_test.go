@@ -0,0 +1,181 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reward-service/api/calltypes"
+	"reward-service/internal/service"
+	"reward-service/pkg/errormsg"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRewardService_CheckIn(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		setupMock    func(*MockRepository)
+		expectedCode int
+	}{
+		{
+			name: "streak continues",
+			setupMock: func(m *MockRepository) {
+				m.On("CheckIn", 123, mock.AnythingOfType("time.Time")).
+					Return(&calltypes.CheckIn{Date: "2025-09-02", Streak: 2, Reward: 15}, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name: "already checked in today",
+			setupMock: func(m *MockRepository) {
+				m.On("CheckIn", 123, mock.AnythingOfType("time.Time")).Return((*calltypes.CheckIn)(nil), errormsg.ErrAlreadyCheckedIn)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name: "user not found",
+			setupMock: func(m *MockRepository) {
+				m.On("CheckIn", 123, mock.AnythingOfType("time.Time")).
+					Return((*calltypes.CheckIn)(nil), errormsg.ErrUserNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(MockRepository)
+			tt.setupMock(mockRepo)
+
+			svc := service.NewRewardService(mockRepo)
+
+			req := httptest.NewRequest(http.MethodPost, "/users/123/checkin", nil)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "123")
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+
+			svc.CheckIn(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRewardService_BuyStreakFreeze(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		repoResponse *calltypes.Streak
+		repoError    error
+		expectedCode int
+	}{
+		{
+			name:         "freeze is bought",
+			repoResponse: &calltypes.Streak{Current: 4, Longest: 4, Freezes: 1, Timezone: "UTC"},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "freeze limit reached",
+			repoError:    errormsg.ErrStreakFreezeLimit,
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "not enough points",
+			repoError:    errormsg.ErrInsufficientPoints,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(MockRepository)
+			mockRepo.On("BuyStreakFreeze", 123).Return(tt.repoResponse, tt.repoError)
+
+			svc := service.NewRewardService(mockRepo)
+
+			req := httptest.NewRequest(http.MethodPost, "/users/123/streak/freezes", nil)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "123")
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+
+			svc.BuyStreakFreeze(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRewardService_SetTimezone(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		requestBody  string
+		setupMock    func(*MockRepository)
+		expectedCode int
+	}{
+		{
+			name:        "timezone is set",
+			requestBody: `{"timezone": "Europe/Moscow"}`,
+			setupMock: func(m *MockRepository) {
+				m.On("SetTimezone", 123, "Europe/Moscow").Return(nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "unknown timezone",
+			requestBody:  `{"timezone": "Mars/Olympus"}`,
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "server's local timezone",
+			requestBody:  `{"timezone": "Local"}`,
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(MockRepository)
+			tt.setupMock(mockRepo)
+
+			svc := service.NewRewardService(mockRepo)
+
+			req := httptest.NewRequest(http.MethodPut, "/users/123/timezone", strings.NewReader(tt.requestBody))
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "123")
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+
+			svc.SetTimezone(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
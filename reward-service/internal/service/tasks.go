@@ -0,0 +1,426 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reward-service/api/calltypes"
+	"reward-service/api/server/httputils"
+	"reward-service/api/server/middleware"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ListTasks godoc
+// @Summary List available tasks
+// @Description Returns active tasks of the catalog that can be completed right now
+// @Tags Tasks
+// @Produce json
+// @Success 200 {object} calltypes.JSONResponse{data=[]calltypes.Task}
+// @Failure 401 {object} calltypes.ErrorResponse "Unauthorized"
+// @Failure 500 {object} calltypes.ErrorResponse "Failed to fetch tasks"
+// @Router /tasks [get].
+func (s *RewardService) ListTasks(w http.ResponseWriter, _ *http.Request) {
+	s.writeTasks(w, false)
+}
+
+// ListAllTasks godoc
+// @Summary List all tasks
+// @Description Returns the whole task catalog including inactive and expired tasks. Available to admins.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} calltypes.JSONResponse{data=[]calltypes.Task}
+// @Failure 403 {object} calltypes.ErrorResponse "Insufficient role"
+// @Failure 500 {object} calltypes.ErrorResponse "Failed to fetch tasks"
+// @Router /admin/tasks [get].
+func (s *RewardService) ListAllTasks(w http.ResponseWriter, _ *http.Request) {
+	s.writeTasks(w, true)
+}
+
+// CompleteTaskBySlug godoc
+// @Summary Complete catalog task
+// @Description Awards the reward of the catalog task to the user. Tasks with a verifier are checked with
+// @Description their provider first, the user's account with the provider is required for them. The account
+// @Description is bound to the user by the first verified completion and can't be used by other users.
+// @Tags Tasks
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param slug path string true "Task slug"
+// @Param request body calltypes.TaskCompletionRequest false "Account with the task provider"
+// @Success 200 {object} calltypes.JSONResponse
+// @Success 202 {object} calltypes.JSONResponse "Verification is pending"
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid user ID, missing account or failed to add points"
+// @Failure 404 {object} calltypes.ErrorResponse "Task not found"
+// @Failure 409 {object} calltypes.ErrorResponse "Task is not available, its policy forbids another completion or account is taken"
+// @Failure 422 {object} calltypes.ErrorResponse "Provider couldn't confirm the completion"
+// @Failure 502 {object} calltypes.ErrorResponse "Provider rejected the verifier's request"
+// @Failure 503 {object} calltypes.ErrorResponse "Verifier of the task is not configured"
+// @Router /users/{id}/tasks/{slug}/complete [post].
+func (s *RewardService) CompleteTaskBySlug(w http.ResponseWriter, r *http.Request) {
+	s.completeCatalogTask(w, r, chi.URLParam(r, "slug"))
+}
+
+// CreateTask godoc
+// @Summary Create task
+// @Description Adds new task to the catalog. Available to admins.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body calltypes.TaskRequest true "Task"
+// @Success 201 {object} calltypes.JSONResponse
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid task"
+// @Failure 403 {object} calltypes.ErrorResponse "Insufficient role"
+// @Failure 409 {object} calltypes.ErrorResponse "Slug is already taken"
+// @Router /admin/tasks [post].
+func (s *RewardService) CreateTask(w http.ResponseWriter, r *http.Request) {
+	var requestPayload calltypes.TaskRequest
+
+	err := httputils.ReadJSON(w, r, &requestPayload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	task, err := taskFromRequest(requestPayload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	id, err := s.Repo.CreateTask(task)
+	if errors.Is(err, errormsg.ErrTaskSlugTaken) {
+		httputils.ErrorJSON(w, err, http.StatusConflict)
+
+		return
+	}
+
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrSaveTask, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: fmt.Sprintf("Created task %s, id: %d", task.Slug, id),
+	}
+
+	err = httputils.WriteJSON(w, http.StatusCreated, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// UpdateTask godoc
+// @Summary Update task
+// @Description Replaces the task with provided slug. Available to admins.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param slug path string true "Task slug"
+// @Param request body calltypes.TaskRequest true "Task, the slug in the body is ignored"
+// @Success 200 {object} calltypes.JSONResponse
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid task"
+// @Failure 403 {object} calltypes.ErrorResponse "Insufficient role"
+// @Failure 404 {object} calltypes.ErrorResponse "Task not found"
+// @Router /admin/tasks/{slug} [put].
+func (s *RewardService) UpdateTask(w http.ResponseWriter, r *http.Request) {
+	var requestPayload calltypes.TaskRequest
+
+	err := httputils.ReadJSON(w, r, &requestPayload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	requestPayload.Slug = chi.URLParam(r, "slug")
+
+	task, err := taskFromRequest(requestPayload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	err = s.Repo.UpdateTask(task)
+	if errors.Is(err, errormsg.ErrTaskNotFound) {
+		httputils.ErrorJSON(w, err, http.StatusNotFound)
+
+		return
+	}
+
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrSaveTask, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: "Updated task " + task.Slug,
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// completeCatalogTask awards the user from the URL with the reward of the task with provided slug.
+func (s *RewardService) completeCatalogTask(w http.ResponseWriter, r *http.Request, slug string) {
+	id, err := GetIDFromURL(r, "id")
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidID, http.StatusBadRequest)
+
+		return
+	}
+
+	task, err := s.Repo.GetTask(slug)
+	if errors.Is(err, errormsg.ErrTaskNotFound) {
+		httputils.ErrorJSON(w, err, http.StatusNotFound)
+
+		return
+	}
+
+	if err != nil {
+		log.Printf("failed to fetch task %s: %v", slug, err)
+		httputils.ErrorJSON(w, errormsg.ErrFetchTasks, http.StatusInternalServerError)
+
+		return
+	}
+
+	if !taskAvailable(task, time.Now()) {
+		httputils.ErrorJSON(w, errormsg.ErrTaskNotAvailable, http.StatusConflict)
+
+		return
+	}
+
+	completion := calltypes.TaskCompletion{UserID: id, TaskID: task.ID, Status: consts.VerificationVerified}
+
+	if task.Verifier != "" {
+		var status int
+
+		completion, status, err = s.verifyCompletion(w, r, task, id)
+		if err != nil {
+			httputils.ErrorJSON(w, err, status)
+
+			return
+		}
+	}
+
+	actorID, _ := middleware.UserIDFromContext(r.Context())
+
+	err = s.Repo.CompleteTask(task, completion, calltypes.PointTransaction{
+		UserID:      id,
+		Delta:       task.Reward,
+		Reason:      consts.ReasonTaskCompletion,
+		ReferenceID: task.Slug,
+		ActorID:     actorID,
+	})
+	if errors.Is(err, errormsg.ErrTaskAlreadyCompleted) || errors.Is(err, errormsg.ErrTaskLimitReached) ||
+		errors.Is(err, errormsg.ErrTaskOnCooldown) || errors.Is(err, errormsg.ErrTaskAccountTaken) {
+		httputils.ErrorJSON(w, err, http.StatusConflict)
+
+		return
+	}
+
+	if err != nil {
+		log.Printf("failed to complete task %s for user %d: %v", slug, id, err)
+		httputils.ErrorJSON(w, errormsg.ErrAddPoints, http.StatusBadRequest)
+
+		return
+	}
+
+	switch completion.Status {
+	case consts.VerificationFailed:
+		httputils.ErrorJSON(w, errormsg.ErrTaskVerificationFailed, http.StatusUnprocessableEntity)
+
+		return
+	case consts.VerificationPending:
+		payload := calltypes.JSONResponse{
+			Error:   false,
+			Message: errormsg.ErrTaskVerificationPending.Error(),
+		}
+
+		err = httputils.WriteJSON(w, http.StatusAccepted, payload)
+		if err != nil {
+			httputils.ErrorJSON(w, err, http.StatusBadRequest)
+		}
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: fmt.Sprintf("complete task worked for user with id %d, added points %d", id, task.Reward),
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// verifyCompletion checks the completion with the provider of the task. The account with the provider
+// is read from the request body. A status code to respond with is returned along with an error. Completions
+// the provider couldn't be asked about are pending, the ones it refused to check because of the verifier's
+// configuration are not recorded.
+func (s *RewardService) verifyCompletion(w http.ResponseWriter, r *http.Request, task *calltypes.Task, userID int,
+) (calltypes.TaskCompletion, int, error) {
+	var requestPayload calltypes.TaskCompletionRequest
+
+	err := httputils.ReadJSON(w, r, &requestPayload)
+	if err != nil || requestPayload.Account == "" {
+		return calltypes.TaskCompletion{}, http.StatusBadRequest, errormsg.ErrMissingTaskAccount
+	}
+
+	verifier, ok := s.Verifiers[task.Verifier]
+	if !ok {
+		log.Printf("no verifier %q is configured for task %s", task.Verifier, task.Slug)
+
+		return calltypes.TaskCompletion{}, http.StatusServiceUnavailable, errormsg.ErrTaskVerifierUnavailable
+	}
+
+	// X may take several pages to check, the whole check must end before the server's write timeout.
+	ctx, cancel := context.WithTimeout(r.Context(), consts.VerificationTimeout)
+	defer cancel()
+
+	verification, err := verifier.Verify(ctx, task, requestPayload.Account)
+	if err != nil {
+		log.Printf("failed to verify task %s for user %d: %v", task.Slug, userID, err)
+
+		if errors.Is(err, errormsg.ErrVerifierResponse) {
+			return calltypes.TaskCompletion{}, http.StatusBadGateway, errormsg.ErrVerifierResponse
+		}
+
+		verification = pendingVerification(err.Error())
+	}
+
+	return calltypes.TaskCompletion{
+		UserID:  userID,
+		TaskID:  task.ID,
+		Status:  verification.Status,
+		Account: requestPayload.Account,
+		Detail:  verification.Detail,
+	}, http.StatusOK, nil
+}
+
+func (s *RewardService) writeTasks(w http.ResponseWriter, includeUnavailable bool) {
+	tasks, err := s.Repo.GetTasks(includeUnavailable)
+	if err != nil {
+		log.Printf("failed to fetch tasks: %v", err)
+		httputils.ErrorJSON(w, errormsg.ErrFetchTasks, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: "Fetched tasks",
+		Data:    tasks,
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// taskAvailable reports whether the task is active and now is inside its time window.
+func taskAvailable(task *calltypes.Task, now time.Time) bool {
+	if !task.Active {
+		return false
+	}
+
+	if task.StartsAt != nil && now.Before(*task.StartsAt) {
+		return false
+	}
+
+	return task.EndsAt == nil || now.Before(*task.EndsAt)
+}
+
+// taskFromRequest validates the request and turns it into a task.
+func taskFromRequest(request calltypes.TaskRequest) (calltypes.Task, error) {
+	if !isValidTaskSlug(request.Slug) {
+		return calltypes.Task{}, errormsg.ErrInvalidTaskSlug
+	}
+
+	if request.Title == "" || request.Reward <= 0 {
+		return calltypes.Task{}, errormsg.ErrInvalidTask
+	}
+
+	if request.StartsAt != nil && request.EndsAt != nil && !request.StartsAt.Before(*request.EndsAt) {
+		return calltypes.Task{}, errormsg.ErrInvalidTask
+	}
+
+	if request.Verifier != "" && request.Verifier != consts.VerifierTelegram && request.Verifier != consts.VerifierX {
+		return calltypes.Task{}, errormsg.ErrInvalidTaskVerifier
+	}
+
+	task := calltypes.Task{
+		Slug:        request.Slug,
+		Title:       request.Title,
+		Description: request.Description,
+		Reward:      request.Reward,
+		Policy:      request.Policy,
+		Verifier:    request.Verifier,
+		Active:      request.Active == nil || *request.Active,
+		StartsAt:    request.StartsAt,
+		EndsAt:      request.EndsAt,
+	}
+
+	// Only the parameters of the chosen policy are kept.
+	switch request.Policy {
+	case "":
+		task.Policy = consts.TaskPolicyOnce
+	case consts.TaskPolicyOnce, consts.TaskPolicyDaily:
+	case consts.TaskPolicyLimited:
+		if request.MaxCompletions <= 0 || request.PeriodSeconds <= 0 {
+			return calltypes.Task{}, errormsg.ErrInvalidTaskPolicy
+		}
+
+		task.MaxCompletions = request.MaxCompletions
+		task.PeriodSeconds = request.PeriodSeconds
+	case consts.TaskPolicyUnlimited:
+		if request.CooldownSeconds < 0 {
+			return calltypes.Task{}, errormsg.ErrInvalidTaskPolicy
+		}
+
+		task.CooldownSeconds = request.CooldownSeconds
+	default:
+		return calltypes.Task{}, errormsg.ErrInvalidTaskPolicy
+	}
+
+	return task, nil
+}
+
+func isValidTaskSlug(slug string) bool {
+	if slug == "" || len(slug) > consts.TaskSlugMaxLength {
+		return false
+	}
+
+	for _, c := range slug {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,376 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reward-service/api/calltypes"
+	"reward-service/internal/service"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewardService_CompleteTaskBySlug(t *testing.T) {
+	t.Parallel()
+
+	past := time.Now().Add(-time.Hour)
+	task := calltypes.Task{Slug: "daily-quiz", Reward: 40, Policy: consts.TaskPolicyDaily, Active: true}
+
+	tests := []struct {
+		name         string
+		urlID        string
+		setupMock    func(*MockRepository)
+		expectedCode int
+	}{
+		{
+			name:  "reward comes from the catalog",
+			urlID: "123",
+			setupMock: func(m *MockRepository) {
+				m.On("GetTask", "daily-quiz").Return(&task, nil)
+				m.On("CompleteTask", &task, mock.MatchedBy(func(completion calltypes.TaskCompletion) bool {
+					return completion.UserID == 123 && completion.Status == consts.VerificationVerified
+				}), mock.MatchedBy(func(entry calltypes.PointTransaction) bool {
+					return entry.UserID == 123 && entry.Delta == 40 &&
+						entry.Reason == consts.ReasonTaskCompletion && entry.ReferenceID == "daily-quiz"
+				})).Return(nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:  "one-time task is already completed",
+			urlID: "123",
+			setupMock: func(m *MockRepository) {
+				m.On("GetTask", "daily-quiz").Return(&task, nil)
+				m.On("CompleteTask", &task, mock.AnythingOfType("calltypes.TaskCompletion"),
+					mock.AnythingOfType("calltypes.PointTransaction")).
+					Return(errormsg.ErrTaskAlreadyCompleted)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:  "task is on cooldown",
+			urlID: "123",
+			setupMock: func(m *MockRepository) {
+				m.On("GetTask", "daily-quiz").Return(&task, nil)
+				m.On("CompleteTask", &task, mock.AnythingOfType("calltypes.TaskCompletion"),
+					mock.AnythingOfType("calltypes.PointTransaction")).
+					Return(errormsg.ErrTaskOnCooldown)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:  "provider account belongs to another user",
+			urlID: "123",
+			setupMock: func(m *MockRepository) {
+				m.On("GetTask", "daily-quiz").Return(&task, nil)
+				m.On("CompleteTask", &task, mock.AnythingOfType("calltypes.TaskCompletion"),
+					mock.AnythingOfType("calltypes.PointTransaction")).
+					Return(errormsg.ErrTaskAccountTaken)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:  "repository error",
+			urlID: "123",
+			setupMock: func(m *MockRepository) {
+				m.On("GetTask", "daily-quiz").Return(&task, nil)
+				m.On("CompleteTask", &task, mock.AnythingOfType("calltypes.TaskCompletion"),
+					mock.AnythingOfType("calltypes.PointTransaction")).
+					Return(errormsg.ErrRepositoryError)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid ID",
+			urlID:        "abc",
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:  "unknown task",
+			urlID: "123",
+			setupMock: func(m *MockRepository) {
+				m.On("GetTask", "daily-quiz").Return((*calltypes.Task)(nil), errormsg.ErrTaskNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:  "inactive task",
+			urlID: "123",
+			setupMock: func(m *MockRepository) {
+				m.On("GetTask", "daily-quiz").Return(&calltypes.Task{Slug: "daily-quiz", Reward: 40}, nil)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:  "task window is over",
+			urlID: "123",
+			setupMock: func(m *MockRepository) {
+				m.On("GetTask", "daily-quiz").
+					Return(&calltypes.Task{Slug: "daily-quiz", Reward: 40, Active: true, EndsAt: &past}, nil)
+			},
+			expectedCode: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(MockRepository)
+			tt.setupMock(mockRepo)
+
+			svc := service.NewRewardService(mockRepo)
+
+			req := httptest.NewRequest(http.MethodPost, "/users/"+tt.urlID+"/tasks/daily-quiz/complete", nil)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.urlID)
+			rctx.URLParams.Add("slug", "daily-quiz")
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+
+			svc.CompleteTaskBySlug(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+// rejectedVerifier is a verifier whose provider refuses to answer, as with a revoked token.
+type rejectedVerifier struct{}
+
+func (rejectedVerifier) Verify(_ context.Context, _ *calltypes.Task, _ string) (calltypes.Verification, error) {
+	return calltypes.Verification{}, fmt.Errorf("%w: status 401", errormsg.ErrVerifierResponse)
+}
+
+func TestRewardService_CompleteTaskBySlug_Verification(t *testing.T) {
+	t.Parallel()
+
+	task := calltypes.Task{
+		ID: 2, Slug: consts.TaskSlugTelegramSign, Reward: 50, Policy: consts.TaskPolicyOnce,
+		Verifier: consts.VerifierTelegram, Active: true,
+	}
+
+	tests := []struct {
+		name         string
+		requestBody  string
+		verifier     service.TaskVerifier
+		setupMock    func(*MockRepository)
+		expectedCode int
+	}{
+		{
+			name:        "verified completion is rewarded",
+			requestBody: `{"account": "42"}`,
+			verifier:    service.NewFakeVerifier(),
+			setupMock: func(m *MockRepository) {
+				m.On("CompleteTask", &task, mock.MatchedBy(func(completion calltypes.TaskCompletion) bool {
+					return completion.Status == consts.VerificationVerified && completion.Account == "42" &&
+						completion.TaskID == 2
+				}), mock.AnythingOfType("calltypes.PointTransaction")).Return(nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:        "pending completion is recorded",
+			requestBody: `{"account": "42"}`,
+			verifier:    &service.FakeVerifier{Status: consts.VerificationPending},
+			setupMock: func(m *MockRepository) {
+				m.On("CompleteTask", &task, mock.MatchedBy(func(completion calltypes.TaskCompletion) bool {
+					return completion.Status == consts.VerificationPending
+				}), mock.AnythingOfType("calltypes.PointTransaction")).Return(nil)
+			},
+			expectedCode: http.StatusAccepted,
+		},
+		{
+			name:        "failed completion is recorded",
+			requestBody: `{"account": "42"}`,
+			verifier:    &service.FakeVerifier{Status: consts.VerificationFailed},
+			setupMock: func(m *MockRepository) {
+				m.On("CompleteTask", &task, mock.MatchedBy(func(completion calltypes.TaskCompletion) bool {
+					return completion.Status == consts.VerificationFailed
+				}), mock.AnythingOfType("calltypes.PointTransaction")).Return(nil)
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "provider rejected the verifier",
+			requestBody:  `{"account": "42"}`,
+			verifier:     rejectedVerifier{},
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadGateway,
+		},
+		{
+			name:         "missing account",
+			requestBody:  `{}`,
+			verifier:     service.NewFakeVerifier(),
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "verifier is not configured",
+			requestBody:  `{"account": "42"}`,
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(MockRepository)
+			mockRepo.On("GetTask", consts.TaskSlugTelegramSign).Return(&task, nil)
+			tt.setupMock(mockRepo)
+
+			svc := service.NewRewardService(mockRepo)
+			if tt.verifier != nil {
+				svc.Verifiers[consts.VerifierTelegram] = tt.verifier
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/users/123/tasks/telegram-sign/complete",
+				strings.NewReader(tt.requestBody))
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "123")
+			rctx.URLParams.Add("slug", consts.TaskSlugTelegramSign)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+
+			svc.CompleteTaskBySlug(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRewardService_ListTasks(t *testing.T) {
+	t.Parallel()
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetTasks", false).Return([]*calltypes.Task{{Slug: consts.TaskSlugTelegramSign, Reward: 50}}, nil)
+
+	svc := service.NewRewardService(mockRepo)
+
+	rr := httptest.NewRecorder()
+
+	svc.ListTasks(rr, httptest.NewRequest(http.MethodGet, "/tasks", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response struct {
+		Data []calltypes.Task `json:"data"`
+	}
+
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, consts.TaskSlugTelegramSign, response.Data[0].Slug)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRewardService_CreateTask(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		requestBody  string
+		setupMock    func(*MockRepository)
+		expectedCode int
+	}{
+		{
+			name:        "task is active and one-time by default",
+			requestBody: `{"slug": "daily-quiz", "title": "Daily quiz", "reward": 40}`,
+			setupMock: func(m *MockRepository) {
+				m.On("CreateTask", mock.MatchedBy(func(task calltypes.Task) bool {
+					return task.Slug == "daily-quiz" && task.Reward == 40 && task.Active &&
+						task.Policy == consts.TaskPolicyOnce
+				})).Return(1, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name: "limited policy keeps its parameters only",
+			requestBody: `{"slug": "daily-quiz", "title": "Daily quiz", "reward": 40,
+				"policy": "limited", "maxCompletions": 3, "periodSeconds": 3600, "cooldownSeconds": 60}`,
+			setupMock: func(m *MockRepository) {
+				m.On("CreateTask", mock.MatchedBy(func(task calltypes.Task) bool {
+					return task.MaxCompletions == 3 && task.PeriodSeconds == 3600 && task.CooldownSeconds == 0
+				})).Return(1, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "limited policy without period",
+			requestBody:  `{"slug": "daily-quiz", "title": "Daily quiz", "reward": 40, "policy": "limited", "maxCompletions": 3}`,
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "unknown policy",
+			requestBody:  `{"slug": "daily-quiz", "title": "Daily quiz", "reward": 40, "policy": "weekly"}`,
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid slug",
+			requestBody:  `{"slug": "Daily Quiz", "title": "Daily quiz", "reward": 40}`,
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "non-positive reward",
+			requestBody:  `{"slug": "daily-quiz", "title": "Daily quiz", "reward": 0}`,
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "window ends before it starts",
+			requestBody: `{"slug": "daily-quiz", "title": "Daily quiz", "reward": 40,
+				"startsAt": "2025-07-02T00:00:00Z", "endsAt": "2025-07-01T00:00:00Z"}`,
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:        "slug is taken",
+			requestBody: `{"slug": "daily-quiz", "title": "Daily quiz", "reward": 40}`,
+			setupMock: func(m *MockRepository) {
+				m.On("CreateTask", mock.AnythingOfType("calltypes.Task")).Return(0, errormsg.ErrTaskSlugTaken)
+			},
+			expectedCode: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(MockRepository)
+			tt.setupMock(mockRepo)
+
+			svc := service.NewRewardService(mockRepo)
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/tasks", strings.NewReader(tt.requestBody))
+			rr := httptest.NewRecorder()
+
+			svc.CreateTask(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reward-service/api/calltypes"
+	"reward-service/api/server/httputils"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+)
+
+// ListTiers godoc
+// @Summary List tiers
+// @Description Returns the tiers from the lowest to the highest with the lifetime points they require
+// @Description and their reward multipliers
+// @Tags Users
+// @Produce json
+// @Success 200 {object} calltypes.JSONResponse{data=[]calltypes.Tier}
+// @Failure 401 {object} calltypes.ErrorResponse "Unauthorized"
+// @Failure 500 {object} calltypes.ErrorResponse "Failed to fetch tiers"
+// @Router /tiers [get].
+func (s *RewardService) ListTiers(w http.ResponseWriter, _ *http.Request) {
+	tiers, err := s.Repo.GetTiers()
+	if err != nil {
+		log.Printf("failed to fetch tiers: %v", err)
+		httputils.ErrorJSON(w, errormsg.ErrFetchTiers, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: "Fetched tiers",
+		Data:    tiers,
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// CreateTier godoc
+// @Summary Create tier
+// @Description Adds new tier. Available to admins.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body calltypes.TierRequest true "Tier"
+// @Success 201 {object} calltypes.JSONResponse
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid tier"
+// @Failure 403 {object} calltypes.ErrorResponse "Insufficient role"
+// @Failure 409 {object} calltypes.ErrorResponse "Tier with the name or minPoints exists"
+// @Router /admin/tiers [post].
+func (s *RewardService) CreateTier(w http.ResponseWriter, r *http.Request) {
+	var requestPayload calltypes.TierRequest
+
+	err := httputils.ReadJSON(w, r, &requestPayload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	tier, err := tierFromRequest(requestPayload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	id, err := s.Repo.CreateTier(tier)
+	if errors.Is(err, errormsg.ErrTierExists) {
+		httputils.ErrorJSON(w, err, http.StatusConflict)
+
+		return
+	}
+
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrSaveTier, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: fmt.Sprintf("Created tier %s, id: %d", tier.Name, id),
+	}
+
+	err = httputils.WriteJSON(w, http.StatusCreated, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// UpdateTier godoc
+// @Summary Update tier
+// @Description Replaces the tier with provided id, users are placed by the new thresholds at once. Available to admins.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Tier ID"
+// @Param request body calltypes.TierRequest true "Tier"
+// @Success 200 {object} calltypes.JSONResponse
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid tier"
+// @Failure 403 {object} calltypes.ErrorResponse "Insufficient role"
+// @Failure 404 {object} calltypes.ErrorResponse "Tier not found"
+// @Failure 409 {object} calltypes.ErrorResponse "Tier with the name or minPoints exists"
+// @Router /admin/tiers/{id} [put].
+func (s *RewardService) UpdateTier(w http.ResponseWriter, r *http.Request) {
+	id, err := GetIDFromURL(r, "id")
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidID, http.StatusBadRequest)
+
+		return
+	}
+
+	var requestPayload calltypes.TierRequest
+
+	err = httputils.ReadJSON(w, r, &requestPayload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	tier, err := tierFromRequest(requestPayload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	tier.ID = id
+
+	err = s.Repo.UpdateTier(tier)
+
+	switch {
+	case errors.Is(err, errormsg.ErrTierNotFound):
+		httputils.ErrorJSON(w, err, http.StatusNotFound)
+
+		return
+	case errors.Is(err, errormsg.ErrTierExists):
+		httputils.ErrorJSON(w, err, http.StatusConflict)
+
+		return
+	case err != nil:
+		httputils.ErrorJSON(w, errormsg.ErrSaveTier, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: "Updated tier " + tier.Name,
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// DeleteTier godoc
+// @Summary Delete tier
+// @Description Deletes the tier, its users fall back to the highest tier below it. Available to admins.
+// @Tags Admin
+// @Produce json
+// @Param id path int true "Tier ID"
+// @Success 200 {object} calltypes.JSONResponse
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid tier ID"
+// @Failure 403 {object} calltypes.ErrorResponse "Insufficient role"
+// @Failure 404 {object} calltypes.ErrorResponse "Tier not found"
+// @Router /admin/tiers/{id} [delete].
+func (s *RewardService) DeleteTier(w http.ResponseWriter, r *http.Request) {
+	id, err := GetIDFromURL(r, "id")
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidID, http.StatusBadRequest)
+
+		return
+	}
+
+	err = s.Repo.DeleteTier(id)
+	if errors.Is(err, errormsg.ErrTierNotFound) {
+		httputils.ErrorJSON(w, err, http.StatusNotFound)
+
+		return
+	}
+
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrSaveTier, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: fmt.Sprintf("Deleted tier %d", id),
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// tierFromRequest validates the request, a tier without multiplier gives no bonus.
+func tierFromRequest(request calltypes.TierRequest) (calltypes.Tier, error) {
+	tier := calltypes.Tier{
+		Name:              request.Name,
+		MinPoints:         request.MinPoints,
+		MultiplierPercent: request.MultiplierPercent,
+	}
+
+	if tier.MultiplierPercent == 0 {
+		tier.MultiplierPercent = 100 //nolint: mnd
+	}
+
+	if tier.Name == "" || tier.MinPoints < 0 || tier.MultiplierPercent < 100 || //nolint: mnd
+		tier.MultiplierPercent > consts.MaxMultiplierPercent {
+		return calltypes.Tier{}, errormsg.ErrInvalidTier
+	}
+
+	return tier, nil
+}
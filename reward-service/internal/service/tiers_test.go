@@ -0,0 +1,75 @@
+package service_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reward-service/api/calltypes"
+	"reward-service/internal/service"
+	"reward-service/pkg/errormsg"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRewardService_CreateTier(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		requestBody  string
+		setupMock    func(*MockRepository)
+		expectedCode int
+	}{
+		{
+			name:        "tier without multiplier gives no bonus",
+			requestBody: `{"name": "Bronze", "minPoints": 0}`,
+			setupMock: func(m *MockRepository) {
+				m.On("CreateTier", mock.MatchedBy(func(tier calltypes.Tier) bool {
+					return tier.Name == "Bronze" && tier.MultiplierPercent == 100
+				})).Return(1, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:        "threshold is taken",
+			requestBody: `{"name": "Gold", "minPoints": 5000, "multiplierPercent": 125}`,
+			setupMock: func(m *MockRepository) {
+				m.On("CreateTier", mock.Anything).Return(0, errormsg.ErrTierExists)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "multiplier lowers rewards",
+			requestBody:  `{"name": "Gold", "minPoints": 5000, "multiplierPercent": 50}`,
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "negative threshold",
+			requestBody:  `{"name": "Gold", "minPoints": -1}`,
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(MockRepository)
+			tt.setupMock(mockRepo)
+
+			svc := service.NewRewardService(mockRepo)
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/tiers", strings.NewReader(tt.requestBody))
+			rr := httptest.NewRecorder()
+
+			svc.CreateTier(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
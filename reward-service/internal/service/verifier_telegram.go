@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reward-service/api/calltypes"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"strings"
+)
+
+// TelegramVerifier checks that the user is a member of the Telegram channel through the Bot API.
+// The bot must be an administrator of the channel.
+type TelegramVerifier struct {
+	Client   *http.Client
+	BaseURL  string
+	BotToken string
+	ChatID   string
+}
+
+// NewTelegramVerifier creates Telegram verifier, baseURL defaults to the public Bot API.
+func NewTelegramVerifier(client *http.Client, baseURL, botToken, chatID string) *TelegramVerifier {
+	if baseURL == "" {
+		baseURL = consts.TelegramAPIURL
+	}
+
+	return &TelegramVerifier{
+		Client:   client,
+		BaseURL:  strings.TrimSuffix(baseURL, "/"),
+		BotToken: botToken,
+		ChatID:   chatID,
+	}
+}
+
+// Verify looks up the Telegram user with ID account in the channel.
+func (v *TelegramVerifier) Verify(ctx context.Context, _ *calltypes.Task, account string,
+) (calltypes.Verification, error) {
+	query := url.Values{}
+	query.Set("chat_id", v.ChatID)
+	query.Set("user_id", account)
+
+	endpoint := fmt.Sprintf("%s/bot%s/getChatMember?%s", v.BaseURL, v.BotToken, query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return calltypes.Verification{}, fmt.Errorf("failed to create telegram request: %w", err)
+	}
+
+	resp, err := v.Client.Do(req)
+	if err != nil {
+		return pendingVerification("telegram is unreachable"), nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+		return pendingVerification(fmt.Sprintf("telegram responded with status %d", resp.StatusCode)), nil
+	}
+
+	var body struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+		Result      struct {
+			Status   string `json:"status"`
+			IsMember bool   `json:"is_member"`
+		} `json:"result"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return calltypes.Verification{}, fmt.Errorf("%w: %w", errormsg.ErrVerifierResponse, err)
+	}
+
+	if !body.OK {
+		// The Bot API answers 400 for users it has never seen, they are surely not members. Any other
+		// rejection, such as a revoked token or a bot removed from the channel, is the operator's to fix.
+		if resp.StatusCode == http.StatusBadRequest && unknownTelegramUser(body.Description) {
+			return calltypes.Verification{Status: consts.VerificationFailed, Detail: body.Description}, nil
+		}
+
+		return calltypes.Verification{}, fmt.Errorf("%w: status %d: %s", errormsg.ErrVerifierResponse,
+			resp.StatusCode, body.Description)
+	}
+
+	switch body.Result.Status {
+	case "creator", "administrator", "member":
+		return calltypes.Verification{Status: consts.VerificationVerified, Detail: body.Result.Status}, nil
+	case "restricted":
+		if body.Result.IsMember {
+			return calltypes.Verification{Status: consts.VerificationVerified, Detail: body.Result.Status}, nil
+		}
+	}
+
+	return calltypes.Verification{Status: consts.VerificationFailed, Detail: body.Result.Status}, nil
+}
+
+// unknownTelegramUser reports whether the Bot API error description says that the user doesn't exist.
+func unknownTelegramUser(description string) bool {
+	return strings.Contains(description, "user not found") || strings.Contains(description, "PARTICIPANT_ID_INVALID")
+}
+
+func pendingVerification(detail string) calltypes.Verification {
+	return calltypes.Verification{Status: consts.VerificationPending, Detail: detail}
+}
@@ -0,0 +1,169 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reward-service/internal/service"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTelegramVerifier_Verify(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		statusCode     int
+		responseBody   string
+		expectedStatus string
+		expectedErr    error
+	}{
+		{
+			name:           "member",
+			statusCode:     http.StatusOK,
+			responseBody:   `{"ok": true, "result": {"status": "member"}}`,
+			expectedStatus: consts.VerificationVerified,
+		},
+		{
+			name:           "restricted member",
+			statusCode:     http.StatusOK,
+			responseBody:   `{"ok": true, "result": {"status": "restricted", "is_member": true}}`,
+			expectedStatus: consts.VerificationVerified,
+		},
+		{
+			name:           "left the channel",
+			statusCode:     http.StatusOK,
+			responseBody:   `{"ok": true, "result": {"status": "left"}}`,
+			expectedStatus: consts.VerificationFailed,
+		},
+		{
+			name:           "unknown user",
+			statusCode:     http.StatusBadRequest,
+			responseBody:   `{"ok": false, "description": "Bad Request: user not found"}`,
+			expectedStatus: consts.VerificationFailed,
+		},
+		{
+			name:           "invalid user ID",
+			statusCode:     http.StatusBadRequest,
+			responseBody:   `{"ok": false, "description": "Bad Request: PARTICIPANT_ID_INVALID"}`,
+			expectedStatus: consts.VerificationFailed,
+		},
+		{
+			name:         "unknown channel",
+			statusCode:   http.StatusBadRequest,
+			responseBody: `{"ok": false, "description": "Bad Request: chat not found"}`,
+			expectedErr:  errormsg.ErrVerifierResponse,
+		},
+		{
+			name:         "revoked bot token",
+			statusCode:   http.StatusUnauthorized,
+			responseBody: `{"ok": false, "description": "Unauthorized"}`,
+			expectedErr:  errormsg.ErrVerifierResponse,
+		},
+		{
+			name:         "bot removed from the channel",
+			statusCode:   http.StatusForbidden,
+			responseBody: `{"ok": false, "description": "Forbidden: bot is not a member of the channel chat"}`,
+			expectedErr:  errormsg.ErrVerifierResponse,
+		},
+		{
+			name:           "rate limited",
+			statusCode:     http.StatusTooManyRequests,
+			responseBody:   `{"ok": false}`,
+			expectedStatus: consts.VerificationPending,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/bottoken/getChatMember", r.URL.Path)
+				assert.Equal(t, "@channel", r.URL.Query().Get("chat_id"))
+				assert.Equal(t, "42", r.URL.Query().Get("user_id"))
+
+				w.WriteHeader(tt.statusCode)
+				_, _ = w.Write([]byte(tt.responseBody))
+			}))
+			defer stub.Close()
+
+			verifier := service.NewTelegramVerifier(stub.Client(), stub.URL, "token", "@channel")
+
+			verification, err := verifier.Verify(context.Background(), nil, "42")
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, verification.Status)
+		})
+	}
+}
+
+func TestXVerifier_Verify(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		pages          map[string]string
+		statusCode     int
+		expectedStatus string
+	}{
+		{
+			name: "account is on the second page",
+			pages: map[string]string{
+				"":     `{"data": [{"id": "1"}], "meta": {"next_token": "next"}}`,
+				"next": `{"data": [{"id": "777"}], "meta": {}}`,
+			},
+			statusCode:     http.StatusOK,
+			expectedStatus: consts.VerificationVerified,
+		},
+		{
+			name:           "account is not followed",
+			pages:          map[string]string{"": `{"data": [{"id": "1"}], "meta": {}}`},
+			statusCode:     http.StatusOK,
+			expectedStatus: consts.VerificationFailed,
+		},
+		{
+			name:           "unknown user",
+			pages:          map[string]string{"": `{"errors": []}`},
+			statusCode:     http.StatusNotFound,
+			expectedStatus: consts.VerificationFailed,
+		},
+		{
+			name:           "provider is down",
+			pages:          map[string]string{"": ``},
+			statusCode:     http.StatusServiceUnavailable,
+			expectedStatus: consts.VerificationPending,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/2/users/42/following", r.URL.Path)
+				assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+				w.WriteHeader(tt.statusCode)
+				_, _ = w.Write([]byte(tt.pages[r.URL.Query().Get("pagination_token")]))
+			}))
+			defer stub.Close()
+
+			verifier := service.NewXVerifier(stub.Client(), stub.URL, "token", "777")
+
+			verification, err := verifier.Verify(context.Background(), nil, "42")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, verification.Status)
+		})
+	}
+}
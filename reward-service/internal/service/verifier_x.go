@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reward-service/api/calltypes"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"strconv"
+	"strings"
+)
+
+// XVerifier checks that the user follows the account on X through the X API v2.
+type XVerifier struct {
+	Client      *http.Client
+	BaseURL     string
+	BearerToken string
+	AccountID   string
+}
+
+// NewXVerifier creates X verifier, baseURL defaults to the public X API.
+func NewXVerifier(client *http.Client, baseURL, bearerToken, accountID string) *XVerifier {
+	if baseURL == "" {
+		baseURL = consts.XAPIURL
+	}
+
+	return &XVerifier{
+		Client:      client,
+		BaseURL:     strings.TrimSuffix(baseURL, "/"),
+		BearerToken: bearerToken,
+		AccountID:   accountID,
+	}
+}
+
+// Verify looks for the account among the ones followed by the X user with ID account.
+// Only the first consts.XFollowingMaxPages pages are checked, the completion stays pending when there are more.
+func (v *XVerifier) Verify(ctx context.Context, _ *calltypes.Task, account string) (calltypes.Verification, error) {
+	paginationToken := ""
+
+	for range consts.XFollowingMaxPages {
+		page, verification, err := v.followingPage(ctx, account, paginationToken)
+		if err != nil || verification.Status != "" {
+			return verification, err
+		}
+
+		for _, user := range page.Data {
+			if user.ID == v.AccountID {
+				return calltypes.Verification{Status: consts.VerificationVerified}, nil
+			}
+		}
+
+		if page.Meta.NextToken == "" {
+			return calltypes.Verification{Status: consts.VerificationFailed, Detail: "account is not followed"}, nil
+		}
+
+		paginationToken = page.Meta.NextToken
+	}
+
+	return pendingVerification("too many followed accounts to check"), nil
+}
+
+type xFollowingPage struct {
+	Data []struct {
+		ID string `json:"id"`
+	} `json:"data"`
+	Meta struct {
+		NextToken string `json:"next_token"`
+	} `json:"meta"`
+}
+
+// followingPage fetches one page of the accounts followed by the user. The final verification is returned
+// instead of the page when the API can't answer or doesn't know the user.
+func (v *XVerifier) followingPage(ctx context.Context, account, paginationToken string,
+) (*xFollowingPage, calltypes.Verification, error) {
+	query := url.Values{}
+	query.Set("max_results", strconv.Itoa(consts.XFollowingPageSize))
+
+	if paginationToken != "" {
+		query.Set("pagination_token", paginationToken)
+	}
+
+	endpoint := fmt.Sprintf("%s/2/users/%s/following?%s", v.BaseURL, url.PathEscape(account), query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, calltypes.Verification{}, fmt.Errorf("failed to create x request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+v.BearerToken)
+
+	resp, err := v.Client.Do(req)
+	if err != nil {
+		return nil, pendingVerification("x is unreachable"), nil
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError:
+		return nil, pendingVerification(fmt.Sprintf("x responded with status %d", resp.StatusCode)), nil
+	case resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusNotFound:
+		return nil, calltypes.Verification{Status: consts.VerificationFailed, Detail: "unknown x account"}, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, calltypes.Verification{}, fmt.Errorf("%w: status %d", errormsg.ErrVerifierResponse, resp.StatusCode)
+	}
+
+	var page xFollowingPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, calltypes.Verification{}, fmt.Errorf("%w: %w", errormsg.ErrVerifierResponse, err)
+	}
+
+	return &page, calltypes.Verification{}, nil
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reward-service/api/calltypes"
+	"reward-service/api/server/httputils"
+	"reward-service/api/server/middleware"
+	"reward-service/internal/voucher"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+)
+
+// UploadVouchers godoc
+// @Summary Upload vouchers
+// @Description Adds voucher codes from the first column of the CSV to the inventory of the digital store item.
+// @Description A "code" header is skipped, codes the item already has are counted as duplicates. Available to admins.
+// @Tags Admin
+// @Accept text/csv
+// @Produce json
+// @Param id path int true "Store item ID"
+// @Success 201 {object} calltypes.JSONResponse{data=calltypes.VoucherUpload}
+// @Failure 400 {object} calltypes.ErrorResponse "Invalid store item ID or CSV"
+// @Failure 403 {object} calltypes.ErrorResponse "Insufficient role"
+// @Failure 404 {object} calltypes.ErrorResponse "Store item not found"
+// @Failure 409 {object} calltypes.ErrorResponse "Store item is not digital"
+// @Failure 503 {object} calltypes.ErrorResponse "Vouchers are not configured"
+// @Router /admin/store/items/{id}/vouchers [post].
+func (s *RewardService) UploadVouchers(w http.ResponseWriter, r *http.Request) {
+	if s.Vouchers == nil {
+		httputils.ErrorJSON(w, errormsg.ErrVouchersDisabled, http.StatusServiceUnavailable)
+
+		return
+	}
+
+	id, err := GetIDFromURL(r, "id")
+	if err != nil {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidID, http.StatusBadRequest)
+
+		return
+	}
+
+	codes, err := voucher.ParseCSV(http.MaxBytesReader(w, r.Body, consts.VoucherUploadMaxBytes))
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+
+	vouchers := make([]calltypes.Voucher, 0, len(codes))
+
+	for _, code := range codes {
+		encrypted, err := s.Vouchers.Encrypt(code)
+		if err != nil {
+			log.Printf("failed to encrypt voucher: %v", err)
+			httputils.ErrorJSON(w, errormsg.ErrSaveVouchers, http.StatusInternalServerError)
+
+			return
+		}
+
+		vouchers = append(vouchers, calltypes.Voucher{
+			ItemID:             id,
+			EncryptedCode:      encrypted,
+			Fingerprint:        s.Vouchers.Fingerprint(code),
+			FingerprintVersion: voucher.FingerprintVersion,
+		})
+	}
+
+	added, err := s.Repo.AddVouchers(id, vouchers)
+
+	switch {
+	case errors.Is(err, errormsg.ErrStoreItemNotFound):
+		httputils.ErrorJSON(w, err, http.StatusNotFound)
+
+		return
+	case errors.Is(err, errormsg.ErrStoreItemNotDigital):
+		httputils.ErrorJSON(w, err, http.StatusConflict)
+
+		return
+	case err != nil:
+		log.Printf("failed to add vouchers to store item %d: %v", id, err)
+		httputils.ErrorJSON(w, errormsg.ErrSaveVouchers, http.StatusInternalServerError)
+
+		return
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: fmt.Sprintf("Added %d vouchers to store item %d", added, id),
+		Data:    calltypes.VoucherUpload{Added: added, Duplicates: len(vouchers) - added},
+	}
+
+	err = httputils.WriteJSON(w, http.StatusCreated, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// GetVouchers godoc
+// @Summary Get my vouchers
+// @Description Returns the voucher codes claimed by the current user, newest first
+// @Tags Store
+// @Produce json
+// @Success 200 {object} calltypes.JSONResponse{data=[]calltypes.Voucher}
+// @Failure 401 {object} calltypes.ErrorResponse "Unauthorized"
+// @Failure 500 {object} calltypes.ErrorResponse "Failed to fetch vouchers"
+// @Failure 503 {object} calltypes.ErrorResponse "Vouchers are not configured"
+// @Router /users/me/vouchers [get].
+func (s *RewardService) GetVouchers(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		httputils.ErrorJSON(w, errormsg.ErrInvalidID, http.StatusUnauthorized)
+
+		return
+	}
+
+	vouchers, err := s.Repo.GetVouchers(userID)
+	if err != nil {
+		log.Printf("failed to fetch vouchers of user %d: %v", userID, err)
+		httputils.ErrorJSON(w, errormsg.ErrFetchVouchers, http.StatusInternalServerError)
+
+		return
+	}
+
+	for _, v := range vouchers {
+		v.Code, err = s.decryptVoucher(v.EncryptedCode)
+		if errors.Is(err, errormsg.ErrVouchersDisabled) {
+			httputils.ErrorJSON(w, err, http.StatusServiceUnavailable)
+
+			return
+		}
+
+		if err != nil {
+			log.Printf("failed to decrypt voucher %d: %v", v.ID, err)
+			httputils.ErrorJSON(w, errormsg.ErrFetchVouchers, http.StatusInternalServerError)
+
+			return
+		}
+	}
+
+	payload := calltypes.JSONResponse{
+		Error:   false,
+		Message: "Fetched vouchers",
+		Data:    vouchers,
+	}
+
+	err = httputils.WriteJSON(w, http.StatusOK, payload)
+	if err != nil {
+		httputils.ErrorJSON(w, err, http.StatusBadRequest)
+
+		return
+	}
+}
+
+// RefreshVoucherFingerprints recomputes the fingerprints made with an older fingerprint key version,
+// so duplicates of the codes uploaded before the key changed are still skipped. Nothing is read when
+// every voucher is up to date.
+func (s *RewardService) RefreshVoucherFingerprints() error {
+	if s.Vouchers == nil {
+		return nil
+	}
+
+	fingerprint := func(encryptedCode string) (string, error) {
+		code, err := s.decryptVoucher(encryptedCode)
+		if err != nil {
+			return "", err
+		}
+
+		return s.Vouchers.Fingerprint(code), nil
+	}
+
+	updated, err := s.Repo.RefreshVoucherFingerprints(voucher.FingerprintVersion, fingerprint)
+	if err != nil {
+		return fmt.Errorf("failed to refresh voucher fingerprints: %w", err)
+	}
+
+	if updated > 0 {
+		log.Printf("Refreshed fingerprints of %d vouchers", updated)
+	}
+
+	return nil
+}
+
+func (s *RewardService) decryptVoucher(encrypted string) (string, error) {
+	if s.Vouchers == nil {
+		return "", errormsg.ErrVouchersDisabled
+	}
+
+	return s.Vouchers.Decrypt(encrypted) //nolint: wrapcheck
+}
@@ -0,0 +1,182 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reward-service/api/calltypes"
+	"reward-service/api/server/middleware"
+	"reward-service/internal/service"
+	"reward-service/internal/voucher"
+	"reward-service/pkg/errormsg"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCipher(t *testing.T) *voucher.Cipher {
+	t.Helper()
+
+	cipher, err := voucher.NewCipher(bytes.Repeat([]byte{7}, 32))
+	require.NoError(t, err)
+
+	return cipher
+}
+
+func TestRewardService_UploadVouchers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		requestBody  string
+		disabled     bool
+		setupMock    func(*MockRepository)
+		expectedCode int
+	}{
+		{
+			name:        "vouchers are added",
+			requestBody: "code\nAAAA-1111\nBBBB-2222\nAAAA-1111\n",
+			setupMock: func(m *MockRepository) {
+				m.On("AddVouchers", 3, mock.MatchedBy(func(vouchers []calltypes.Voucher) bool {
+					return len(vouchers) == 2 && vouchers[0].EncryptedCode != "AAAA-1111" &&
+						vouchers[0].Fingerprint != vouchers[1].Fingerprint &&
+						vouchers[0].FingerprintVersion == voucher.FingerprintVersion
+				})).Return(1, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "empty CSV",
+			requestBody:  "code\n",
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:        "item is not digital",
+			requestBody: "AAAA-1111\n",
+			setupMock: func(m *MockRepository) {
+				m.On("AddVouchers", 3, mock.Anything).Return(0, errormsg.ErrStoreItemNotDigital)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:        "unknown item",
+			requestBody: "AAAA-1111\n",
+			setupMock: func(m *MockRepository) {
+				m.On("AddVouchers", 3, mock.Anything).Return(0, errormsg.ErrStoreItemNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "encryption key is not configured",
+			requestBody:  "AAAA-1111\n",
+			disabled:     true,
+			setupMock:    func(_ *MockRepository) {},
+			expectedCode: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(MockRepository)
+			tt.setupMock(mockRepo)
+
+			svc := service.NewRewardService(mockRepo)
+			if !tt.disabled {
+				svc.Vouchers = newTestCipher(t)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/store/items/3/vouchers",
+				strings.NewReader(tt.requestBody))
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "3")
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+
+			svc.UploadVouchers(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRewardService_GetVouchers(t *testing.T) {
+	t.Parallel()
+
+	mockRepo := new(MockRepository)
+	svc := service.NewRewardService(mockRepo)
+	svc.Vouchers = newTestCipher(t)
+
+	encrypted, err := svc.Vouchers.Encrypt("AAAA-1111")
+	require.NoError(t, err)
+
+	mockRepo.On("GetVouchers", 123).Return([]*calltypes.Voucher{
+		{ID: 1, ItemID: 3, ItemTitle: "Gift card", EncryptedCode: encrypted},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/me/vouchers", nil)
+	req = req.WithContext(middleware.WithIdentity(req.Context(), middleware.Identity{UserID: 123}))
+
+	rr := httptest.NewRecorder()
+
+	svc.GetVouchers(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var response struct {
+		Data []calltypes.Voucher `json:"data"`
+	}
+
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, "AAAA-1111", response.Data[0].Code)
+	assert.NotContains(t, rr.Body.String(), encrypted)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRewardService_RefreshVoucherFingerprints(t *testing.T) {
+	t.Parallel()
+
+	mockRepo := new(MockRepository)
+	svc := service.NewRewardService(mockRepo)
+	svc.Vouchers = newTestCipher(t)
+
+	encrypted, err := svc.Vouchers.Encrypt("AAAA-1111")
+	require.NoError(t, err)
+
+	mockRepo.On("RefreshVoucherFingerprints", voucher.FingerprintVersion, mock.Anything).Run(func(args mock.Arguments) {
+		fingerprint, ok := args.Get(1).(func(string) (string, error))
+		require.True(t, ok)
+
+		fresh, err := fingerprint(encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, svc.Vouchers.Fingerprint("AAAA-1111"), fresh)
+
+		_, err = fingerprint("garbage")
+		require.ErrorIs(t, err, errormsg.ErrDecryptVoucher)
+	}).Return(1, nil)
+
+	require.NoError(t, svc.RefreshVoucherFingerprints())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRewardService_RefreshVoucherFingerprintsDisabled(t *testing.T) {
+	t.Parallel()
+
+	mockRepo := new(MockRepository)
+	svc := service.NewRewardService(mockRepo)
+
+	require.NoError(t, svc.RefreshVoucherFingerprints())
+	mockRepo.AssertNotCalled(t, "RefreshVoucherFingerprints", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,81 @@
+// Package streak counts daily check-in streaks. Days are calendar dates in the user's timezone,
+// a missed day breaks the streak unless a streak freeze covers it.
+package streak
+
+import (
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"time"
+)
+
+// State is the streak of one user. LastCheckIn is a date as returned by Date, zero when the user never checked in.
+type State struct {
+	Current     int
+	Longest     int
+	LastCheckIn time.Time
+	Freezes     int
+}
+
+// Date returns the calendar date of now in loc as midnight UTC, the form dates are stored and compared in.
+func Date(now time.Time, loc *time.Location) time.Time {
+	y, m, d := now.In(loc).Date()
+
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// CheckIn returns the state after the check-in on today and the number of freezes it used.
+func CheckIn(state State, today time.Time) (State, int, error) {
+	if !state.LastCheckIn.IsZero() && !today.After(state.LastCheckIn) {
+		return state, 0, errormsg.ErrAlreadyCheckedIn
+	}
+
+	used := 0
+
+	switch missed := missedDays(state, today); {
+	case state.LastCheckIn.IsZero():
+		state.Current = 1
+	case missed <= state.Freezes:
+		used = missed
+		state.Freezes -= missed
+		state.Current++
+	default:
+		state.Current = 1
+	}
+
+	state.Longest = max(state.Longest, state.Current)
+	state.LastCheckIn = today
+
+	return state, used, nil
+}
+
+// Current returns the streak the user still can continue on today, zero when it is already broken.
+func Current(state State, today time.Time) int {
+	if state.LastCheckIn.IsZero() || missedDays(state, today) > state.Freezes {
+		return 0
+	}
+
+	return state.Current
+}
+
+// Reward returns the points for the check-in that makes the streak current days long. The reward grows
+// by consts.CheckinRewardStep every day up to day consts.CheckinMaxRewardDay.
+func Reward(current int) int {
+	return consts.CheckinBaseReward + consts.CheckinRewardStep*(min(current, consts.CheckinMaxRewardDay)-1)
+}
+
+// missedDays returns the number of days between the last check-in and today without a check-in.
+func missedDays(state State, today time.Time) int {
+	return max(int(today.Sub(state.LastCheckIn).Hours()/24)-1, 0) //nolint: mnd
+}
+
+// ValidTimezone reports whether the name is a zone of the IANA database. The server's "Local" zone
+// and the empty name, which LoadLocation accepts, are not.
+func ValidTimezone(name string) bool {
+	if name == "" || name == "Local" {
+		return false
+	}
+
+	_, err := time.LoadLocation(name)
+
+	return err == nil
+}
@@ -0,0 +1,135 @@
+package streak_test
+
+import (
+	"reward-service/internal/streak"
+	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func day(d int) time.Time {
+	return time.Date(2025, time.September, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestDate(t *testing.T) {
+	t.Parallel()
+
+	loc, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	// 20:00 UTC on the 1st is already the 2nd in Tokyo.
+	now := time.Date(2025, time.September, 1, 20, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, day(1), streak.Date(now, time.UTC))
+	assert.Equal(t, day(2), streak.Date(now, loc))
+}
+
+func TestCheckIn(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		state    streak.State
+		today    time.Time
+		expected streak.State
+		used     int
+		err      error
+	}{
+		{
+			name:     "first check-in",
+			today:    day(1),
+			expected: streak.State{Current: 1, Longest: 1, LastCheckIn: day(1)},
+		},
+		{
+			name:     "consecutive day",
+			state:    streak.State{Current: 3, Longest: 3, LastCheckIn: day(3)},
+			today:    day(4),
+			expected: streak.State{Current: 4, Longest: 4, LastCheckIn: day(4)},
+		},
+		{
+			name:     "missed day resets the streak",
+			state:    streak.State{Current: 5, Longest: 8, LastCheckIn: day(3)},
+			today:    day(5),
+			expected: streak.State{Current: 1, Longest: 8, LastCheckIn: day(5)},
+		},
+		{
+			name:     "freeze covers the missed day",
+			state:    streak.State{Current: 5, Longest: 5, LastCheckIn: day(3), Freezes: 2},
+			today:    day(5),
+			expected: streak.State{Current: 6, Longest: 6, LastCheckIn: day(5), Freezes: 1},
+			used:     1,
+		},
+		{
+			name:     "freezes don't cover the gap",
+			state:    streak.State{Current: 5, Longest: 5, LastCheckIn: day(3), Freezes: 1},
+			today:    day(6),
+			expected: streak.State{Current: 1, Longest: 5, LastCheckIn: day(6), Freezes: 1},
+		},
+		{
+			name:     "second check-in on the same day",
+			state:    streak.State{Current: 2, Longest: 2, LastCheckIn: day(4)},
+			today:    day(4),
+			expected: streak.State{Current: 2, Longest: 2, LastCheckIn: day(4)},
+			err:      errormsg.ErrAlreadyCheckedIn,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			state, used, err := streak.CheckIn(tt.state, tt.today)
+
+			require.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.expected, state)
+			assert.Equal(t, tt.used, used)
+		})
+	}
+}
+
+func TestCurrent(t *testing.T) {
+	t.Parallel()
+
+	state := streak.State{Current: 4, Longest: 4, LastCheckIn: day(3), Freezes: 1}
+
+	assert.Equal(t, 4, streak.Current(state, day(4)))
+	assert.Equal(t, 4, streak.Current(state, day(5)))
+	assert.Equal(t, 0, streak.Current(state, day(6)))
+	assert.Equal(t, 0, streak.Current(streak.State{}, day(6)))
+}
+
+func TestReward(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, consts.CheckinBaseReward, streak.Reward(1))
+	assert.Equal(t, consts.CheckinBaseReward+consts.CheckinRewardStep, streak.Reward(2))
+	assert.Equal(t, streak.Reward(consts.CheckinMaxRewardDay), streak.Reward(consts.CheckinMaxRewardDay+10))
+}
+
+func TestValidTimezone(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		timezone string
+		valid    bool
+	}{
+		{name: "IANA zone", timezone: "Europe/Moscow", valid: true},
+		{name: "UTC", timezone: "UTC", valid: true},
+		{name: "unknown zone", timezone: "Mars/Olympus"},
+		{name: "server's local zone", timezone: "Local"},
+		{name: "empty name", timezone: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.valid, streak.ValidTimezone(tt.timezone))
+		})
+	}
+}
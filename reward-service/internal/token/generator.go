@@ -1,51 +1,54 @@
 package token
 
 import (
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"github.com/golang-jwt/jwt"
 	"os"
+	"reward-service/api/calltypes"
 	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"strings"
 	"time"
 )
 
 type ServiceToken struct {
-	SecretKey string
+	SecretKey  string
+	RefreshKey string
 }
 
+// NewTokenService creates token service. Refresh tokens are hashed with REFRESH_TOKEN_KEY,
+// SECRET_KEY is used instead when it is not set.
 func NewTokenService() *ServiceToken {
-	return &ServiceToken{
-		SecretKey: os.Getenv("SECRET_KEY"),
+	refreshKey := os.Getenv("REFRESH_TOKEN_KEY")
+	if refreshKey == "" {
+		refreshKey = os.Getenv("SECRET_KEY")
 	}
-}
 
-// GenerateTokens when called generates access tokens.
-func (ts *ServiceToken) GenerateTokens(userID int) (string, string, error) {
-	accessToken, err := ts.GenerateAccessToken(userID)
-	if err != nil {
-		return "", "", fmt.Errorf("failed to generate access token: %w", err)
-	}
-
-	hashedRefreshToken, err := GenerateRefreshToken()
-
-	if err != nil {
-		return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
+	return &ServiceToken{
+		SecretKey:  os.Getenv("SECRET_KEY"),
+		RefreshKey: refreshKey,
 	}
+}
 
+// GenerateAccessToken generates access tokens bound to the user's session and carrying the user's role.
+func (ts *ServiceToken) GenerateAccessToken(userID, sessionID int, role string) (string, error) {
+	tokenID, err := randomString(consts.AccessTokenIDLength)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to store refresh token: %w", err)
+		return "", err
 	}
 
-	return accessToken, hashedRefreshToken, nil
-}
-
-// GenerateAccessToken generates access tokens.
-func (ts *ServiceToken) GenerateAccessToken(userID int) (string, error) {
 	claims := jwt.MapClaims{
-		"sub": userID,
-		"exp": time.Now().Add(consts.AccessTokenExpireTime).Unix(),
-		"iat": time.Now().Unix(),
+		"sub":  userID,
+		"sid":  sessionID,
+		"role": role,
+		"jti":  tokenID,
+		"exp":  time.Now().Add(consts.AccessTokenExpireTime).Unix(),
+		"iat":  time.Now().Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS512, claims)
@@ -58,14 +61,43 @@ func (ts *ServiceToken) GenerateAccessToken(userID int) (string, error) {
 	return signedToken, nil
 }
 
-// GenerateRefreshToken generates refresh tokens.
+// GenerateRefreshToken generates refresh tokens in "selector.verifier" form.
+// The selector is used to find the stored token, the verifier is only ever stored hashed.
 func GenerateRefreshToken() (string, error) {
-	tokenBytes := make([]byte, consts.RefreshTokenLength)
+	selector, err := randomString(consts.RefreshTokenSelectorLength)
+	if err != nil {
+		return "", err
+	}
+
+	verifier, err := randomString(consts.RefreshTokenLength)
+	if err != nil {
+		return "", err
+	}
+
+	return selector + "." + verifier, nil
+}
+
+// HashRefreshToken splits raw refresh token and hashes its verifier with HMAC-SHA256.
+func (ts *ServiceToken) HashRefreshToken(refreshToken string) (calltypes.RefreshToken, error) {
+	selector, verifier, found := strings.Cut(refreshToken, ".")
+	if !found || selector == "" || verifier == "" {
+		return calltypes.RefreshToken{}, errormsg.ErrInvalidRefreshToken
+	}
+
+	mac := hmac.New(sha256.New, []byte(ts.RefreshKey))
+	mac.Write([]byte(verifier))
+
+	return calltypes.RefreshToken{
+		Selector: selector,
+		Hash:     hex.EncodeToString(mac.Sum(nil)),
+	}, nil
+}
+
+func randomString(length int) (string, error) {
+	tokenBytes := make([]byte, length)
 	if _, err := rand.Read(tokenBytes); err != nil {
 		return "", fmt.Errorf("failed to generate random bytes: %w", err)
 	}
 
-	refreshToken := base64.URLEncoding.EncodeToString(tokenBytes)
-
-	return refreshToken, nil
+	return base64.RawURLEncoding.EncodeToString(tokenBytes), nil
 }
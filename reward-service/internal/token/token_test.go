@@ -6,6 +6,8 @@ import (
 	"os"
 	"reward-service/internal/token"
 	"reward-service/pkg/consts"
+	"reward-service/pkg/errormsg"
+	"strings"
 	"testing"
 	"time"
 
@@ -36,17 +38,19 @@ func TestGenerateAccessToken(t *testing.T) {
 	setup()
 
 	tests := []struct {
-		name     string
-		userID   int
-		setup    func()
-		wantErr  bool
-		validate func(t *testing.T, token string)
+		name      string
+		userID    int
+		sessionID int
+		setup     func()
+		wantErr   bool
+		validate  func(t *testing.T, token string)
 	}{
 		{
-			name:    "successful token generation",
-			userID:  1,
-			setup:   func() {},
-			wantErr: false,
+			name:      "successful token generation",
+			userID:    1,
+			sessionID: 1,
+			setup:     func() {},
+			wantErr:   false,
 			validate: func(t *testing.T, token string) {
 				t.Helper()
 				parsed, err := jwt.Parse(token, func(_ *jwt.Token) (interface{}, error) {
@@ -83,7 +87,7 @@ func TestGenerateAccessToken(t *testing.T) {
 			}
 
 			g := token.NewTokenService()
-			tkn, err := g.GenerateAccessToken(res.userID)
+			tkn, err := g.GenerateAccessToken(res.userID, res.sessionID, consts.RoleUser)
 
 			if res.wantErr {
 				require.NoError(t, err)
@@ -106,7 +110,7 @@ func TestTokenExpiration(t *testing.T) {
 	t.Run("token should expire after specified time", func(t *testing.T) {
 		t.Parallel()
 
-		tkn, err := g.GenerateAccessToken(1)
+		tkn, err := g.GenerateAccessToken(1, 1, consts.RoleUser)
 		require.NoError(t, err)
 
 		parser := jwt.Parser{}
@@ -135,7 +139,7 @@ func TestTokenValidation(t *testing.T) {
 	t.Run("modified token should be invalid", func(t *testing.T) {
 		t.Parallel()
 
-		tkn, err := g.GenerateAccessToken(1)
+		tkn, err := g.GenerateAccessToken(1, 1, consts.RoleUser)
 		require.NoError(t, err)
 
 		tkn = tkn[:len(tkn)-2] + "xx"
@@ -147,3 +151,82 @@ func TestTokenValidation(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestHashRefreshToken(t *testing.T) {
+	t.Parallel()
+	setup()
+
+	g := token.NewTokenService()
+
+	t.Run("hash is keyed and does not contain the verifier", func(t *testing.T) {
+		t.Parallel()
+
+		raw, err := token.GenerateRefreshToken()
+		require.NoError(t, err)
+
+		hashed, err := g.HashRefreshToken(raw)
+		require.NoError(t, err)
+
+		selector, verifier, found := strings.Cut(raw, ".")
+		require.True(t, found)
+		assert.Equal(t, selector, hashed.Selector)
+		assert.NotContains(t, hashed.Hash, verifier)
+
+		other := &token.ServiceToken{RefreshKey: "another_key"}
+		otherHashed, err := other.HashRefreshToken(raw)
+		require.NoError(t, err)
+		assert.NotEqual(t, hashed.Hash, otherHashed.Hash)
+	})
+
+	t.Run("malformed token is rejected", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := g.HashRefreshToken("without-separator")
+		assert.ErrorIs(t, err, errormsg.ErrInvalidRefreshToken)
+	})
+}
+
+func TestParseAccessClaims(t *testing.T) {
+	t.Parallel()
+	setup()
+
+	g := token.NewTokenService()
+
+	t.Run("every access token gets a unique ID", func(t *testing.T) {
+		t.Parallel()
+
+		first, err := g.GenerateAccessToken(3, 9, consts.RoleModerator)
+		require.NoError(t, err)
+
+		second, err := g.GenerateAccessToken(3, 9, consts.RoleModerator)
+		require.NoError(t, err)
+
+		firstClaims, err := g.ValidateAccessToken(first)
+		require.NoError(t, err)
+
+		secondClaims, err := g.ValidateAccessToken(second)
+		require.NoError(t, err)
+
+		parsedFirst, err := token.ParseAccessClaims(firstClaims)
+		require.NoError(t, err)
+
+		parsedSecond, err := token.ParseAccessClaims(secondClaims)
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, parsedFirst.UserID)
+		assert.Equal(t, 9, parsedFirst.SessionID)
+		assert.Equal(t, consts.RoleModerator, parsedFirst.Role)
+		assert.NotEmpty(t, parsedFirst.TokenID)
+		assert.NotEqual(t, parsedFirst.TokenID, parsedSecond.TokenID)
+		assert.WithinDuration(t, time.Now().Add(consts.AccessTokenExpireTime), parsedFirst.ExpiresAt, 2*time.Second)
+	})
+
+	t.Run("token without ID is rejected", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := token.ParseAccessClaims(jwt.MapClaims{
+			"sub": float64(1), "sid": float64(1), "role": consts.RoleUser, "iat": float64(1), "exp": float64(2),
+		})
+		assert.ErrorIs(t, err, errormsg.ErrInvalidTokenClaims)
+	})
+}
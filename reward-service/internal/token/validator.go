@@ -4,12 +4,23 @@ import (
 	"fmt"
 	"github.com/golang-jwt/jwt"
 	"reward-service/pkg/errormsg"
+	"time"
 )
 
 type Validator struct {
 	SecretKey string
 }
 
+// AccessClaims holds the claims of a validated access token.
+type AccessClaims struct {
+	TokenID   string
+	UserID    int
+	SessionID int
+	Role      string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
 // ValidateAccessToken validate provided access token.
 func (ts *ServiceToken) ValidateAccessToken(tokenString string) (jwt.MapClaims, error) {
 	fmt.Println("access token received in ValidateAccessToken before parsing is: ", tokenString)
@@ -34,3 +45,45 @@ func (ts *ServiceToken) ValidateAccessToken(tokenString string) (jwt.MapClaims,
 
 	return nil, errormsg.ErrInvalidToken
 }
+
+// ParseAccessClaims extracts typed claims from validated access token claims.
+func ParseAccessClaims(claims jwt.MapClaims) (AccessClaims, error) {
+	userID, ok := claims["sub"].(float64)
+	if !ok {
+		return AccessClaims{}, errormsg.ErrInvalidTokenClaims
+	}
+
+	sessionID, ok := claims["sid"].(float64)
+	if !ok {
+		return AccessClaims{}, errormsg.ErrInvalidTokenClaims
+	}
+
+	tokenID, ok := claims["jti"].(string)
+	if !ok || tokenID == "" {
+		return AccessClaims{}, errormsg.ErrInvalidTokenClaims
+	}
+
+	issuedAt, ok := claims["iat"].(float64)
+	if !ok {
+		return AccessClaims{}, errormsg.ErrInvalidTokenClaims
+	}
+
+	expiresAt, ok := claims["exp"].(float64)
+	if !ok {
+		return AccessClaims{}, errormsg.ErrInvalidTokenClaims
+	}
+
+	role, ok := claims["role"].(string)
+	if !ok || role == "" {
+		return AccessClaims{}, errormsg.ErrInvalidTokenClaims
+	}
+
+	return AccessClaims{
+		TokenID:   tokenID,
+		UserID:    int(userID),
+		SessionID: int(sessionID),
+		Role:      role,
+		IssuedAt:  time.Unix(int64(issuedAt), 0),
+		ExpiresAt: time.Unix(int64(expiresAt), 0),
+	}, nil
+}
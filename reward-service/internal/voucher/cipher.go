@@ -0,0 +1,95 @@
+// Package voucher encrypts voucher codes at rest and parses voucher uploads.
+package voucher
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"reward-service/pkg/errormsg"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// fingerprintKeyInfo labels the HKDF derivation of the fingerprint key, so the key never doubles
+// as the AES key.
+const fingerprintKeyInfo = "reward-service voucher fingerprint v1"
+
+// FingerprintVersion is the version of the fingerprint key stored with every voucher. It goes up together
+// with fingerprintKeyInfo, vouchers fingerprinted with an older version are backfilled.
+const FingerprintVersion = 1
+
+// Cipher encrypts voucher codes with AES-256-GCM. Fingerprints let duplicate codes be found
+// without decrypting the inventory, they are keyed with a separate key derived from the AES key.
+type Cipher struct {
+	aead           cipher.AEAD
+	fingerprintKey []byte
+}
+
+// NewCipher creates cipher from a 32 bytes long key.
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != 32 { //nolint: mnd
+		return nil, errormsg.ErrInvalidVoucherKey
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create voucher cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create voucher cipher: %w", err)
+	}
+
+	fingerprintKey := make([]byte, len(key))
+
+	_, err = io.ReadFull(hkdf.New(sha256.New, key, nil, []byte(fingerprintKeyInfo)), fingerprintKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive fingerprint key: %w", err)
+	}
+
+	return &Cipher{aead: aead, fingerprintKey: fingerprintKey}, nil
+}
+
+// Encrypt returns the base64 encoded nonce followed by the sealed code.
+func (c *Cipher) Encrypt(code string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := c.aead.Seal(nonce, nonce, []byte(code), nil)
+
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens the code sealed by Encrypt.
+func (c *Cipher) Decrypt(encrypted string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return "", errormsg.ErrDecryptVoucher
+	}
+
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+
+	code, err := c.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", errormsg.ErrDecryptVoucher
+	}
+
+	return string(code), nil
+}
+
+// Fingerprint returns the hex encoded HMAC-SHA256 of the code under the fingerprint key.
+func (c *Cipher) Fingerprint(code string) string {
+	mac := hmac.New(sha256.New, c.fingerprintKey)
+	mac.Write([]byte(code))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,51 @@
+package voucher
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reward-service/pkg/errormsg"
+	"strings"
+)
+
+// ParseCSV reads voucher codes from the first column of the CSV. A "code" header is skipped, blank codes and
+// codes repeated within the upload are dropped.
+func ParseCSV(r io.Reader) ([]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var codes []string
+
+	seen := make(map[string]struct{})
+
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errormsg.ErrInvalidVoucherCSV, err)
+		}
+
+		code := strings.TrimSpace(record[0])
+		if code == "" || (line == 1 && strings.EqualFold(code, "code")) {
+			continue
+		}
+
+		if _, ok := seen[code]; ok {
+			continue
+		}
+
+		seen[code] = struct{}{}
+		codes = append(codes, code)
+	}
+
+	if len(codes) == 0 {
+		return nil, errormsg.ErrInvalidVoucherCSV
+	}
+
+	return codes, nil
+}
@@ -0,0 +1,101 @@
+package voucher_test
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"reward-service/internal/voucher"
+	"reward-service/pkg/errormsg"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCipher(t *testing.T) {
+	t.Parallel()
+
+	key := bytes.Repeat([]byte{7}, 32)
+
+	c, err := voucher.NewCipher(key)
+	require.NoError(t, err)
+
+	encrypted, err := c.Encrypt("GIFT-1234")
+	require.NoError(t, err)
+	assert.NotContains(t, encrypted, "GIFT-1234")
+
+	again, err := c.Encrypt("GIFT-1234")
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted, again, "every encryption uses a new nonce")
+
+	code, err := c.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "GIFT-1234", code)
+
+	assert.Equal(t, c.Fingerprint("GIFT-1234"), c.Fingerprint("GIFT-1234"))
+	assert.NotEqual(t, c.Fingerprint("GIFT-1234"), c.Fingerprint("GIFT-1235"))
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("GIFT-1234"))
+	assert.NotEqual(t, hex.EncodeToString(mac.Sum(nil)), c.Fingerprint("GIFT-1234"),
+		"fingerprints are not keyed with the AES key")
+
+	other, err := voucher.NewCipher(bytes.Repeat([]byte{8}, 32))
+	require.NoError(t, err)
+
+	_, err = other.Decrypt(encrypted)
+	require.ErrorIs(t, err, errormsg.ErrDecryptVoucher)
+
+	_, err = voucher.NewCipher([]byte("short"))
+	require.ErrorIs(t, err, errormsg.ErrInvalidVoucherKey)
+}
+
+func TestParseCSV(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+		err      error
+	}{
+		{
+			name:     "header and extra columns",
+			input:    "code,value\nGIFT-1,10\nGIFT-2,10\n",
+			expected: []string{"GIFT-1", "GIFT-2"},
+		},
+		{
+			name:     "blank and repeated codes are dropped",
+			input:    "GIFT-1\n\n  GIFT-2\nGIFT-1\n",
+			expected: []string{"GIFT-1", "GIFT-2"},
+		},
+		{
+			name:  "no codes",
+			input: "code\n",
+			err:   errormsg.ErrInvalidVoucherCSV,
+		},
+		{
+			name:  "broken quotes",
+			input: "\"GIFT-1\n",
+			err:   errormsg.ErrInvalidVoucherCSV,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			codes, err := voucher.ParseCSV(strings.NewReader(tt.input))
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, codes)
+		})
+	}
+}
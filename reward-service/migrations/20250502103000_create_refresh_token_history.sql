@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS refresh_token_history(
+    id serial PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token TEXT NOT NULL,
+    rotated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
+
+    CREATE INDEX idx_refresh_token_history_token ON refresh_token_history(token);
+    CREATE INDEX idx_refresh_token_history_user_rotated ON refresh_token_history(user_id, rotated_at);
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS refresh_token_history;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- Plaintext refresh tokens can not be hashed in place, so every stored token is invalidated.
+UPDATE users SET refresh_token = NULL, refresh_token_expires = NULL;
+DELETE FROM refresh_token_history;
+
+DROP INDEX IF EXISTS idx_users_refresh_token;
+DROP INDEX IF EXISTS idx_refresh_token_history_token;
+
+ALTER TABLE users
+ADD COLUMN refresh_token_selector VARCHAR(32);
+
+ALTER TABLE refresh_token_history
+ADD COLUMN selector VARCHAR(32) NOT NULL;
+
+CREATE UNIQUE INDEX idx_users_refresh_token_selector ON users(refresh_token_selector);
+CREATE INDEX idx_refresh_token_history_selector ON refresh_token_history(selector);
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_refresh_token_history_selector;
+DROP INDEX IF EXISTS idx_users_refresh_token_selector;
+
+ALTER TABLE refresh_token_history
+DROP COLUMN selector;
+
+ALTER TABLE users
+DROP COLUMN refresh_token_selector;
+
+CREATE INDEX idx_users_refresh_token ON users(refresh_token);
+CREATE INDEX idx_refresh_token_history_token ON refresh_token_history(token);
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
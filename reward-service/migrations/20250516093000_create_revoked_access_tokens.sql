@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS revoked_access_tokens(
+    jti VARCHAR(64) PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP NOT NULL
+    );
+
+    CREATE INDEX idx_revoked_access_tokens_expires_at ON revoked_access_tokens(expires_at);
+
+ALTER TABLE users
+ADD COLUMN tokens_revoked_at TIMESTAMP;
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+ALTER TABLE users
+DROP COLUMN tokens_revoked_at;
+
+DROP TABLE IF EXISTS revoked_access_tokens;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
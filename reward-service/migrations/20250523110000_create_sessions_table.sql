@@ -0,0 +1,59 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS sessions(
+    id serial PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    selector VARCHAR(32) NOT NULL,
+    token_hash VARCHAR(128) NOT NULL,
+    user_agent TEXT NOT NULL DEFAULT '',
+    ip VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL
+    );
+
+    CREATE UNIQUE INDEX idx_sessions_selector ON sessions(selector);
+    CREATE INDEX idx_sessions_user_id ON sessions(user_id);
+    CREATE INDEX idx_sessions_expires_at ON sessions(expires_at);
+
+INSERT INTO sessions (user_id, selector, token_hash, created_at, last_used_at, expires_at)
+SELECT id, refresh_token_selector, refresh_token, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, refresh_token_expires
+FROM users
+WHERE refresh_token_selector IS NOT NULL AND refresh_token_expires > CURRENT_TIMESTAMP;
+
+ALTER TABLE refresh_token_history
+ADD COLUMN session_id INT REFERENCES sessions(id) ON DELETE CASCADE;
+
+-- Every user had at most one session before, so rotated tokens belong to that one.
+UPDATE refresh_token_history h SET session_id = s.id FROM sessions s WHERE s.user_id = h.user_id;
+DELETE FROM refresh_token_history WHERE session_id IS NULL;
+
+ALTER TABLE refresh_token_history
+ALTER COLUMN session_id SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_users_refresh_token_selector;
+DROP INDEX IF EXISTS idx_users_refresh_token_expires;
+
+ALTER TABLE users
+DROP COLUMN refresh_token_selector,
+DROP COLUMN refresh_token,
+DROP COLUMN refresh_token_expires;
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+ALTER TABLE users
+ADD COLUMN refresh_token TEXT,
+ADD COLUMN refresh_token_expires TIMESTAMP,
+ADD COLUMN refresh_token_selector VARCHAR(32);
+
+CREATE UNIQUE INDEX idx_users_refresh_token_selector ON users(refresh_token_selector);
+CREATE INDEX idx_users_refresh_token_expires ON users(refresh_token_expires);
+
+ALTER TABLE refresh_token_history
+DROP COLUMN session_id;
+
+DROP TABLE IF EXISTS sessions;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
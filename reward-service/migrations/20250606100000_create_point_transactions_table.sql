@@ -0,0 +1,28 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS point_transactions(
+    id bigserial PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    delta INT NOT NULL,
+    reason VARCHAR(64) NOT NULL,
+    reference_id VARCHAR(255) NOT NULL DEFAULT '',
+    actor_id INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
+
+    CREATE INDEX idx_point_transactions_user_id ON point_transactions(user_id, id DESC);
+    CREATE INDEX idx_point_transactions_created_at ON point_transactions(created_at);
+
+-- Existing balances become the opening entries of the ledger, so users.score stays equal to the ledger sum.
+INSERT INTO point_transactions (user_id, delta, reason, created_at)
+SELECT id, score, 'opening_balance', CURRENT_TIMESTAMP
+FROM users
+WHERE score <> 0;
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS point_transactions;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS referrals(
+    id serial PRIMARY KEY,
+    referrer_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    referee_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT referrals_referee_unique UNIQUE (referee_id),
+    CONSTRAINT referrals_not_self CHECK (referrer_id <> referee_id)
+    );
+
+    CREATE INDEX idx_referrals_referrer_id ON referrals(referrer_id);
+
+-- Redemptions made before this table existed are recovered from the ledger, earliest one wins.
+INSERT INTO referrals (referrer_id, referee_id, code, created_at)
+SELECT DISTINCT ON (referee.user_id) referrer.user_id, referee.user_id, COALESCE(u.referrer, ''), referee.created_at
+FROM point_transactions referee
+JOIN point_transactions referrer
+    ON referrer.reason = 'referrer_bonus'
+    AND referrer.reference_id = referee.user_id::text
+    AND referee.reference_id = referrer.user_id::text
+JOIN users u ON u.id = referrer.user_id
+WHERE referee.reason = 'referee_bonus'
+ORDER BY referee.user_id, referee.created_at;
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS referrals;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- Users registered without a code get a generated one, the alphabet leaves out 0, O, 1, I and L
+-- and the id suffix keeps the codes unique.
+UPDATE users
+SET referrer = (
+    SELECT string_agg(substr('23456789ABCDEFGHJKMNPQRSTUVWXYZ', (random() * 30)::int + 1, 1), '')
+    FROM generate_series(1, 8)
+    WHERE users.id IS NOT NULL
+    ) || '-' || id
+WHERE referrer IS NULL OR referrer = '';
+
+ALTER TABLE users ALTER COLUMN referrer SET NOT NULL;
+
+CREATE INDEX idx_users_referrer_upper ON users(upper(referrer));
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_users_referrer_upper;
+
+ALTER TABLE users ALTER COLUMN referrer DROP NOT NULL;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
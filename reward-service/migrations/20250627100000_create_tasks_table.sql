@@ -0,0 +1,31 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS tasks(
+    id serial PRIMARY KEY,
+    slug VARCHAR(64) NOT NULL UNIQUE,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    reward INT NOT NULL CHECK (reward > 0),
+    repeatable BOOLEAN NOT NULL DEFAULT FALSE,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    starts_at TIMESTAMP,
+    ends_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT tasks_window CHECK (starts_at IS NULL OR ends_at IS NULL OR starts_at < ends_at)
+    );
+
+    CREATE INDEX idx_tasks_active ON tasks(active, starts_at, ends_at);
+
+INSERT INTO tasks (slug, title, description, reward, repeatable) VALUES
+    ('telegram-sign', 'Subscribe to the Telegram channel', 'Join our Telegram channel', 50, FALSE),
+    ('x-sign', 'Follow us on X', 'Follow our account on X', 75, FALSE),
+    ('some-task', 'Some task', 'Complete some task', 100, TRUE);
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS tasks;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
@@ -0,0 +1,52 @@
+-- +goose Up
+ALTER TABLE tasks
+    ADD COLUMN policy VARCHAR(20) NOT NULL DEFAULT 'once'
+        CONSTRAINT tasks_policy_check CHECK (policy IN ('once', 'daily', 'limited', 'unlimited')),
+    ADD COLUMN max_completions INT NOT NULL DEFAULT 0 CHECK (max_completions >= 0),
+    ADD COLUMN period_seconds INT NOT NULL DEFAULT 0 CHECK (period_seconds >= 0),
+    ADD COLUMN cooldown_seconds INT NOT NULL DEFAULT 0 CHECK (cooldown_seconds >= 0);
+
+UPDATE tasks SET policy = 'unlimited' WHERE repeatable;
+
+ALTER TABLE tasks DROP COLUMN repeatable;
+
+CREATE TABLE IF NOT EXISTS task_completions(
+    id bigserial PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    task_id INT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    completed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
+
+    CREATE INDEX idx_task_completions_user_task ON task_completions(user_id, task_id, completed_at DESC);
+
+-- Completions made before this table existed are recovered from the ledger,
+-- the legacy routes wrote the last segment of their path as the reference.
+INSERT INTO task_completions (user_id, task_id, completed_at)
+SELECT pt.user_id, t.id, pt.created_at
+FROM point_transactions pt
+JOIN tasks t ON t.slug = CASE pt.reference_id
+    WHEN 'telegramSign' THEN 'telegram-sign'
+    WHEN 'XSign' THEN 'x-sign'
+    WHEN 'complete' THEN 'some-task'
+    ELSE pt.reference_id
+    END
+WHERE pt.reason = 'task_completion';
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS task_completions;
+
+ALTER TABLE tasks ADD COLUMN repeatable BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE tasks SET repeatable = policy <> 'once';
+
+ALTER TABLE tasks
+    DROP COLUMN policy,
+    DROP COLUMN max_completions,
+    DROP COLUMN period_seconds,
+    DROP COLUMN cooldown_seconds;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+ALTER TABLE tasks
+    ADD COLUMN verifier VARCHAR(32) NOT NULL DEFAULT ''
+        CONSTRAINT tasks_verifier_check CHECK (verifier IN ('', 'telegram', 'x'));
+
+UPDATE tasks SET verifier = 'telegram' WHERE slug = 'telegram-sign';
+UPDATE tasks SET verifier = 'x' WHERE slug = 'x-sign';
+
+-- Completions recorded before verification existed were rewarded without a check.
+ALTER TABLE task_completions
+    ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'verified'
+        CONSTRAINT task_completions_status_check CHECK (status IN ('verified', 'pending', 'failed')),
+    ADD COLUMN account VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN detail TEXT NOT NULL DEFAULT '';
+
+DROP INDEX IF EXISTS idx_task_completions_user_task;
+
+CREATE INDEX idx_task_completions_user_task ON task_completions(user_id, task_id, status, completed_at DESC);
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DELETE FROM task_completions WHERE status <> 'verified';
+
+DROP INDEX IF EXISTS idx_task_completions_user_task;
+
+ALTER TABLE task_completions
+    DROP COLUMN status,
+    DROP COLUMN account,
+    DROP COLUMN detail;
+
+CREATE INDEX idx_task_completions_user_task ON task_completions(user_id, task_id, completed_at DESC);
+
+ALTER TABLE tasks DROP COLUMN verifier;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
@@ -0,0 +1,52 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS secret_codes(
+    id serial PRIMARY KEY,
+    title VARCHAR(255) NOT NULL,
+    code_hash CHAR(64) NOT NULL UNIQUE,
+    reward INT NOT NULL CHECK (reward > 0),
+    max_redemptions INT NOT NULL DEFAULT 0 CHECK (max_redemptions >= 0),
+    per_user_limit INT NOT NULL DEFAULT 1 CHECK (per_user_limit > 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    expires_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
+
+CREATE TABLE IF NOT EXISTS secret_code_redemptions(
+    id bigserial PRIMARY KEY,
+    code_id INT NOT NULL REFERENCES secret_codes(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    redeemed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
+
+    CREATE INDEX idx_secret_code_redemptions_code_user ON secret_code_redemptions(code_id, user_id);
+
+CREATE TABLE IF NOT EXISTS secret_code_attempts(
+    id bigserial PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    attempted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
+
+    CREATE INDEX idx_secret_code_attempts_user ON secret_code_attempts(user_id, attempted_at DESC);
+
+-- The secret of the former /kuarhodron route becomes the first quest, codes are stored as sha256 of their
+-- upper-cased form. Its redemptions are recovered from the ledger. The code is public in the repository,
+-- so the quest is seeded inactive.
+INSERT INTO secret_codes (title, code_hash, reward, per_user_limit, active)
+VALUES ('Kuarhodron', encode(sha256('KUARHODRON'::bytea), 'hex'), 10000, 1, FALSE);
+
+INSERT INTO secret_code_redemptions (code_id, user_id, redeemed_at)
+SELECT sc.id, pt.user_id, pt.created_at
+FROM point_transactions pt
+JOIN secret_codes sc ON sc.title = 'Kuarhodron'
+WHERE pt.reason = 'task_completion' AND pt.reference_id = 'kuarhodron';
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS secret_code_attempts;
+DROP TABLE IF EXISTS secret_code_redemptions;
+DROP TABLE IF EXISTS secret_codes;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
@@ -0,0 +1,41 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS campaigns(
+    id serial PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    kind VARCHAR(20) NOT NULL
+        CONSTRAINT campaigns_kind_check CHECK (kind IN ('multiplier', 'promo')),
+    multiplier_percent INT NOT NULL DEFAULT 100 CHECK (multiplier_percent >= 100),
+    promo_code VARCHAR(32),
+    reward INT NOT NULL DEFAULT 0 CHECK (reward >= 0),
+    max_redemptions INT NOT NULL DEFAULT 0 CHECK (max_redemptions >= 0),
+    new_user_days INT NOT NULL DEFAULT 0 CHECK (new_user_days >= 0),
+    min_score INT NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    starts_at TIMESTAMP,
+    ends_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT campaigns_promo_code_check CHECK ((kind = 'promo') = (promo_code IS NOT NULL)),
+    CONSTRAINT campaigns_window_check CHECK (starts_at IS NULL OR ends_at IS NULL OR starts_at < ends_at)
+    );
+
+    CREATE UNIQUE INDEX campaigns_promo_code_key ON campaigns(promo_code);
+    CREATE INDEX idx_campaigns_kind_active ON campaigns(kind, active);
+
+CREATE TABLE IF NOT EXISTS campaign_redemptions(
+    id bigserial PRIMARY KEY,
+    campaign_id INT NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    redeemed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (campaign_id, user_id)
+    );
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS campaign_redemptions;
+DROP TABLE IF EXISTS campaigns;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS store_items(
+    id serial PRIMARY KEY,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    price INT NOT NULL CHECK (price > 0),
+    stock INT CHECK (stock >= 0),
+    per_user_limit INT NOT NULL DEFAULT 0 CHECK (per_user_limit >= 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
+
+CREATE TABLE IF NOT EXISTS redemptions(
+    id bigserial PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    item_id INT NOT NULL REFERENCES store_items(id),
+    price INT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CONSTRAINT redemptions_status_check CHECK (status IN ('pending', 'fulfilled', 'cancelled')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
+
+    CREATE INDEX idx_redemptions_user ON redemptions(user_id, id DESC);
+    CREATE INDEX idx_redemptions_item_user ON redemptions(item_id, user_id);
+    CREATE INDEX idx_redemptions_status ON redemptions(status, id);
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS redemptions;
+DROP TABLE IF EXISTS store_items;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
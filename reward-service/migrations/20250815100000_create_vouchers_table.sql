@@ -0,0 +1,30 @@
+-- +goose Up
+ALTER TABLE store_items
+    ADD COLUMN digital BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD CONSTRAINT store_items_digital_stock_check CHECK (NOT digital OR stock IS NULL);
+
+CREATE TABLE IF NOT EXISTS vouchers(
+    id bigserial PRIMARY KEY,
+    item_id INT NOT NULL REFERENCES store_items(id) ON DELETE CASCADE,
+    code_encrypted TEXT NOT NULL,
+    fingerprint CHAR(64) NOT NULL,
+    redemption_id BIGINT UNIQUE REFERENCES redemptions(id),
+    claimed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (item_id, fingerprint)
+    );
+
+    CREATE INDEX idx_vouchers_unclaimed ON vouchers(item_id, id) WHERE redemption_id IS NULL;
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS vouchers;
+
+ALTER TABLE store_items
+    DROP CONSTRAINT store_items_digital_stock_check,
+    DROP COLUMN digital;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
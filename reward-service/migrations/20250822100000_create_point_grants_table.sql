@@ -0,0 +1,36 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS point_grants(
+    id bigserial PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    transaction_id BIGINT NOT NULL UNIQUE REFERENCES point_transactions(id) ON DELETE CASCADE,
+    amount INT NOT NULL CHECK (amount > 0),
+    remaining INT NOT NULL CHECK (remaining >= 0 AND remaining <= amount),
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
+
+    CREATE INDEX idx_point_grants_user_open ON point_grants(user_id, expires_at, id) WHERE remaining > 0;
+    CREATE INDEX idx_point_grants_expires_open ON point_grants(expires_at) WHERE remaining > 0;
+
+-- Every credit of the ledger becomes a grant that expires 12 months after it was earned. Debits made so far
+-- consume the grants oldest first, so the remaining amounts add up to the balance.
+INSERT INTO point_grants (user_id, transaction_id, amount, remaining, expires_at, created_at)
+SELECT g.user_id, g.id, g.delta,
+       GREATEST(0, LEAST(g.delta, g.earned - COALESCE(s.spent, 0))),
+       g.created_at + INTERVAL '12 months', g.created_at
+FROM (SELECT id, user_id, delta, created_at, SUM(delta) OVER (PARTITION BY user_id ORDER BY id) AS earned
+      FROM point_transactions
+      WHERE delta > 0) g
+LEFT JOIN (SELECT user_id, -SUM(delta) AS spent
+           FROM point_transactions
+           WHERE delta < 0
+           GROUP BY user_id) s ON s.user_id = g.user_id;
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS point_grants;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
@@ -0,0 +1,51 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS tiers(
+    id serial PRIMARY KEY,
+    name VARCHAR(64) NOT NULL UNIQUE,
+    min_points INT NOT NULL UNIQUE CHECK (min_points >= 0),
+    multiplier_percent INT NOT NULL DEFAULT 100 CHECK (multiplier_percent BETWEEN 100 AND 1000),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
+
+CREATE TABLE IF NOT EXISTS tier_events(
+    id bigserial PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    from_tier_id INT REFERENCES tiers(id) ON DELETE SET NULL,
+    to_tier_id INT REFERENCES tiers(id) ON DELETE SET NULL,
+    lifetime_points INT NOT NULL,
+    transaction_id BIGINT REFERENCES point_transactions(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
+
+    CREATE INDEX idx_tier_events_user ON tier_events(user_id, id DESC);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS lifetime_points INT NOT NULL DEFAULT 0;
+
+INSERT INTO tiers (name, min_points, multiplier_percent)
+VALUES ('Bronze', 0, 100),
+       ('Silver', 1000, 110),
+       ('Gold', 5000, 125),
+       ('Platinum', 20000, 150)
+ON CONFLICT DO NOTHING;
+
+-- Lifetime points are everything the user has earned, refunds of cancelled redemptions return spent points
+-- and are not earnings.
+UPDATE users u
+SET lifetime_points = t.earned
+FROM (SELECT user_id, SUM(delta) AS earned
+      FROM point_transactions
+      WHERE delta > 0 AND reason <> 'redemption_refund'
+      GROUP BY user_id) t
+WHERE t.user_id = u.id;
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN IF EXISTS lifetime_points;
+DROP TABLE IF EXISTS tier_events;
+DROP TABLE IF EXISTS tiers;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
@@ -0,0 +1,55 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS badges(
+    id serial PRIMARY KEY,
+    slug VARCHAR(64) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    metric VARCHAR(32) NOT NULL
+        CONSTRAINT badges_metric_check CHECK (metric IN ('tasks_completed', 'referrals', 'lifetime_points', 'monthly_rank')),
+    threshold INT NOT NULL CHECK (threshold > 0),
+    reward INT NOT NULL DEFAULT 0 CHECK (reward >= 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
+
+CREATE TABLE IF NOT EXISTS user_badges(
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    badge_id INT NOT NULL REFERENCES badges(id) ON DELETE CASCADE,
+    awarded_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, badge_id)
+    );
+
+    CREATE INDEX idx_user_badges_user ON user_badges(user_id, awarded_at DESC);
+
+INSERT INTO badges (slug, name, description, metric, threshold)
+VALUES ('first-task', 'First task', 'Completed the first task', 'tasks_completed', 1),
+       ('ten-friends', 'Invited 10 friends', 'Ten friends redeemed the referral code', 'referrals', 10),
+       ('monthly-top-100', 'Top 100 this month', 'Among the 100 users who earned the most points this month',
+        'monthly_rank', 100)
+ON CONFLICT DO NOTHING;
+
+-- Milestones reached before badges existed are awarded at once, without rewards.
+INSERT INTO user_badges (user_id, badge_id, awarded_at)
+SELECT c.user_id, b.id, MIN(c.completed_at)
+FROM task_completions c
+JOIN badges b ON b.slug = 'first-task'
+WHERE c.status = 'verified'
+GROUP BY c.user_id, b.id;
+
+INSERT INTO user_badges (user_id, badge_id, awarded_at)
+SELECT r.referrer_id, b.id, MAX(r.created_at)
+FROM referrals r
+JOIN badges b ON b.slug = 'ten-friends'
+GROUP BY r.referrer_id, b.id
+HAVING COUNT(*) >= 10;
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS user_badges;
+DROP TABLE IF EXISTS badges;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
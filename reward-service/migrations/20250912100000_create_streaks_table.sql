@@ -0,0 +1,32 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+CREATE TABLE IF NOT EXISTS streaks(
+    user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    current INT NOT NULL DEFAULT 0 CHECK (current >= 0),
+    longest INT NOT NULL DEFAULT 0 CHECK (longest >= current),
+    last_checkin DATE,
+    freezes INT NOT NULL DEFAULT 0 CHECK (freezes >= 0),
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );
+
+CREATE TABLE IF NOT EXISTS checkins(
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    checkin_date DATE NOT NULL,
+    streak INT NOT NULL,
+    reward INT NOT NULL,
+    freezes_used INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, checkin_date)
+    );
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS checkins;
+DROP TABLE IF EXISTS streaks;
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS seasons(
+    id serial PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    closed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT seasons_period_check CHECK (ends_at > starts_at)
+    );
+
+    CREATE INDEX idx_seasons_open ON seasons(ends_at) WHERE closed_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS season_prizes(
+    season_id INT NOT NULL REFERENCES seasons(id) ON DELETE CASCADE,
+    rank INT NOT NULL CHECK (rank > 0),
+    points INT NOT NULL CHECK (points > 0),
+    PRIMARY KEY (season_id, rank)
+    );
+
+CREATE TABLE IF NOT EXISTS season_standings(
+    season_id INT NOT NULL REFERENCES seasons(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    rank INT NOT NULL,
+    score INT NOT NULL,
+    prize INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (season_id, user_id)
+    );
+
+    CREATE INDEX idx_season_standings_order ON season_standings(season_id, score DESC, user_id);
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS season_standings;
+DROP TABLE IF EXISTS season_prizes;
+DROP TABLE IF EXISTS seasons;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
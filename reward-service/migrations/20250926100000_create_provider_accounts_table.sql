@@ -0,0 +1,28 @@
+-- +goose Up
+-- An account with a task provider earns verified completions for one user only.
+CREATE TABLE IF NOT EXISTS provider_accounts(
+    provider VARCHAR(32) NOT NULL,
+    account VARCHAR(255) NOT NULL,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    bound_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (provider, account)
+    );
+
+    CREATE INDEX idx_provider_accounts_user ON provider_accounts(user_id);
+
+-- Accounts already used are bound to the user who completed a task with them first.
+INSERT INTO provider_accounts (provider, account, user_id, bound_at)
+SELECT DISTINCT ON (t.verifier, c.account) t.verifier, c.account, c.user_id, c.completed_at
+FROM task_completions c
+JOIN tasks t ON t.id = c.task_id
+WHERE c.status = 'verified' AND c.account <> '' AND t.verifier <> ''
+ORDER BY t.verifier, c.account, c.completed_at, c.id;
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS provider_accounts;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
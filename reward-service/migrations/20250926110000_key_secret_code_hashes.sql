@@ -0,0 +1,20 @@
+-- +goose Up
+-- Codes are hashed with HMAC-SHA256 under SECRET_CODE_KEY now. Existing quests keep their plain sha256
+-- hashes until they are redeemed for the first time and are rehashed then.
+ALTER TABLE secret_codes ADD COLUMN legacy_hash BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE secret_codes SET legacy_hash = TRUE;
+
+-- Wrong attempts of all users are counted to warn about code guessing.
+CREATE INDEX idx_secret_code_attempts_time ON secret_code_attempts(attempted_at);
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_secret_code_attempts_time;
+
+ALTER TABLE secret_codes DROP COLUMN legacy_hash;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
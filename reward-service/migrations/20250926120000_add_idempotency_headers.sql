@@ -0,0 +1,21 @@
+-- +goose Up
+-- Replays restore every response header, cookies included, not only the content type.
+ALTER TABLE idempotency_keys ADD COLUMN headers JSONB NOT NULL DEFAULT '{}';
+
+UPDATE idempotency_keys SET headers = jsonb_build_object('Content-Type', jsonb_build_array(content_type))
+WHERE content_type <> '';
+
+ALTER TABLE idempotency_keys DROP COLUMN content_type;
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+ALTER TABLE idempotency_keys ADD COLUMN content_type VARCHAR(255) NOT NULL DEFAULT '';
+
+UPDATE idempotency_keys SET content_type = coalesce(headers -> 'Content-Type' ->> 0, '');
+
+ALTER TABLE idempotency_keys DROP COLUMN headers;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Vouchers remember the fingerprint key version, the ones made with an older key are backfilled in the background.
+ALTER TABLE vouchers ADD COLUMN fingerprint_version SMALLINT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_vouchers_fingerprint_version ON vouchers(fingerprint_version, id);
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_vouchers_fingerprint_version;
+
+ALTER TABLE vouchers DROP COLUMN fingerprint_version;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
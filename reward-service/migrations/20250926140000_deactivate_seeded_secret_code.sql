@@ -0,0 +1,11 @@
+-- +goose Up
+-- The code of the quest seeded from the former /kuarhodron route is public in the repository.
+UPDATE secret_codes SET active = FALSE WHERE title = 'Kuarhodron';
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
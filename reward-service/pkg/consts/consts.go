@@ -7,13 +7,11 @@ const (
 	PassMinLength              = 8
 	BcryptCost                 = 12
 	RefreshTokenExpireTime     = 30 * 24 * time.Hour
-	FixedRewardForTelegramSign = 50
-	FixedRewardForXSign        = 75
-	FixedRewardForSomeTask     = 100
 	AtLeastPassLength          = 8
-	FixedReardForSecretTask    = 10000
 	AccessTokenExpireTime      = 15 * time.Minute
 	RefreshTokenLength         = 32
+	RefreshTokenSelectorLength = 12
+	AccessTokenIDLength        = 16
 	ConnectAttempts            = 10
 	WaitBeforeAttempts         = 2
 	MaxAge                     = 300
@@ -21,4 +19,145 @@ const (
 	IdleTimeout                = 30
 	WriteTimeout               = 10
 	ReadTimeout                = 5
+	RoleUser                   = "user"
+	RoleModerator              = "moderator"
+	RoleAdmin                  = "admin"
+	FixedRewardForReferrer     = 100
+	FixedRewardForReferee      = 25
+	SecondTierReferrerReward   = 20
+	MaxReferralTiers           = 5
+	TaskSlugMaxLength          = 64
+	IdempotencyKeyHeader       = "Idempotency-Key"
+	IdempotencyReplayedHeader  = "Idempotent-Replayed"
+	IdempotencyKeyMaxLength    = 255
+	IdempotencyKeyTTL          = 24 * time.Hour
+	TelegramAPIURL             = "https://api.telegram.org"
+	XAPIURL                    = "https://api.x.com"
+	XFollowingPageSize         = 1000
+	XFollowingMaxPages         = 5
+	VerifierRequestTimeout     = 5 * time.Second
+	VerificationTimeout        = 8 * time.Second
+	DefaultPageLimit           = 20
+	MaxPageLimit               = 100
+	SerializableTxAttempts     = 3
+	ReferralCodeLength         = 8
+	ReferralCodeAttempts       = 5
+	VanityCodeMinLength        = 4
+	VanityCodeMaxLength        = 20
+	ReferralLinkTemplate       = "/registrate?referrer={code}"
+	SecretCodeMinLength        = 8
+	SecretCodeMaxLength        = 64
+	SecretCodeMaxAttempts      = 5
+	SecretCodeAttemptWindow    = 15 * time.Minute
+	SecretCodeAlertAttempts    = 200
+	PromoCodeMinLength         = 4
+	PromoCodeMaxLength         = 32
+	MaxMultiplierPercent       = 1000
+	VoucherLowStockThreshold   = 10
+	VoucherUploadMaxBytes      = 10 * Megabyte
+	VoucherFingerprintBatch    = 500
+	VoucherInsertBatch         = 1000
+	VoucherBatchTimeout        = time.Second
+	PointsLifetimeMonths       = 12
+	PointsExpiryNoticePeriod   = 30 * 24 * time.Hour
+	PointsExpiryInterval       = time.Hour
+	CheckinBaseReward          = 10
+	CheckinRewardStep          = 5
+	CheckinMaxRewardDay        = 7
+	StreakFreezePrice          = 100
+	MaxStreakFreezes           = 2
+	DefaultTimezone            = "UTC"
+	DefaultRankNeighbours      = 5
+	MaxRankNeighbours          = 25
+	MaxSeasonPrizes            = 100
+	SeasonClosingInterval      = time.Hour
+	RankBadgesInterval         = 15 * time.Minute
+)
+
+// Reasons of points ledger entries.
+const (
+	ReasonOpeningBalance = "opening_balance"
+	ReasonAdjustment     = "adjustment"
+	ReasonTaskCompletion = "task_completion"
+	ReasonReferrerBonus  = "referrer_bonus"
+	ReasonRefereeBonus   = "referee_bonus"
+	ReasonSecretCode     = "secret_code"
+	ReasonCampaignBonus  = "campaign_bonus"
+	ReasonPromoCode      = "promo_code"
+	ReasonRedemption     = "redemption"
+	ReasonRefund         = "redemption_refund"
+	ReasonPointsExpired  = "points_expired"
+	ReasonTierBonus      = "tier_bonus"
+	ReasonBadgeReward    = "badge_reward"
+	ReasonDailyCheckin   = "daily_checkin"
+	ReasonStreakFreeze   = "streak_freeze"
+	ReasonSeasonPrize    = "season_prize"
+)
+
+// Metrics of badge rules.
+const (
+	BadgeMetricTasksCompleted = "tasks_completed"
+	BadgeMetricReferrals      = "referrals"
+	BadgeMetricLifetimePoints = "lifetime_points"
+	BadgeMetricMonthlyRank    = "monthly_rank"
+)
+
+// Slugs of the catalog tasks that have their own legacy routes.
+const (
+	TaskSlugTelegramSign = "telegram-sign"
+	TaskSlugXSign        = "x-sign"
+	TaskSlugSomeTask     = "some-task"
+)
+
+// Completion policies of catalog tasks.
+const (
+	TaskPolicyOnce      = "once"
+	TaskPolicyDaily     = "daily"
+	TaskPolicyLimited   = "limited"
+	TaskPolicyUnlimited = "unlimited"
+)
+
+// Providers that check task completions and the statuses of the checks.
+const (
+	VerifierTelegram     = "telegram"
+	VerifierX            = "x"
+	VerificationVerified = "verified"
+	VerificationPending  = "pending"
+	VerificationFailed   = "failed"
+)
+
+// Kinds of marketing campaigns.
+const (
+	CampaignKindMultiplier = "multiplier"
+	CampaignKindPromo      = "promo"
+)
+
+// Statuses of store redemptions.
+const (
+	RedemptionPending   = "pending"
+	RedemptionFulfilled = "fulfilled"
+	RedemptionCancelled = "cancelled"
+)
+
+// Periods of the leaderboard, the ones other than all rank the points earned since the period began in UTC.
+const (
+	LeaderboardPeriodAll   = "all"
+	LeaderboardPeriodMonth = "month"
+	LeaderboardPeriodWeek  = "week"
+	LeaderboardPeriodDay   = "day"
+)
+
+// Ways to rank users with equal scores: competition ranking skips the ranks after the ties (1, 2, 2, 4),
+// dense ranking doesn't (1, 2, 2, 3).
+const (
+	RankingCompetition = "competition"
+	RankingDense       = "dense"
+)
+
+// Statuses of leaderboard seasons. An ended season waits for the job that closes it and archives the standings.
+const (
+	SeasonUpcoming = "upcoming"
+	SeasonActive   = "active"
+	SeasonEnded    = "ended"
+	SeasonClosed   = "closed"
 )
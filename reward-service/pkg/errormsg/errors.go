@@ -34,6 +34,128 @@ var (
 	ErrDSNRequired                   = errors.New("DSN is required")
 	ErrServerPortRequired            = errors.New("server port is required")
 	ErrPostgresConnectAttemptsFailed = errors.New("failed connect to Postgres after 10 attempts")
+	ErrMissingRefreshToken           = errors.New("missing refresh token")
+	ErrInvalidRefreshToken           = errors.New("refresh token is invalid or expired")
+	ErrRefreshTokenReused            = errors.New("refresh token has already been used, all sessions were revoked")
+	ErrCreateSession                 = errors.New("couldn't create session")
+	ErrSessionNotFound               = errors.New("session does not exist")
+	ErrFetchSessions                 = errors.New("couldn't fetch sessions")
+	ErrForbidden                     = errors.New("access to another user's resources is forbidden")
+	ErrInsufficientRole              = errors.New("insufficient role for this operation")
+	ErrInvalidRole                   = errors.New("role must be one of: user, moderator, admin")
+	ErrChangeOwnRole                 = errors.New("admins cannot change their own role")
+	ErrSetRole                       = errors.New("couldn't change user's role")
+	ErrBootstrapAdmin                = errors.New("couldn't bootstrap admin account")
+	ErrReferrerNotFound              = errors.New("provided referrer does not exist")
+	ErrOwnReferrer                   = errors.New("user cannot redeem their own referrer")
+	ErrReferrerAlreadyRedeemed       = errors.New("user has already redeemed a referrer")
+	ErrInvalidReferralCode           = errors.New("referral code must be 4-20 latin letters, digits or single inner hyphens")
+	ErrReferralCodeTaken             = errors.New("referral code is already taken")
+	ErrGenerateReferralCode          = errors.New("couldn't generate unique referral code")
+	ErrFetchReferral                 = errors.New("couldn't fetch referral code")
+	ErrFetchReferralStats            = errors.New("couldn't fetch referral statistics")
+	ErrInvalidReferralRewards        = errors.New("referral rewards must be comma separated non-negative numbers, 5 levels at most")
+	ErrFetchTransactions             = errors.New("couldn't fetch transactions")
+	ErrTaskNotFound                  = errors.New("task not found")
+	ErrTaskNotAvailable              = errors.New("task is not available at the moment")
+	ErrTaskSlugTaken                 = errors.New("task with this slug already exists")
+	ErrTaskAlreadyCompleted          = errors.New("task can be completed only once and is already completed")
+	ErrTaskLimitReached              = errors.New("task completion limit for the period is reached, try again later")
+	ErrTaskOnCooldown                = errors.New("task was completed recently, try again after the cooldown")
+	ErrInvalidTaskVerifier           = errors.New("task verifier must be telegram, x or empty")
+	ErrMissingTaskAccount            = errors.New("account with the task provider is required")
+	ErrTaskAccountTaken              = errors.New("account with the task provider is already used by another user")
+	ErrTaskVerifierUnavailable       = errors.New("task can't be verified at the moment")
+	ErrTaskVerificationFailed        = errors.New("task provider couldn't confirm the completion")
+	ErrTaskVerificationPending       = errors.New("task completion is waiting for verification, try again later")
+	ErrVerifierResponse              = errors.New("unexpected response from task provider")
+	ErrInvalidTaskPolicy             = errors.New("task policy must be once, daily, limited with maxCompletions and periodSeconds or unlimited with optional cooldownSeconds")
+	ErrInvalidTaskSlug               = errors.New("task slug must be 1-64 lowercase latin letters, digits or hyphens")
+	ErrInvalidTask                   = errors.New("task must have a title, a positive reward and start before it ends")
+	ErrFetchTasks                    = errors.New("couldn't fetch tasks")
+	ErrSaveTask                      = errors.New("couldn't save task")
+	ErrInvalidSecretCode             = errors.New("secret code must be 8-64 characters long")
+	ErrInvalidSecretCodeQuest        = errors.New("secret code quest must have a title, a positive reward and non-negative limits")
+	ErrSecretCodeTaken               = errors.New("secret code is already used by another quest")
+	ErrSecretCodeNotFound            = errors.New("secret code is wrong")
+	ErrSecretCodeExpired             = errors.New("secret code is no longer valid")
+	ErrSecretCodeExhausted           = errors.New("secret code has been redeemed the maximum number of times")
+	ErrSecretCodeAlreadyRedeemed     = errors.New("user has already redeemed this secret code")
+	ErrTooManyCodeAttempts           = errors.New("too many wrong secret codes, try again later")
+	ErrInvalidSecretCodeKey          = errors.New("secret code key must be at least 32 bytes encoded in base64")
+	ErrSecretCodesDisabled           = errors.New("secret codes are not configured")
+	ErrRedeemSecretCode              = errors.New("couldn't redeem secret code")
+	ErrFetchSecretCodes              = errors.New("couldn't fetch secret code quests")
+	ErrSaveSecretCode                = errors.New("couldn't save secret code quest")
+	ErrCampaignNotFound              = errors.New("campaign not found")
+	ErrInvalidCampaign               = errors.New("campaign must have a name, non-negative audience filters and start before it ends")
+	ErrInvalidCampaignKind           = errors.New("campaign must be a multiplier with multiplierPercent of 101-1000 or a promo with a positive reward")
+	ErrInvalidPromoCode              = errors.New("promo code must be 4-32 latin letters, digits or hyphens")
+	ErrPromoCodeTaken                = errors.New("promo code is already used by another campaign")
+	ErrPromoCodeNotFound             = errors.New("promo code is wrong")
+	ErrPromoCodeNotAvailable         = errors.New("promo code is not available to the user at the moment")
+	ErrPromoCodeExhausted            = errors.New("promo code has been redeemed the maximum number of times")
+	ErrPromoCodeAlreadyRedeemed      = errors.New("user has already redeemed this promo code")
+	ErrRedeemPromoCode               = errors.New("couldn't redeem promo code")
+	ErrFetchCampaigns                = errors.New("couldn't fetch campaigns")
+	ErrSaveCampaign                  = errors.New("couldn't save campaign")
+	ErrStoreItemNotFound             = errors.New("store item not found")
+	ErrStoreItemNotAvailable         = errors.New("store item is not available")
+	ErrInvalidStoreItem              = errors.New("store item must have a title, a positive price, non-negative limit and stock, digital items have no stock")
+	ErrOutOfStock                    = errors.New("store item is out of stock")
+	ErrRedemptionLimitReached        = errors.New("user has already redeemed this item the maximum number of times")
+	ErrInsufficientPoints            = errors.New("user doesn't have enough points")
+	ErrRedemptionNotFound            = errors.New("redemption not found")
+	ErrInvalidRedemptionStatus       = errors.New("redemption status must be fulfilled or cancelled")
+	ErrRedemptionNotPending          = errors.New("only pending redemptions can be fulfilled or cancelled")
+	ErrFetchStoreItems               = errors.New("couldn't fetch store items")
+	ErrSaveStoreItem                 = errors.New("couldn't save store item")
+	ErrRedeemItem                    = errors.New("couldn't redeem store item")
+	ErrFetchRedemptions              = errors.New("couldn't fetch redemptions")
+	ErrUpdateRedemption              = errors.New("couldn't update redemption")
+	ErrStoreItemNotDigital           = errors.New("store item isn't fulfilled with vouchers")
+	ErrInvalidVoucherKey             = errors.New("voucher encryption key must be 32 bytes encoded in base64")
+	ErrInvalidVoucherCSV             = errors.New("voucher upload must be a CSV with codes in the first column")
+	ErrDecryptVoucher                = errors.New("couldn't decrypt voucher code")
+	ErrVouchersDisabled              = errors.New("vouchers are not configured")
+	ErrSaveVouchers                  = errors.New("couldn't save vouchers")
+	ErrFetchVouchers                 = errors.New("couldn't fetch vouchers")
+	ErrTierNotFound                  = errors.New("tier not found")
+	ErrInvalidTier                   = errors.New("tier must have a name, non-negative minPoints and multiplierPercent of 100-1000")
+	ErrTierExists                    = errors.New("tier with this name or minPoints already exists")
+	ErrFetchTiers                    = errors.New("couldn't fetch tiers")
+	ErrSaveTier                      = errors.New("couldn't save tier")
+	ErrBadgeNotFound                 = errors.New("badge not found")
+	ErrInvalidBadge                  = errors.New("badge must have a slug, a name and a non-negative reward")
+	ErrInvalidBadgeRule              = errors.New("badge rule must use tasks_completed, referrals, lifetime_points or monthly_rank with a positive threshold")
+	ErrBadgeExists                   = errors.New("badge with this slug already exists")
+	ErrFetchBadges                   = errors.New("couldn't fetch badges")
+	ErrSaveBadge                     = errors.New("couldn't save badge")
+	ErrAlreadyCheckedIn              = errors.New("user has already checked in today")
+	ErrStreakFreezeLimit             = errors.New("user already holds the maximum number of streak freezes")
+	ErrInvalidTimezone               = errors.New("timezone must be an IANA time zone name, e.g. Europe/Moscow")
+	ErrCheckIn                       = errors.New("couldn't check in")
+	ErrBuyStreakFreeze               = errors.New("couldn't buy streak freeze")
+	ErrSetTimezone                   = errors.New("couldn't set timezone")
+	ErrInvalidPeriod                 = errors.New("period must be one of all, month, week, day")
+	ErrFetchLeaderboard              = errors.New("couldn't fetch leaderboard")
+	ErrUserNotRanked                 = errors.New("user has no place on the leaderboard for the period")
+	ErrInvalidNeighbours             = errors.New("neighbours must be a number from 0 to 25")
+	ErrFetchRank                     = errors.New("couldn't fetch user's rank")
+	ErrInvalidRanking                = errors.New("LEADERBOARD_RANKING must be competition or dense")
+	ErrSeasonNotFound                = errors.New("season not found")
+	ErrInvalidSeason                 = errors.New("season needs a name, endsAt after startsAt and up to 100 non-negative prizes")
+	ErrFetchSeasons                  = errors.New("couldn't fetch seasons")
+	ErrSaveSeason                    = errors.New("couldn't save season")
+	ErrInvalidCursor                 = errors.New("provided cursor is invalid")
+	ErrInvalidLimit                  = errors.New("limit must be a positive number")
+	ErrInvalidIdempotencyKey         = errors.New("idempotency key must be 1-255 characters long")
+	ErrIdempotencyKeyReused          = errors.New("idempotency key was already used with a different request")
+	ErrIdempotencyKeyInProgress      = errors.New("request with this idempotency key is still being processed")
+	ErrIdempotency                   = errors.New("couldn't process idempotency key")
+	ErrTokenRevoked                  = errors.New("access token has been revoked")
+	ErrInvalidTokenClaims            = errors.New("access token has invalid claims")
+	ErrRevokeSessions                = errors.New("couldn't revoke sessions")
 )
 
 // NewErrorResponse creates new ErrorResponse from error.